@@ -0,0 +1,8 @@
+USE `stdnt_reg`;
+
+--
+-- Indexes of the created_on keyset used by the student and teacher listings
+--
+
+ALTER TABLE `student` ADD KEY `created_on_email_idx` (`created_on`, `email`);
+ALTER TABLE `teacher` ADD KEY `created_on_email_idx` (`created_on`, `email`);
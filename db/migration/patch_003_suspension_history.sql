@@ -0,0 +1,33 @@
+USE `stdnt_reg`;
+
+--
+-- Table structure for table `suspension_history`
+--
+-- A student is suspended while one of their suspensions is neither lifted nor past `ends_on`.
+-- `register`.`suspended_on` is no longer read or written.
+--
+
+CREATE TABLE IF NOT EXISTS `suspension_history` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `student_id` varchar(45) NOT NULL,
+  `reason` varchar(255) NOT NULL,
+  `suspended_by` varchar(45) DEFAULT NULL,
+  `suspended_on` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `ends_on` timestamp NULL DEFAULT NULL,
+  `lifted_on` timestamp NULL DEFAULT NULL,
+  `lifted_by` varchar(45) DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `suspension_student_id_idx` (`student_id`, `lifted_on`),
+  CONSTRAINT `suspension_student_id` FOREIGN KEY (`student_id`) REFERENCES `student` (`email`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+--
+-- Carry over the suspensions recorded on the registrations
+--
+
+INSERT INTO `suspension_history` (`student_id`, `reason`, `suspended_on`)
+SELECT `student_id`, 'suspended before the suspension history was kept', MIN(`suspended_on`)
+FROM `register`
+WHERE `suspended_on` IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM `suspension_history`)
+GROUP BY `student_id`;
@@ -0,0 +1,13 @@
+USE `stdnt_reg`;
+
+--
+-- Scope suspensions to a teacher
+--
+-- A suspension with a `teacher_id` only keeps the student out of that teacher's class.
+-- Suspensions without one, including every suspension recorded before this patch, are global.
+--
+
+ALTER TABLE `suspension_history`
+  ADD COLUMN `teacher_id` varchar(45) DEFAULT NULL AFTER `student_id`,
+  ADD KEY `suspension_teacher_id_idx` (`teacher_id`),
+  ADD CONSTRAINT `suspension_teacher_id` FOREIGN KEY (`teacher_id`) REFERENCES `teacher` (`email`) ON DELETE CASCADE ON UPDATE CASCADE;
@@ -0,0 +1,28 @@
+USE `stdnt_reg`;
+
+--
+-- Table structure for table `notification`
+--
+
+CREATE TABLE IF NOT EXISTS `notification` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `teacher_id` varchar(45) NOT NULL,
+  `message` text NOT NULL,
+  `created_on` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `notification_teacher_id_idx` (`teacher_id`, `id`),
+  CONSTRAINT `notification_teacher_id` FOREIGN KEY (`teacher_id`) REFERENCES `teacher` (`email`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+--
+-- Table structure for table `notification_recipient`
+--
+
+CREATE TABLE IF NOT EXISTS `notification_recipient` (
+  `notification_id` int NOT NULL,
+  `student_id` varchar(45) NOT NULL,
+  PRIMARY KEY (`notification_id`, `student_id`),
+  KEY `notification_recipient_student_id_idx` (`student_id`, `notification_id`),
+  CONSTRAINT `notification_recipient_notification_id` FOREIGN KEY (`notification_id`) REFERENCES `notification` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `notification_recipient_student_id` FOREIGN KEY (`student_id`) REFERENCES `student` (`email`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
@@ -0,0 +1,24 @@
+USE `stdnt_reg`;
+
+--
+-- Table structure for table `notification_outbox`
+--
+-- One row per recipient is written in the transaction that stores the notification. The
+-- dispatcher sends the pending rows whose `next_attempt_on` is due and records the outcome.
+--
+
+CREATE TABLE IF NOT EXISTS `notification_outbox` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `notification_id` int NOT NULL,
+  `student_id` varchar(45) NOT NULL,
+  `status` enum('pending','sent','failed') NOT NULL DEFAULT 'pending',
+  `attempts` int NOT NULL DEFAULT 0,
+  `next_attempt_on` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `last_error` varchar(255) DEFAULT NULL,
+  `sent_on` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `notification_outbox_recipient_uk` (`notification_id`, `student_id`),
+  KEY `notification_outbox_due_idx` (`status`, `next_attempt_on`),
+  CONSTRAINT `notification_outbox_notification_id` FOREIGN KEY (`notification_id`) REFERENCES `notification` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `notification_outbox_student_id` FOREIGN KEY (`student_id`) REFERENCES `student` (`email`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
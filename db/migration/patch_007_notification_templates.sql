@@ -0,0 +1,25 @@
+USE `stdnt_reg`;
+
+--
+-- Table structure for table `notification_template`
+--
+
+CREATE TABLE IF NOT EXISTS `notification_template` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `teacher_id` varchar(45) NOT NULL,
+  `name` varchar(100) NOT NULL,
+  `body` text NOT NULL,
+  `created_on` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_on` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `notification_template_name_uk` (`teacher_id`, `name`),
+  CONSTRAINT `notification_template_teacher_id` FOREIGN KEY (`teacher_id`) REFERENCES `teacher` (`email`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+--
+-- A notification sent from a template is rendered for every recipient. The personalized
+-- message is kept with the recipient, NULL when the recipient received the notification message.
+--
+
+ALTER TABLE `notification_recipient`
+  ADD COLUMN `message` text NULL DEFAULT NULL;
@@ -0,0 +1,28 @@
+USE `stdnt_reg`;
+
+--
+-- Table structure for table `scheduled_notification`
+--
+-- A scheduled notification holds either a message or a template with its variables. It is
+-- resolved and sent like any other notification once `send_at` passes. `template_id` has no
+-- foreign key: a scheduled notification whose template was deleted fails when it is due.
+--
+
+CREATE TABLE IF NOT EXISTS `scheduled_notification` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `teacher_id` varchar(45) NOT NULL,
+  `message` text NOT NULL,
+  `template_id` int DEFAULT NULL,
+  `variables` json DEFAULT NULL,
+  `send_at` timestamp NOT NULL,
+  `status` enum('pending','sent','cancelled','failed') NOT NULL DEFAULT 'pending',
+  `notification_id` int DEFAULT NULL,
+  `last_error` varchar(255) DEFAULT NULL,
+  `created_on` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_on` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `scheduled_notification_due_idx` (`status`, `send_at`),
+  KEY `scheduled_notification_teacher_id_idx` (`teacher_id`, `status`, `send_at`),
+  CONSTRAINT `scheduled_notification_teacher_id` FOREIGN KEY (`teacher_id`) REFERENCES `teacher` (`email`) ON DELETE CASCADE ON UPDATE CASCADE,
+  CONSTRAINT `scheduled_notification_notification_id` FOREIGN KEY (`notification_id`) REFERENCES `notification` (`id`) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
@@ -0,0 +1,21 @@
+USE `stdnt_reg`;
+
+--
+-- Table structure for table `student_preference`
+--
+-- A student without a row receives every notification immediately. The quiet hours are
+-- "HH:MM" times in the student's time zone.
+--
+
+CREATE TABLE IF NOT EXISTS `student_preference` (
+  `student_id` varchar(45) NOT NULL,
+  `unsubscribed` tinyint(1) NOT NULL DEFAULT 0,
+  `broadcast_opt_out` tinyint(1) NOT NULL DEFAULT 0,
+  `digest` enum('immediate','daily','weekly') NOT NULL DEFAULT 'immediate',
+  `quiet_start` char(5) DEFAULT NULL,
+  `quiet_end` char(5) DEFAULT NULL,
+  `timezone` varchar(64) NOT NULL DEFAULT 'UTC',
+  `updated_on` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`student_id`),
+  CONSTRAINT `student_preference_student_id` FOREIGN KEY (`student_id`) REFERENCES `student` (`email`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
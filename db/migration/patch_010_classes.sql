@@ -0,0 +1,54 @@
+USE `stdnt_reg`;
+
+--
+-- Table structure for table `class`
+--
+-- A teacher runs classes, each identified by its code in a term. Every teacher with students
+-- registered to them directly has a `default` class without a term holding those students.
+--
+
+CREATE TABLE IF NOT EXISTS `class` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `teacher_id` varchar(45) NOT NULL,
+  `code` varchar(20) NOT NULL,
+  `title` varchar(100) NOT NULL DEFAULT '',
+  `term` varchar(20) NOT NULL DEFAULT '',
+  `created_on` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_on` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `class_code_uk` (`teacher_id`, `term`, `code`),
+  CONSTRAINT `class_teacher_id` FOREIGN KEY (`teacher_id`) REFERENCES `teacher` (`email`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+--
+-- Registrations move from teachers to classes. The existing registrations go to the default
+-- class of their teacher; `register.teacher_id` is kept as the teacher of the class. Rows
+-- without a teacher never registered the student to anyone and are dropped.
+--
+
+DELETE FROM `register` WHERE `teacher_id` IS NULL;
+
+INSERT INTO `class` (`teacher_id`, `code`)
+  SELECT DISTINCT `teacher_id`, 'default' FROM `register`
+  ON DUPLICATE KEY UPDATE `id` = `id`;
+
+ALTER TABLE `register`
+  ADD COLUMN `class_id` int NULL DEFAULT NULL AFTER `teacher_id`;
+
+UPDATE `register`
+  JOIN `class` ON `class`.`teacher_id` = `register`.`teacher_id` AND `class`.`code` = 'default' AND `class`.`term` = ''
+  SET `register`.`class_id` = `class`.`id`;
+
+ALTER TABLE `register`
+  MODIFY COLUMN `class_id` int NOT NULL,
+  DROP INDEX `teacher_student_uk`,
+  ADD UNIQUE KEY `register_class_student_uk` (`student_id`, `class_id`),
+  ADD CONSTRAINT `register_class_id` FOREIGN KEY (`class_id`) REFERENCES `class` (`id`) ON DELETE CASCADE;
+
+--
+-- A scheduled notification may target one class of the teacher. Like the template, the class
+-- is checked when the notification is sent, so it has no foreign key.
+--
+
+ALTER TABLE `scheduled_notification`
+  ADD COLUMN `class_id` int NULL DEFAULT NULL AFTER `variables`;
@@ -0,0 +1,27 @@
+USE `stdnt_reg`;
+
+--
+-- Table structure for table `term`
+--
+-- An academic term runs from `starts_on` to the end of `ends_on`. Classes name their term by
+-- its code; classes without a term, such as the default classes created before terms existed,
+-- have an empty `term`. The term of a class is checked when the class is created, so
+-- `class.term` has no foreign key.
+--
+
+CREATE TABLE IF NOT EXISTS `term` (
+  `code` varchar(20) NOT NULL,
+  `name` varchar(100) NOT NULL DEFAULT '',
+  `starts_on` date NOT NULL,
+  `ends_on` date NOT NULL,
+  `created_on` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`code`),
+  KEY `term_dates_idx` (`starts_on`, `ends_on`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+--
+-- Registrations are looked up by the term of their class
+--
+
+ALTER TABLE `class`
+  ADD KEY `class_term_idx` (`term`);
@@ -2,17 +2,24 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	"os"
+	"strings"
 )
 
 type MainConfig struct {
-	Database DatabaseConfig
-	Server   ServerConfig
-	Redis    CacheConfig
-	JWT      JWTConfig
-	Log      log.FieldLogger
+	Database   DatabaseConfig
+	Server     ServerConfig
+	Redis      CacheConfig
+	JWT        JWTConfig
+	Admin      AdminConfig
+	SMTP       SMTPConfig
+	Dispatcher DispatcherConfig
+	Scheduler  SchedulerConfig
+	Term       TermConfig
+	Log        log.FieldLogger
 }
 
 type ServerConfig struct {
@@ -20,14 +27,98 @@ type ServerConfig struct {
 	RunMode string
 }
 
+// JWTConfig holds the token signing secrets. The expire durations are in minutes. RefreshSecret
+// is not kept in the config files; set it through JWT_REFRESHSECRET.
 type JWTConfig struct {
 	Secret                     string
 	RefreshSecret              string
 	AccessTokenExpireDuration  int
-	refreshTokenExpireDuration int
+	RefreshTokenExpireDuration int
+	// Issuer and Audience are set on issued tokens and, when not empty, required on verified tokens
+	Issuer   string
+	Audience string
+	// KeyID names the HS256 key of Keys that signs new access tokens. Secret is used when it is empty.
+	KeyID string
+	// Keys are the additional access token keys, selected by the kid header of a token
+	Keys []JWTKeyConfig
+}
+
+// Validate returns an error when RefreshSecret is empty or is also an access token secret, which
+// would let a refresh token pass for an access token
+func (c JWTConfig) Validate() error {
+	if c.RefreshSecret == "" {
+		return errors.New("jwt.refreshSecret is not set")
+	}
+	if c.RefreshSecret == c.Secret {
+		return errors.New("jwt.refreshSecret must differ from jwt.secret")
+	}
+	for _, k := range c.Keys {
+		if k.Secret == c.RefreshSecret {
+			return fmt.Errorf("jwt.refreshSecret must differ from the secret of jwt key %q", k.ID)
+		}
+	}
+	return nil
+}
+
+// JWTKeyConfig is an access token verification key. HS256 keys hold a Secret and can also sign
+// tokens, RS256 and ES256 keys hold the PEM encoded PublicKey of an external token issuer.
+type JWTKeyConfig struct {
+	ID        string
+	Algorithm string
+	Secret    string
+	PublicKey string
+}
+
+// AdminConfig holds the credentials of the administrator account created on startup. The
+// account is not created while Password is empty; set it through ADMIN_PASSWORD.
+type AdminConfig struct {
+	Email    string
+	Password string
+}
+
+// SMTPConfig holds the mail server notifications are delivered through. Notifications are
+// only kept in the outbox when Host is empty.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// DispatcherConfig tunes the delivery of the notification outbox. Zero values use the defaults
+// of notifier.NewDispatcher.
+type DispatcherConfig struct {
+	// Interval is the number of seconds between two polls of the outbox
+	Interval int
+	// BatchSize is the number of deliveries fetched per poll
+	BatchSize int
+	// MaxAttempts is the number of attempts after which a delivery is given up
+	MaxAttempts int
+	// Backoff is the number of seconds before the first retry, doubling on every further attempt
+	Backoff int
+}
+
+// SchedulerConfig tunes the sending of scheduled notifications. Zero values use the defaults of
+// notifier.NewScheduler.
+type SchedulerConfig struct {
+	// Interval is the number of seconds between two looks for due notifications
+	Interval int
+	// BatchSize is the number of due notifications fetched at a time
+	BatchSize int
+}
+
+// TermConfig selects the academic term the teacher-level registrations, common students and
+// notifications apply to
+type TermConfig struct {
+	// Active is the code of the active term. When empty the term whose dates include the
+	// current date is active, if any.
+	Active string
 }
 
 type DatabaseConfig struct {
+	// Driver selects the storage backend, either "mysql" (default) or "memory"
+	Driver string
 	Host   string
 	Port   string
 	DBName string
@@ -36,6 +127,8 @@ type DatabaseConfig struct {
 }
 
 type CacheConfig struct {
+	// Driver selects the token store, either "redis" (default) or "memory"
+	Driver   string
 	Host     string
 	Port     string
 	Password string
@@ -74,6 +167,9 @@ func LoadConfig(filename string, fileType string) (*viper.Viper, error) {
 	v.SetConfigType(fileType)
 	v.SetConfigName(filename)
 	v.AddConfigPath(".")
+	// environment variables override the config keys, ADMIN_PASSWORD sets admin.password and
+	// JWT_REFRESHSECRET sets jwt.refreshSecret
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
 
 	err := v.ReadInConfig()
@@ -19,4 +19,42 @@ var _ = Describe("Config", func() {
 		Expect(err).Should(BeNil())
 		Expect(v).ShouldNot(BeNil())
 	})
+	It("ParseConfig should read the admin password from the environment", func() {
+		v, err := config.LoadConfig("../../../configs/config-test", "yml")
+		Expect(err).Should(BeNil())
+		cfg, err := config.ParseConfig(v)
+		Expect(err).Should(BeNil())
+		Expect(cfg.Admin.Password).Should(BeEmpty())
+
+		GinkgoT().Setenv("ADMIN_PASSWORD", "password123")
+		cfg, err = config.ParseConfig(v)
+		Expect(err).Should(BeNil())
+		Expect(cfg.Admin.Password).Should(Equal("password123"))
+	})
+	It("ParseConfig should read the refresh token secret from the environment", func() {
+		v, err := config.LoadConfig("../../../configs/config-test", "yml")
+		Expect(err).Should(BeNil())
+		cfg, err := config.ParseConfig(v)
+		Expect(err).Should(BeNil())
+		Expect(cfg.JWT.Validate()).Should(MatchError("jwt.refreshSecret is not set"))
+
+		GinkgoT().Setenv("JWT_REFRESHSECRET", "testRefreshSecret")
+		cfg, err = config.ParseConfig(v)
+		Expect(err).Should(BeNil())
+		Expect(cfg.JWT.RefreshSecret).Should(Equal("testRefreshSecret"))
+		Expect(cfg.JWT.Validate()).Should(Succeed())
+	})
+	It("JWTConfig.Validate should reject a refresh token secret that also signs access tokens", func() {
+		cfg := config.JWTConfig{Secret: "secret", RefreshSecret: "secret"}
+		Expect(cfg.Validate()).ShouldNot(Succeed())
+
+		cfg = config.JWTConfig{
+			Secret:        "secret",
+			RefreshSecret: "refresh",
+			Keys:          []config.JWTKeyConfig{{ID: "2023-10", Algorithm: "HS256", Secret: "refresh"}},
+		}
+		Expect(cfg.Validate()).ShouldNot(Succeed())
+		cfg.Keys[0].Secret = "current"
+		Expect(cfg.Validate()).Should(Succeed())
+	})
 })
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// DefaultClassCode is the code of the class every teacher has for the students registered to
+// the teacher rather than to one of their classes. The default class has no term.
+const DefaultClassCode = "default"
+
+// Class is a class a teacher runs in a term. Students are registered to classes; the students of
+// a teacher are those of every class of the teacher.
+type Class struct {
+	ID        int        `db:"id"`
+	TeacherID string     `db:"teacher_id"`
+	Code      string     `db:"code"`
+	Title     string     `db:"title"`
+	Term      string     `db:"term"`
+	CreatedOn time.Time  `db:"created_on"`
+	UpdatedOn *time.Time `db:"updated_on"`
+}
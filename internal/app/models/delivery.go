@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Delivery statuses
+const (
+	DeliveryPending = "pending"
+	DeliverySent    = "sent"
+	DeliveryFailed  = "failed"
+)
+
+// Delivery is the delivery of a notification to one of its recipients, kept in the
+// notification outbox until it is sent or given up
+type Delivery struct {
+	ID             int        `db:"id"`
+	NotificationID int        `db:"notification_id"`
+	StudentID      string     `db:"student_id"`
+	Status         string     `db:"status"`
+	Attempts       int        `db:"attempts"`
+	NextAttemptOn  time.Time  `db:"next_attempt_on"`
+	LastError      *string    `db:"last_error"`
+	SentOn         *time.Time `db:"sent_on"`
+	// TeacherID and Message are those of the notification
+	TeacherID string `db:"teacher_id"`
+	Message   string `db:"message"`
+	// Digest is the digest frequency of the student, see Preference
+	Digest string `db:"digest"`
+}
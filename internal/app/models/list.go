@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Sort keys of a listing. Records with the same key are ordered by email.
+const (
+	SortEmail     = "email"
+	SortCreatedOn = "created_on"
+)
+
+// ListFilter selects and orders the records of a student or teacher listing.
+// Zero values leave the filter out.
+type ListFilter struct {
+	// Teacher only lists the students registered to the teacher
+	Teacher string
+	// Suspended only lists the students with (true) or without (false) a suspended registration
+	Suspended    *bool
+	CreatedAfter *time.Time
+	// Query is a prefix of the name or email
+	Query  string
+	SortBy string
+	Desc   bool
+	// After is the sort key of the last record of the previous page
+	After *Cursor
+	Limit int
+}
+
+// Cursor is the sort key of a record in a listing
+type Cursor struct {
+	Email     string    `json:"e"`
+	CreatedOn time.Time `json:"c"`
+}
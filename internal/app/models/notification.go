@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Notification is a message a teacher sent, with the students it was sent to
+type Notification struct {
+	ID         int       `db:"id"`
+	TeacherID  string    `db:"teacher_id"`
+	Message    string    `db:"message"`
+	CreatedOn  time.Time `db:"created_on"`
+	Recipients []string  `db:"-"`
+	// Messages holds the message personalized for each recipient, keyed by email. The
+	// recipients without one received Message.
+	Messages map[string]string `db:"-"`
+	// DeliverAt holds when the email of a recipient is sent, keyed by email, following the
+	// preferences of the recipient. The recipients without one are emailed right away.
+	DeliverAt map[string]time.Time `db:"-"`
+}
+
+// NotificationFilter selects the notifications of a history, latest first.
+// Zero values leave the filter out.
+type NotificationFilter struct {
+	// Teacher only lists the notifications sent by the teacher
+	Teacher string
+	// Student only lists the notifications sent to the student
+	Student string
+	// Before is the ID of the last notification of the previous page
+	Before int
+	Limit  int
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Digest frequencies
+const (
+	DigestImmediate = "immediate"
+	DigestDaily     = "daily"
+	DigestWeekly    = "weekly"
+)
+
+// Preference is how a student wants to receive notifications. A student without preferences
+// receives every notification immediately.
+type Preference struct {
+	StudentID string `db:"student_id"`
+	// Unsubscribed students receive no notification at all, not even the ones mentioning them
+	Unsubscribed bool `db:"unsubscribed"`
+	// BroadcastOptOut students only receive the notifications mentioning them
+	BroadcastOptOut bool `db:"broadcast_opt_out"`
+	// Digest is how often the notification emails of the student are sent, bundled together
+	Digest string `db:"digest"`
+	// QuietStart and QuietEnd are the "HH:MM" bounds of the quiet hours in Timezone, when no
+	// notification email is sent. The quiet hours wrap around midnight when QuietEnd is earlier.
+	QuietStart *string    `db:"quiet_start"`
+	QuietEnd   *string    `db:"quiet_end"`
+	Timezone   string     `db:"timezone"`
+	UpdatedOn  *time.Time `db:"updated_on"`
+}
+
+// DefaultPreference returns the preferences of a student who never set any
+func DefaultPreference(studentEmail string) Preference {
+	return Preference{StudentID: studentEmail, Digest: DigestImmediate, Timezone: "UTC"}
+}
+
+// Receives reports whether the student receives a notification, mentioning them or not
+func (p Preference) Receives(mentioned bool) bool {
+	return !p.Unsubscribed && (mentioned || !p.BroadcastOptOut)
+}
@@ -4,10 +4,12 @@ import (
 	"time"
 )
 
+// Register is the registration of a student to a class. TeacherID is the teacher of the class.
 type Register struct {
 	ID          int        `db:"id"`
 	StudentID   string     `db:"student_id"`
 	TeacherID   string     `db:"teacher_id"`
+	ClassID     int        `db:"class_id"`
 	CreatedOn   time.Time  `db:"created_on"`
 	DeletedOn   *time.Time `db:"deleted_on"`
 	SuspendedOn *time.Time `db:"suspended_on"`
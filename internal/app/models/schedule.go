@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Scheduled notification statuses
+const (
+	SchedulePending   = "pending"
+	ScheduleSent      = "sent"
+	ScheduleCancelled = "cancelled"
+	ScheduleFailed    = "failed"
+)
+
+// ScheduledNotification is a notification a teacher queued to be sent at SendAt. Its recipients
+// are resolved when it is sent. It holds either a Message or a TemplateID with its Variables.
+type ScheduledNotification struct {
+	ID         int               `db:"id"`
+	TeacherID  string            `db:"teacher_id"`
+	Message    string            `db:"message"`
+	TemplateID *int              `db:"template_id"`
+	Variables  map[string]string `db:"variables"`
+	// ClassID only sends the notification to the students of a class of the teacher
+	ClassID *int      `db:"class_id"`
+	SendAt  time.Time `db:"send_at"`
+	Status  string    `db:"status"`
+	// NotificationID is the notification that was sent
+	NotificationID *int       `db:"notification_id"`
+	LastError      *string    `db:"last_error"`
+	CreatedOn      time.Time  `db:"created_on"`
+	UpdatedOn      *time.Time `db:"updated_on"`
+}
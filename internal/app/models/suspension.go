@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Suspension is an entry of the suspension history of a student. A suspension ends when it
+// is lifted or when EndsOn passes. A suspension with a TeacherID only keeps the student out of
+// that teacher's class; without one it applies to every class.
+type Suspension struct {
+	ID          int        `db:"id"`
+	StudentID   string     `db:"student_id"`
+	TeacherID   *string    `db:"teacher_id"`
+	Reason      string     `db:"reason"`
+	SuspendedBy string     `db:"suspended_by"`
+	SuspendedOn time.Time  `db:"suspended_on"`
+	EndsOn      *time.Time `db:"ends_on"`
+	LiftedOn    *time.Time `db:"lifted_on"`
+	LiftedBy    *string    `db:"lifted_by"`
+}
+
+// Active reports whether the suspension is in force at now
+func (s Suspension) Active(now time.Time) bool {
+	return s.LiftedOn == nil && (s.EndsOn == nil || s.EndsOn.After(now))
+}
+
+// AppliesTo reports whether the suspension keeps the student out of the teacher's class
+func (s Suspension) AppliesTo(teacherEmail string) bool {
+	return s.TeacherID == nil || *s.TeacherID == teacherEmail
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Template is a notification a teacher keeps to send again. Its body is a text/template
+// rendered for every recipient, see services.RenderTemplate.
+type Template struct {
+	ID        int        `db:"id"`
+	TeacherID string     `db:"teacher_id"`
+	Name      string     `db:"name"`
+	Body      string     `db:"body"`
+	CreatedOn time.Time  `db:"created_on"`
+	UpdatedOn *time.Time `db:"updated_on"`
+}
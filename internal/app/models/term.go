@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// TermDateLayout is the layout of the start and end dates of a term
+const TermDateLayout = "2006-01-02"
+
+// Term is an academic term. Classes belong to a term, so do the registrations of students to
+// them. StartsOn and EndsOn are dates, the term runs until the end of EndsOn.
+type Term struct {
+	Code      string    `db:"code"`
+	Name      string    `db:"name"`
+	StartsOn  time.Time `db:"starts_on"`
+	EndsOn    time.Time `db:"ends_on"`
+	CreatedOn time.Time `db:"created_on"`
+}
+
+// Includes reports whether at falls within the dates of the term
+func (t Term) Includes(at time.Time) bool {
+	return !at.Before(t.StartsOn) && at.Before(t.EndsOn.AddDate(0, 0, 1))
+}
@@ -1,10 +1,44 @@
 package models
 
+import "time"
+
+// Role values stored in the users.role column
+const (
+	RoleAdmin Role = iota + 1
+	RoleTeacher
+	RoleStudent
+)
+
+// Role is the access level of a user
+type Role int
+
+// roleNames holds the name of each role as used in requests and JWT claims
+var roleNames = map[Role]string{
+	RoleAdmin:   "admin",
+	RoleTeacher: "teacher",
+	RoleStudent: "student",
+}
+
+func (r Role) String() string {
+	return roleNames[r]
+}
+
+// ParseRole returns the role with the given name, or 0 if there is none
+func ParseRole(name string) Role {
+	for role, n := range roleNames {
+		if n == name {
+			return role
+		}
+	}
+	return 0
+}
+
 type User struct {
-	Email        string
-	Username     string
-	Passwordhash string
-	Fullname     string
-	CreateDate   string
-	Role         int
+	ID           int       `db:"id"`
+	Email        string    `db:"email"`
+	Username     string    `db:"username"`
+	Passwordhash string    `db:"password_hash"`
+	Fullname     string    `db:"fullname"`
+	CreateDate   time.Time `db:"created_on"`
+	Role         Role      `db:"role"`
 }
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/opentracing/opentracing-go"
+	log "github.com/sirupsen/logrus"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"time"
+)
+
+type ClassRepository struct {
+	DB *sql.DB
+}
+
+// NewClassRepository an instance of the ClassRepository.
+func NewClassRepository(db *db.MySQL) *ClassRepository {
+	return &ClassRepository{DB: db.DBClient}
+}
+
+// Create adds a class and sets its ID
+func (cl *ClassRepository) Create(ctx context.Context, input *models.Class) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "ClassRepository.Create")
+	defer span.Finish()
+
+	res, err := db.Conn(ctx, cl.DB).ExecContext(ctx, createClassQuery,
+		input.TeacherID,
+		input.Code,
+		input.Title,
+		input.Term,
+	)
+	if err == nil {
+		var id int64
+		id, err = res.LastInsertId()
+		input.ID = int(id)
+		input.CreatedOn = time.Now()
+	}
+	if err != nil {
+		log.Println("[Class][Create][Repository] Problem to querying to db, err: ", err.Error())
+		return db.HandleError(err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves the class of the teacher with the given id
+func (cl *ClassRepository) FindByID(ctx context.Context, teacherEmail string, id int) (models.Class, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "ClassRepository.FindByID")
+	defer span.Finish()
+
+	c, err := scanClass(db.Conn(ctx, cl.DB).QueryRowContext(ctx, getClassQuery, id, teacherEmail).Scan)
+	if err != nil {
+		log.Println("[Class][FindByID][Repository] Problem to querying to db, err: ", err.Error())
+		return c, db.HandleError(err)
+	}
+
+	return c, nil
+}
+
+// FindByTeacher retrieves the classes of the teacher ordered by term and code
+func (cl *ClassRepository) FindByTeacher(ctx context.Context, teacherEmail string) ([]models.Class, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "ClassRepository.FindByTeacher")
+	defer span.Finish()
+
+	classes := []models.Class{}
+	err := queryRows(ctx, db.Conn(ctx, cl.DB), getClassesByTeacherQuery, []interface{}{teacherEmail}, func(rows *sql.Rows) error {
+		c, err := scanClass(rows.Scan)
+		classes = append(classes, c)
+		return err
+	})
+	if err != nil {
+		log.Println("[Class][FindByTeacher][Repository] Problem to querying to db, err: ", err.Error())
+		return nil, db.HandleError(err)
+	}
+
+	return classes, nil
+}
+
+// scanClass reads the classColumns of a row
+func scanClass(scan func(dest ...interface{}) error) (models.Class, error) {
+	var c models.Class
+	err := scan(
+		&c.ID,
+		&c.TeacherID,
+		&c.Code,
+		&c.Title,
+		&c.Term,
+		&c.CreatedOn,
+		&c.UpdatedOn,
+	)
+	return c, err
+}
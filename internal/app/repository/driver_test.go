@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+)
+
+// recordedStmt is a statement received by the recorder driver
+type recordedStmt struct {
+	query string
+	args  []driver.Value
+}
+
+// recorder is a database/sql driver that records every statement and returns no rows,
+// so the SQL the repositories send can be inspected without a MySQL server.
+type recorder struct {
+	mu    sync.Mutex
+	stmts []recordedStmt
+}
+
+func newRecorderDB(r *recorder) *sql.DB {
+	return sql.OpenDB(r)
+}
+
+func (r *recorder) Connect(ctx context.Context) (driver.Conn, error) { return &recorderConn{r: r}, nil }
+func (r *recorder) Driver() driver.Driver                            { return nil }
+
+func (r *recorder) record(query string, args []driver.Value) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stmts = append(r.stmts, recordedStmt{query: query, args: args})
+}
+
+func (r *recorder) recorded() []recordedStmt {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]recordedStmt(nil), r.stmts...)
+}
+
+type recorderConn struct {
+	r *recorder
+}
+
+func (c *recorderConn) Prepare(query string) (driver.Stmt, error) {
+	return &recorderStmt{r: c.r, query: query}, nil
+}
+func (c *recorderConn) Close() error              { return nil }
+func (c *recorderConn) Begin() (driver.Tx, error) { return c, nil }
+func (c *recorderConn) Commit() error             { return nil }
+func (c *recorderConn) Rollback() error           { return nil }
+
+type recorderStmt struct {
+	r     *recorder
+	query string
+}
+
+func (s *recorderStmt) Close() error  { return nil }
+func (s *recorderStmt) NumInput() int { return -1 }
+func (s *recorderStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.r.record(s.query, args)
+	return driver.RowsAffected(0), nil
+}
+func (s *recorderStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.r.record(s.query, args)
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string              { return []string{"email"} }
+func (emptyRows) Close() error                   { return nil }
+func (emptyRows) Next(dest []driver.Value) error { return io.EOF }
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"strings"
+)
+
+const (
+	// personColumns are the columns of the student and teacher tables in the order they are scanned
+	personColumns         = "email, name, created_on, deleted_on, updated_on"
+	registeredStudentCond = "EXISTS (SELECT 1 FROM register WHERE register.student_id = student.email AND register.teacher_id = ? AND register.deleted_on IS NULL)"
+	// suspendedStudentCond matches students suspended globally or from the teacher bound to it
+	suspendedStudentCond = "EXISTS (SELECT 1 FROM suspension_history WHERE suspension_history.student_id = student.email AND " + activeSuspensionCond +
+		" AND (suspension_history.teacher_id IS NULL OR suspension_history.teacher_id = ?))"
+)
+
+// listConditions returns the WHERE conditions of a listing of table and their bound args.
+// The Teacher and Suspended filters only apply to the student table; Suspended follows the
+// suspensions scoped to Teacher. The cursor is only
+// applied when withCursor is set, so the same filter can be counted.
+func listConditions(table string, f models.ListFilter, withCursor bool) ([]string, []interface{}) {
+	conds := []string{table + ".deleted_on IS NULL"}
+	var args []interface{}
+
+	if f.Teacher != "" {
+		conds = append(conds, registeredStudentCond)
+		args = append(args, f.Teacher)
+	}
+	if f.Suspended != nil {
+		if *f.Suspended {
+			conds = append(conds, suspendedStudentCond)
+		} else {
+			conds = append(conds, "NOT "+suspendedStudentCond)
+		}
+		// without a Teacher filter only global suspensions count
+		args = append(args, f.Teacher)
+	}
+	if f.CreatedAfter != nil {
+		conds = append(conds, table+".created_on > ?")
+		args = append(args, *f.CreatedAfter)
+	}
+	if f.Query != "" {
+		prefix := escapeLike(f.Query) + "%"
+		conds = append(conds, "("+table+".email LIKE ? OR "+table+".name LIKE ?)")
+		args = append(args, prefix, prefix)
+	}
+
+	if withCursor && f.After != nil {
+		op := ">"
+		if f.Desc {
+			op = "<"
+		}
+		if f.SortBy == models.SortCreatedOn {
+			conds = append(conds, "("+table+".created_on "+op+" ? OR ("+table+".created_on = ? AND "+table+".email "+op+" ?))")
+			args = append(args, f.After.CreatedOn, f.After.CreatedOn, f.After.Email)
+		} else {
+			conds = append(conds, table+".email "+op+" ?")
+			args = append(args, f.After.Email)
+		}
+	}
+	return conds, args
+}
+
+// listQuery builds the query selecting columns of a page of table
+func listQuery(table, columns string, f models.ListFilter) (string, []interface{}) {
+	conds, args := listConditions(table, f, true)
+
+	dir := "ASC"
+	if f.Desc {
+		dir = "DESC"
+	}
+	order := table + ".email " + dir
+	if f.SortBy == models.SortCreatedOn {
+		order = table + ".created_on " + dir + ", " + order
+	}
+
+	query := "SELECT " + columns + " FROM " + table + " WHERE " + strings.Join(conds, " AND ") + " ORDER BY " + order
+	if f.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, f.Limit)
+	}
+	return query, args
+}
+
+// countQuery builds the query counting every record of table matching the filter
+func countQuery(table string, f models.ListFilter) (string, []interface{}) {
+	conds, args := listConditions(table, f, false)
+	return "SELECT COUNT(*) FROM " + table + " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// escapeLike escapes the LIKE wildcards of s so it is matched literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// queryCount runs a count query
+func queryCount(ctx context.Context, conn db.Querier, query string, args []interface{}) (n int, err error) {
+	err = conn.QueryRowContext(ctx, query, args...).Scan(&n)
+	return n, err
+}
+
+// queryRows runs a listing query and passes every row to scan
+func queryRows(ctx context.Context, conn db.Querier, query string, args []interface{}, scan func(rows *sql.Rows) error) error {
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			return
+		}
+	}(rows)
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"time"
+)
+
+var _ = Describe("List", func() {
+	It("listQuery should bind the filters and continue after the cursor", func() {
+		created := time.Date(2023, 9, 25, 0, 0, 0, 0, time.UTC)
+		suspended := false
+		query, args := listQuery("student", personColumns, models.ListFilter{
+			Teacher:   "teacher1@gmail.com",
+			Suspended: &suspended,
+			Query:     "50%_x",
+			SortBy:    models.SortCreatedOn,
+			Desc:      true,
+			After:     &models.Cursor{Email: "student1@gmail.com", CreatedOn: created},
+			Limit:     11,
+		})
+		Expect(query).Should(Equal("SELECT email, name, created_on, deleted_on, updated_on FROM student WHERE student.deleted_on IS NULL" +
+			" AND " + registeredStudentCond +
+			" AND NOT " + suspendedStudentCond +
+			" AND (student.email LIKE ? OR student.name LIKE ?)" +
+			" AND (student.created_on < ? OR (student.created_on = ? AND student.email < ?))" +
+			" ORDER BY student.created_on DESC, student.email DESC LIMIT ?"))
+		Expect(args).Should(Equal([]interface{}{
+			"teacher1@gmail.com", "teacher1@gmail.com", `50\%\_x%`, `50\%\_x%`, created, created, "student1@gmail.com", 11,
+		}))
+	})
+	It("countQuery should leave out the cursor and limit", func() {
+		query, args := countQuery("teacher", models.ListFilter{
+			After: &models.Cursor{Email: "teacher1@gmail.com"},
+			Limit: 11,
+		})
+		Expect(query).Should(Equal("SELECT COUNT(*) FROM teacher WHERE teacher.deleted_on IS NULL"))
+		Expect(args).Should(BeEmpty())
+	})
+})
+
+var _ = Describe("NotificationList", func() {
+	It("notificationListQuery should bind the filters and continue before the cursor", func() {
+		query, args := notificationListQuery(models.NotificationFilter{
+			Teacher: "teacher1@gmail.com",
+			Student: "student1@gmail.com",
+			Before:  7,
+			Limit:   11,
+		})
+		Expect(query).Should(Equal("SELECT " + notificationColumns + " FROM notification WHERE notification.teacher_id = ?" +
+			" AND " + sentToStudentCond + " AND notification.id < ? ORDER BY notification.id DESC LIMIT ?"))
+		Expect(args).Should(Equal([]interface{}{"teacher1@gmail.com", "student1@gmail.com", 7, 11}))
+	})
+})
@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"sort"
+	"time"
+)
+
+type ClassRepository struct {
+	store *Store
+}
+
+// NewClassRepository an instance of the in-memory ClassRepository.
+func NewClassRepository(s *Store) *ClassRepository {
+	return &ClassRepository{store: s}
+}
+
+// Create adds a class and sets its ID. The code of a class is unique per teacher and term.
+func (cl *ClassRepository) Create(ctx context.Context, input *models.Class) error {
+	cl.store.mu.Lock()
+	defer cl.store.mu.Unlock()
+
+	if _, ok := cl.store.teachers[input.TeacherID]; !ok {
+		return db.ErrReferenceNotFound{}
+	}
+	for _, c := range cl.store.classes {
+		if c.TeacherID == input.TeacherID && c.Term == input.Term && c.Code == input.Code {
+			return db.ErrDuplicateObject{}
+		}
+	}
+
+	input.ID = cl.store.nextID
+	input.CreatedOn = time.Now()
+	cl.store.nextID++
+	cl.store.classes = append(cl.store.classes, *input)
+	return nil
+}
+
+// FindByID retrieves the class of the teacher with the given id
+func (cl *ClassRepository) FindByID(ctx context.Context, teacherEmail string, id int) (models.Class, error) {
+	cl.store.mu.RLock()
+	defer cl.store.mu.RUnlock()
+
+	i := cl.store.findClass(id)
+	if i == -1 || cl.store.classes[i].TeacherID != teacherEmail {
+		return models.Class{}, db.ErrObjectNotFound{}
+	}
+	return cl.store.classes[i], nil
+}
+
+// FindByTeacher retrieves the classes of the teacher ordered by term and code
+func (cl *ClassRepository) FindByTeacher(ctx context.Context, teacherEmail string) ([]models.Class, error) {
+	cl.store.mu.RLock()
+	defer cl.store.mu.RUnlock()
+
+	classes := []models.Class{}
+	for _, c := range cl.store.classes {
+		if c.TeacherID == teacherEmail {
+			classes = append(classes, c)
+		}
+	}
+	sort.SliceStable(classes, func(i, j int) bool {
+		if classes[i].Term != classes[j].Term {
+			return classes[i].Term < classes[j].Term
+		}
+		return classes[i].Code < classes[j].Code
+	})
+	return classes, nil
+}
@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+// person holds the fields of a student or teacher the listings filter and sort on
+type person struct {
+	email     string
+	name      string
+	createdOn time.Time
+}
+
+// matches reports whether p passes the filters of f that do not depend on registrations
+func (p person) matches(f models.ListFilter, withCursor bool) bool {
+	if f.CreatedAfter != nil && !p.createdOn.After(*f.CreatedAfter) {
+		return false
+	}
+	if f.Query != "" && !strings.HasPrefix(strings.ToLower(p.email), strings.ToLower(f.Query)) &&
+		!strings.HasPrefix(strings.ToLower(p.name), strings.ToLower(f.Query)) {
+		return false
+	}
+	if withCursor && f.After != nil {
+		after := person{email: f.After.Email, createdOn: f.After.CreatedOn}
+		return less(f, after, p)
+	}
+	return true
+}
+
+// less reports whether a is listed before b
+func less(f models.ListFilter, a, b person) bool {
+	if f.Desc {
+		a, b = b, a
+	}
+	if f.SortBy == models.SortCreatedOn && !a.createdOn.Equal(b.createdOn) {
+		return a.createdOn.Before(b.createdOn)
+	}
+	return a.email < b.email
+}
+
+// page sorts people and returns the indexes of the first f.Limit of them
+func page(f models.ListFilter, people []person) []int {
+	idx := make([]int, len(people))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(i, j int) bool {
+		return less(f, people[idx[i]], people[idx[j]])
+	})
+	if f.Limit > 0 && len(idx) > f.Limit {
+		idx = idx[:f.Limit]
+	}
+	return idx
+}
@@ -0,0 +1,281 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/app/repository/memory"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"time"
+)
+
+var _ = Describe("Memory", func() {
+	var (
+		ctx   context.Context
+		store *memory.Store
+		sr    *memory.StudentRepository
+		tr    *memory.TeacherRepository
+		rr    *memory.RegisterRepository
+		hr    *memory.SuspensionRepository
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		store = memory.New()
+		sr = memory.NewStudentRepository(store)
+		tr = memory.NewTeacherRepository(store)
+		rr = memory.NewRegisterRepository(store)
+		hr = memory.NewSuspensionRepository(store)
+
+		Expect(tr.Create(ctx, &models.Teacher{Email: "teacher1@gmail.com", Name: "Teacher1"})).Should(Succeed())
+		Expect(tr.Create(ctx, &models.Teacher{Email: "teacher2@gmail.com", Name: "Teacher2"})).Should(Succeed())
+		for _, email := range []string{"student1@gmail.com", "student2@gmail.com", "student3@gmail.com"} {
+			Expect(sr.Create(ctx, &models.Student{Email: email})).Should(Succeed())
+		}
+	})
+
+	It("FindByEmail should return ErrObjectNotFound for unknown emails", func() {
+		_, err := sr.FindByEmail(ctx, "nobody@gmail.com")
+		Expect(err).Should(MatchError(db.ErrObjectNotFound{}))
+		_, err = tr.FindByEmail(ctx, "nobody@gmail.com")
+		Expect(err).Should(MatchError(db.ErrObjectNotFound{}))
+	})
+	It("Create should reject duplicate emails", func() {
+		Expect(sr.Create(ctx, &models.Student{Email: "student1@gmail.com"})).Should(MatchError(db.ErrDuplicateObject{}))
+		Expect(tr.Create(ctx, &models.Teacher{Email: "teacher1@gmail.com"})).Should(MatchError(db.ErrDuplicateObject{}))
+	})
+	It("Register should reject an existing (student, teacher) pair without storing the batch", func() {
+		Expect(rr.Register(ctx, "teacher1@gmail.com", "", []string{"student1@gmail.com"})).Should(Succeed())
+		err := rr.Register(ctx, "teacher1@gmail.com", "", []string{"student2@gmail.com", "student1@gmail.com"})
+		Expect(err).Should(MatchError(db.ErrDuplicateObject{}))
+
+		res, err := rr.FindByEmailArr(ctx, []string{"teacher1@gmail.com"}, "")
+		Expect(err).Should(BeNil())
+		Expect(res).Should(Equal([]string{"student1@gmail.com"}))
+	})
+	It("Register should reject unknown teachers and students", func() {
+		err := rr.Register(ctx, "nobody@gmail.com", "", []string{"student1@gmail.com"})
+		Expect(err).Should(MatchError(db.ErrReferenceNotFound{}))
+		err = rr.Register(ctx, "teacher1@gmail.com", "", []string{"nobody@gmail.com"})
+		Expect(err).Should(MatchError(db.ErrReferenceNotFound{}))
+	})
+	It("Update should move the registrations to the new email", func() {
+		Expect(rr.Register(ctx, "teacher1@gmail.com", "", []string{"student1@gmail.com"})).Should(Succeed())
+		Expect(sr.Update(ctx, "student1@gmail.com", &models.Student{Email: "student2@gmail.com"})).Should(MatchError(db.ErrDuplicateObject{}))
+		Expect(sr.Update(ctx, "student1@gmail.com", &models.Student{Email: "renamed@gmail.com", Name: "Renamed"})).Should(Succeed())
+
+		_, err := sr.FindByEmail(ctx, "student1@gmail.com")
+		Expect(err).Should(MatchError(db.ErrObjectNotFound{}))
+		student, err := sr.FindByEmail(ctx, "renamed@gmail.com")
+		Expect(err).Should(BeNil())
+		Expect(student.Name).Should(Equal("Renamed"))
+		Expect(student.UpdatedOn).ShouldNot(BeNil())
+
+		res, err := rr.FindByEmailArr(ctx, []string{"teacher1@gmail.com"}, "")
+		Expect(err).Should(BeNil())
+		Expect(res).Should(Equal([]string{"renamed@gmail.com"}))
+	})
+	It("Update should move the preferences to the new email", func() {
+		pr := memory.NewPreferenceRepository(store)
+		Expect(pr.Save(ctx, &models.Preference{StudentID: "nobody@gmail.com"})).Should(MatchError(db.ErrReferenceNotFound{}))
+		Expect(pr.Save(ctx, &models.Preference{StudentID: "student1@gmail.com", Unsubscribed: true})).Should(Succeed())
+		Expect(sr.Update(ctx, "student1@gmail.com", &models.Student{Email: "renamed@gmail.com"})).Should(Succeed())
+
+		_, err := pr.FindByStudent(ctx, "student1@gmail.com")
+		Expect(err).Should(MatchError(db.ErrObjectNotFound{}))
+		p, err := pr.FindByStudent(ctx, "renamed@gmail.com")
+		Expect(err).Should(BeNil())
+		Expect(p.StudentID).Should(Equal("renamed@gmail.com"))
+		Expect(p.Unsubscribed).Should(BeTrue())
+	})
+	It("teacher Update should move the classes and their students to the new email", func() {
+		cl := memory.NewClassRepository(store)
+		class := models.Class{TeacherID: "teacher1@gmail.com", Code: "MATH101"}
+		Expect(cl.Create(ctx, &class)).Should(Succeed())
+		Expect(rr.Enroll(ctx, class.ID, []string{"student1@gmail.com"})).Should(Succeed())
+		Expect(tr.Update(ctx, "teacher1@gmail.com", &models.Teacher{Email: "renamed@gmail.com"})).Should(Succeed())
+
+		_, err := cl.FindByID(ctx, "teacher1@gmail.com", class.ID)
+		Expect(err).Should(MatchError(db.ErrObjectNotFound{}))
+		c, err := cl.FindByID(ctx, "renamed@gmail.com", class.ID)
+		Expect(err).Should(BeNil())
+		Expect(c.Code).Should(Equal("MATH101"))
+		res, err := rr.FindByEmailArr(ctx, []string{"renamed@gmail.com"}, "")
+		Expect(err).Should(BeNil())
+		Expect(res).Should(Equal([]string{"student1@gmail.com"}))
+	})
+	It("CopyTerm should copy the classes of a term once and ArchiveTerm should archive its registrations", func() {
+		cl := memory.NewClassRepository(store)
+		class := models.Class{TeacherID: "teacher1@gmail.com", Code: "MATH101", Term: "2024-1"}
+		Expect(cl.Create(ctx, &class)).Should(Succeed())
+		Expect(rr.Enroll(ctx, class.ID, []string{"student1@gmail.com", "student2@gmail.com"})).Should(Succeed())
+		Expect(rr.Register(ctx, "teacher1@gmail.com", "2024-2", []string{"student3@gmail.com"})).Should(Succeed())
+
+		Expect(rr.CopyTerm(ctx, "2024-1", "2024-2")).Should(Succeed())
+		Expect(rr.CopyTerm(ctx, "2024-1", "2024-2")).Should(Succeed())
+		classes, err := cl.FindByTeacher(ctx, "teacher1@gmail.com")
+		Expect(err).Should(BeNil())
+		Expect(classes).Should(HaveLen(3))
+		res, err := rr.FindByEmailArr(ctx, []string{"teacher1@gmail.com"}, "2024-2")
+		Expect(err).Should(BeNil())
+		Expect(res).Should(Equal([]string{"student1@gmail.com", "student2@gmail.com", "student3@gmail.com"}))
+
+		archived, err := rr.ArchiveTerm(ctx, "2024-1")
+		Expect(err).Should(BeNil())
+		Expect(archived).Should(Equal(2))
+		res, err = rr.FindByEmailArr(ctx, []string{"teacher1@gmail.com"}, "2024-1")
+		Expect(err).Should(BeNil())
+		Expect(res).Should(BeEmpty())
+		active, _, err := rr.CountByTeacher(ctx, "teacher1@gmail.com", "2024-2")
+		Expect(err).Should(BeNil())
+		Expect(active).Should(Equal(3))
+	})
+	It("Delete should leave the student out of every read until it is restored", func() {
+		Expect(rr.Register(ctx, "teacher1@gmail.com", "", []string{"student1@gmail.com", "student2@gmail.com"})).Should(Succeed())
+		Expect(sr.Delete(ctx, "student1@gmail.com")).Should(Succeed())
+		Expect(sr.Delete(ctx, "student1@gmail.com")).Should(MatchError(db.ErrObjectNotFound{}))
+
+		_, err := sr.FindByEmail(ctx, "student1@gmail.com")
+		Expect(err).Should(MatchError(db.ErrObjectNotFound{}))
+		res, err := sr.FindByEmailArr(ctx, []string{"student1@gmail.com", "student2@gmail.com"}, true)
+		Expect(err).Should(BeNil())
+		Expect(res).Should(Equal([]string{"student2@gmail.com"}))
+		res, err = rr.FindByEmailArr(ctx, []string{"teacher1@gmail.com"}, "")
+		Expect(err).Should(BeNil())
+		Expect(res).Should(Equal([]string{"student2@gmail.com"}))
+
+		Expect(sr.Restore(ctx, "student1@gmail.com")).Should(Succeed())
+		Expect(sr.Restore(ctx, "student1@gmail.com")).Should(MatchError(db.ErrObjectNotFound{}))
+		res, err = rr.FindByEmailArr(ctx, []string{"teacher1@gmail.com"}, "")
+		Expect(err).Should(BeNil())
+		Expect(res).Should(Equal([]string{"student1@gmail.com", "student2@gmail.com"}))
+	})
+	It("Unregister should remove only the registered students and allow registering them again", func() {
+		Expect(rr.Register(ctx, "teacher1@gmail.com", "", []string{"student1@gmail.com", "student2@gmail.com"})).Should(Succeed())
+
+		res, err := rr.Unregister(ctx, "teacher1@gmail.com", "", []string{"student1@gmail.com", "student3@gmail.com"})
+		Expect(err).Should(BeNil())
+		Expect(res).Should(Equal([]string{"student1@gmail.com"}))
+		res, err = rr.Unregister(ctx, "teacher1@gmail.com", "", []string{"student1@gmail.com"})
+		Expect(err).Should(BeNil())
+		Expect(res).Should(BeEmpty())
+
+		res, err = rr.FindByEmailArr(ctx, []string{"teacher1@gmail.com"}, "")
+		Expect(err).Should(BeNil())
+		Expect(res).Should(Equal([]string{"student2@gmail.com"}))
+
+		Expect(rr.Register(ctx, "teacher1@gmail.com", "", []string{"student1@gmail.com"})).Should(Succeed())
+		res, err = rr.FindByEmailArr(ctx, []string{"teacher1@gmail.com"}, "")
+		Expect(err).Should(BeNil())
+		Expect(res).Should(Equal([]string{"student1@gmail.com", "student2@gmail.com"}))
+	})
+	It("Suspend should hide the student from every teacher", func() {
+		Expect(rr.Register(ctx, "teacher1@gmail.com", "", []string{"student1@gmail.com", "student2@gmail.com"})).Should(Succeed())
+		Expect(rr.Register(ctx, "teacher2@gmail.com", "", []string{"student1@gmail.com", "student3@gmail.com"})).Should(Succeed())
+		Expect(hr.Create(ctx, &models.Suspension{StudentID: "student1@gmail.com", Reason: "misconduct"})).Should(Succeed())
+
+		res, err := rr.FindByEmailArr(ctx, []string{"teacher1@gmail.com", "teacher2@gmail.com"}, "")
+		Expect(err).Should(BeNil())
+		Expect(res).Should(Equal([]string{"student2@gmail.com", "student3@gmail.com"}))
+
+		res, err = sr.FindByEmailArr(ctx, []string{"student1@gmail.com"}, false)
+		Expect(err).Should(BeNil())
+		Expect(res).Should(BeEmpty())
+		res, err = sr.FindByEmailArr(ctx, []string{"student1@gmail.com"}, true)
+		Expect(err).Should(BeNil())
+		Expect(res).Should(Equal([]string{"student1@gmail.com"}))
+	})
+	It("Suspension scoped to a teacher should only hide the student from that teacher", func() {
+		Expect(rr.Register(ctx, "teacher1@gmail.com", "", []string{"student1@gmail.com", "student2@gmail.com"})).Should(Succeed())
+		Expect(rr.Register(ctx, "teacher2@gmail.com", "", []string{"student1@gmail.com"})).Should(Succeed())
+		teacher := "teacher1@gmail.com"
+		Expect(hr.Create(ctx, &models.Suspension{StudentID: "student1@gmail.com", TeacherID: &teacher, Reason: "late"})).Should(Succeed())
+
+		res, err := rr.FindByEmailArr(ctx, []string{"teacher1@gmail.com"}, "")
+		Expect(err).Should(BeNil())
+		Expect(res).Should(Equal([]string{"student2@gmail.com"}))
+		res, err = rr.FindByEmailArr(ctx, []string{"teacher2@gmail.com"}, "")
+		Expect(err).Should(BeNil())
+		Expect(res).Should(Equal([]string{"student1@gmail.com"}))
+
+		res, err = sr.FindNotSuspended(ctx, []string{"student1@gmail.com"}, "teacher1@gmail.com")
+		Expect(err).Should(BeNil())
+		Expect(res).Should(BeEmpty())
+		res, err = sr.FindNotSuspended(ctx, []string{"student1@gmail.com"}, "teacher2@gmail.com")
+		Expect(err).Should(BeNil())
+		Expect(res).Should(Equal([]string{"student1@gmail.com"}))
+
+		_, err = hr.FindActive(ctx, "student1@gmail.com", "")
+		Expect(err).Should(MatchError(db.ErrObjectNotFound{}))
+		lifted, err := hr.Lift(ctx, "student1@gmail.com", "teacher2@gmail.com", "teacher2@gmail.com")
+		Expect(err).Should(BeNil())
+		Expect(lifted).Should(BeZero())
+		lifted, err = hr.Lift(ctx, "student1@gmail.com", "teacher1@gmail.com", "teacher1@gmail.com")
+		Expect(err).Should(BeNil())
+		Expect(lifted).Should(Equal(1))
+	})
+	It("Suspensions should end when lifted or when their end date passes", func() {
+		past := time.Now().Add(-time.Hour)
+		Expect(hr.Create(ctx, &models.Suspension{StudentID: "student1@gmail.com", Reason: "over", EndsOn: &past})).Should(Succeed())
+		_, err := hr.FindActive(ctx, "student1@gmail.com", "")
+		Expect(err).Should(MatchError(db.ErrObjectNotFound{}))
+
+		Expect(hr.Create(ctx, &models.Suspension{StudentID: "student1@gmail.com", Reason: "misconduct"})).Should(Succeed())
+		active, err := hr.FindActive(ctx, "student1@gmail.com", "")
+		Expect(err).Should(BeNil())
+		Expect(active.Reason).Should(Equal("misconduct"))
+
+		lifted, err := hr.Lift(ctx, "student1@gmail.com", "", "admin@gmail.com")
+		Expect(err).Should(BeNil())
+		Expect(lifted).Should(Equal(1))
+		_, err = hr.FindActive(ctx, "student1@gmail.com", "")
+		Expect(err).Should(MatchError(db.ErrObjectNotFound{}))
+
+		history, err := hr.FindByStudent(ctx, "student1@gmail.com")
+		Expect(err).Should(BeNil())
+		Expect(history).Should(HaveLen(2))
+		Expect(history[0].Reason).Should(Equal("misconduct"))
+		Expect(*history[0].LiftedBy).Should(Equal("admin@gmail.com"))
+		Expect(history[1].LiftedOn).Should(BeNil())
+	})
+	It("UnitOfWork should roll back every write when the unit fails", func() {
+		uow := memory.NewUnitOfWork(store)
+		err := uow.Do(ctx, func(ctx context.Context) error {
+			Expect(sr.Create(ctx, &models.Student{Email: "student4@gmail.com"})).Should(Succeed())
+			Expect(rr.Register(ctx, "teacher1@gmail.com", "", []string{"student4@gmail.com"})).Should(Succeed())
+			return errors.New("boom")
+		})
+		Expect(err).Should(MatchError("boom"))
+
+		_, err = sr.FindByEmail(ctx, "student4@gmail.com")
+		Expect(err).Should(MatchError(db.ErrObjectNotFound{}))
+		res, err := rr.FindByEmailArr(ctx, []string{"teacher1@gmail.com"}, "")
+		Expect(err).Should(BeNil())
+		Expect(res).Should(BeEmpty())
+	})
+	It("UnitOfWork should roll back the changes made in place to an existing row", func() {
+		pr := memory.NewPreferenceRepository(store)
+		sc := memory.NewScheduleRepository(store)
+		start := "22:00"
+		Expect(pr.Save(ctx, &models.Preference{StudentID: "student1@gmail.com", QuietStart: &start})).Should(Succeed())
+		schedule := models.ScheduledNotification{TeacherID: "teacher1@gmail.com", Variables: map[string]string{"day": "Friday"}}
+		Expect(sc.Create(ctx, &schedule)).Should(Succeed())
+
+		uow := memory.NewUnitOfWork(store)
+		err := uow.Do(ctx, func(ctx context.Context) error {
+			start = "23:00"
+			schedule.Variables["day"] = "Monday"
+			return errors.New("boom")
+		})
+		Expect(err).Should(MatchError("boom"))
+
+		p, err := pr.FindByStudent(ctx, "student1@gmail.com")
+		Expect(err).Should(BeNil())
+		Expect(*p.QuietStart).Should(Equal("22:00"))
+		s, err := sc.FindByID(ctx, "teacher1@gmail.com", schedule.ID)
+		Expect(err).Should(BeNil())
+		Expect(s.Variables).Should(Equal(map[string]string{"day": "Friday"}))
+	})
+})
@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"context"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"time"
+)
+
+type NotificationRepository struct {
+	store *Store
+}
+
+// NewNotificationRepository an instance of the in-memory NotificationRepository.
+func NewNotificationRepository(s *Store) *NotificationRepository {
+	return &NotificationRepository{store: s}
+}
+
+// Create persists the notification with its recipients and queues a delivery to every recipient
+// in the notification outbox
+func (nr *NotificationRepository) Create(ctx context.Context, input *models.Notification) error {
+	nr.store.mu.Lock()
+	defer nr.store.mu.Unlock()
+
+	if _, ok := nr.store.teachers[input.TeacherID]; !ok {
+		return db.ErrReferenceNotFound{}
+	}
+	for _, email := range input.Recipients {
+		if _, ok := nr.store.students[email]; !ok {
+			return db.ErrReferenceNotFound{}
+		}
+	}
+
+	input.ID = nr.store.nextID
+	input.CreatedOn = time.Now()
+	nr.store.nextID++
+	n := *input
+	n.Recipients = append([]string{}, input.Recipients...)
+	n.Messages = copyMessages(input.Messages)
+	// the delivery dates are kept in the outbox
+	n.DeliverAt = nil
+	nr.store.notifications = append(nr.store.notifications, n)
+
+	for _, email := range input.Recipients {
+		deliverAt, ok := input.DeliverAt[email]
+		if !ok {
+			deliverAt = input.CreatedOn
+		}
+		nr.store.deliveries = append(nr.store.deliveries, models.Delivery{
+			ID:             nr.store.nextID,
+			NotificationID: input.ID,
+			StudentID:      email,
+			Status:         models.DeliveryPending,
+			NextAttemptOn:  deliverAt,
+		})
+		nr.store.nextID++
+	}
+	return nil
+}
+
+// List retrieves the notifications matching the filter with their recipients, latest first.
+// When filtering on a student the message is the one the student received.
+func (nr *NotificationRepository) List(ctx context.Context, f models.NotificationFilter) ([]models.Notification, error) {
+	nr.store.mu.RLock()
+	defer nr.store.mu.RUnlock()
+
+	notifications := []models.Notification{}
+	for i := len(nr.store.notifications) - 1; i >= 0; i-- {
+		n := nr.store.notifications[i]
+		if (f.Teacher != "" && n.TeacherID != f.Teacher) ||
+			(f.Student != "" && !contains(n.Recipients, f.Student)) ||
+			(f.Before > 0 && n.ID >= f.Before) {
+			continue
+		}
+		if f.Limit > 0 && len(notifications) == f.Limit {
+			break
+		}
+		n.Recipients = append([]string{}, n.Recipients...)
+		n.Messages = copyMessages(n.Messages)
+		if m, ok := n.Messages[f.Student]; ok && f.Student != "" {
+			n.Message = m
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, nil
+}
+
+// contains reports whether values holds v
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// copyMessages returns a copy of the personalized messages of a notification, or of other string
+// maps such as the variables of a scheduled notification, nil when there are none
+func copyMessages(messages map[string]string) map[string]string {
+	if len(messages) == 0 {
+		return nil
+	}
+	c := make(map[string]string, len(messages))
+	for k, v := range messages {
+		c[k] = v
+	}
+	return c
+}
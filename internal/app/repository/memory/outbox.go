@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"context"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"sort"
+	"time"
+)
+
+// OutboxRepository records the outcome of the deliveries through a UnitOfWork, as the dispatcher
+// writes outside of the units of work of the service and the store is otherwise restored over its
+// writes when a unit of work running meanwhile fails.
+type OutboxRepository struct {
+	store *Store
+	uow   *UnitOfWork
+}
+
+// NewOutboxRepository an instance of the in-memory OutboxRepository.
+func NewOutboxRepository(s *Store) *OutboxRepository {
+	return &OutboxRepository{store: s, uow: NewUnitOfWork(s)}
+}
+
+// Due retrieves up to limit pending deliveries whose next attempt is due at now, oldest first
+func (ob *OutboxRepository) Due(ctx context.Context, now time.Time, limit int) ([]models.Delivery, error) {
+	deliveries := ob.due(now, "")
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+// DueByStudent retrieves every pending delivery to the student whose next attempt is due at now, oldest first
+func (ob *OutboxRepository) DueByStudent(ctx context.Context, studentEmail string, now time.Time) ([]models.Delivery, error) {
+	return ob.due(now, studentEmail), nil
+}
+
+// due returns the pending deliveries due at now, to the student when studentEmail is given, oldest first
+func (ob *OutboxRepository) due(now time.Time, studentEmail string) []models.Delivery {
+	ob.store.mu.RLock()
+	defer ob.store.mu.RUnlock()
+
+	deliveries := []models.Delivery{}
+	for _, d := range ob.store.deliveries {
+		if d.Status != models.DeliveryPending || d.NextAttemptOn.After(now) ||
+			(studentEmail != "" && d.StudentID != studentEmail) {
+			continue
+		}
+		d.TeacherID, d.Message = ob.store.notificationOf(d)
+		d.Digest = ob.store.digestOf(d.StudentID)
+		deliveries = append(deliveries, d)
+	}
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].NextAttemptOn.Before(deliveries[j].NextAttemptOn)
+	})
+	return deliveries
+}
+
+// Claim leases the pending delivery due at now until the given time, when it is due again if it was
+// neither sent nor failed. It returns db.ErrObjectNotFound if the delivery is no longer due.
+func (ob *OutboxRepository) Claim(ctx context.Context, id int, now, until time.Time) error {
+	return ob.uow.Do(ctx, func(ctx context.Context) error {
+		ob.store.mu.Lock()
+		defer ob.store.mu.Unlock()
+
+		i := ob.store.findDelivery(id)
+		if i == -1 || ob.store.deliveries[i].Status != models.DeliveryPending || ob.store.deliveries[i].NextAttemptOn.After(now) {
+			return db.ErrObjectNotFound{}
+		}
+		ob.store.deliveries[i].NextAttemptOn = until
+		return nil
+	})
+}
+
+// MarkSent records that the delivery was sent
+func (ob *OutboxRepository) MarkSent(ctx context.Context, id int) error {
+	return ob.uow.Do(ctx, func(ctx context.Context) error {
+		ob.store.mu.Lock()
+		defer ob.store.mu.Unlock()
+
+		if i := ob.store.findDelivery(id); i > -1 {
+			now := time.Now()
+			d := &ob.store.deliveries[i]
+			d.Status = models.DeliverySent
+			d.Attempts++
+			d.LastError = nil
+			d.SentOn = &now
+		}
+		return nil
+	})
+}
+
+// MarkFailed records a failed attempt of the delivery. It is retried at next, or given up when next is nil.
+func (ob *OutboxRepository) MarkFailed(ctx context.Context, id int, reason string, next *time.Time) error {
+	return ob.uow.Do(ctx, func(ctx context.Context) error {
+		ob.store.mu.Lock()
+		defer ob.store.mu.Unlock()
+
+		if i := ob.store.findDelivery(id); i > -1 {
+			d := &ob.store.deliveries[i]
+			d.Attempts++
+			d.LastError = &reason
+			if next == nil {
+				d.Status = models.DeliveryFailed
+			} else {
+				d.NextAttemptOn = *next
+			}
+		}
+		return nil
+	})
+}
+
+// FindByNotification retrieves the deliveries of the notification
+func (ob *OutboxRepository) FindByNotification(ctx context.Context, notificationID int) ([]models.Delivery, error) {
+	ob.store.mu.RLock()
+	defer ob.store.mu.RUnlock()
+
+	deliveries := []models.Delivery{}
+	for _, d := range ob.store.deliveries {
+		if d.NotificationID == notificationID {
+			d.TeacherID, d.Message = ob.store.notificationOf(d)
+			d.Digest = ob.store.digestOf(d.StudentID)
+			deliveries = append(deliveries, d)
+		}
+	}
+	return deliveries, nil
+}
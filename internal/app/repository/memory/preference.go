@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"context"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"time"
+)
+
+type PreferenceRepository struct {
+	store *Store
+}
+
+// NewPreferenceRepository an instance of the in-memory PreferenceRepository.
+func NewPreferenceRepository(s *Store) *PreferenceRepository {
+	return &PreferenceRepository{store: s}
+}
+
+// FindByStudent retrieves the notification preferences of the student
+func (pr *PreferenceRepository) FindByStudent(ctx context.Context, studentEmail string) (models.Preference, error) {
+	pr.store.mu.RLock()
+	defer pr.store.mu.RUnlock()
+
+	p, ok := pr.store.preferences[studentEmail]
+	if !ok {
+		return models.Preference{}, db.ErrObjectNotFound{}
+	}
+	return p, nil
+}
+
+// FindByStudents retrieves the notification preferences of the given students that set any
+func (pr *PreferenceRepository) FindByStudents(ctx context.Context, studentEmails []string) ([]models.Preference, error) {
+	pr.store.mu.RLock()
+	defer pr.store.mu.RUnlock()
+
+	preferences := []models.Preference{}
+	for _, email := range studentEmails {
+		if p, ok := pr.store.preferences[email]; ok {
+			preferences = append(preferences, p)
+		}
+	}
+	return preferences, nil
+}
+
+// Save creates or replaces the notification preferences of the student
+func (pr *PreferenceRepository) Save(ctx context.Context, input *models.Preference) error {
+	pr.store.mu.Lock()
+	defer pr.store.mu.Unlock()
+
+	if _, ok := pr.store.students[input.StudentID]; !ok {
+		return db.ErrReferenceNotFound{}
+	}
+	now := time.Now()
+	input.UpdatedOn = &now
+	pr.store.preferences[input.StudentID] = *input
+	return nil
+}
@@ -0,0 +1,277 @@
+package memory
+
+import (
+	"context"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"sort"
+	"time"
+)
+
+type RegisterRepository struct {
+	store *Store
+}
+
+// NewRegisterRepository an instance of the in-memory RegisterRepository.
+func NewRegisterRepository(s *Store) *RegisterRepository {
+	return &RegisterRepository{store: s}
+}
+
+// Register registers the students to the default class of the teacher in the term. All the
+// registrations are validated before any is stored so a failing batch leaves the store untouched.
+func (rr *RegisterRepository) Register(ctx context.Context, teacherEmail, term string, studentEmails []string) error {
+	rr.store.mu.Lock()
+	defer rr.store.mu.Unlock()
+
+	if _, ok := rr.store.teachers[teacherEmail]; !ok {
+		return db.ErrReferenceNotFound{}
+	}
+	seen := make(map[string]bool, len(studentEmails))
+	for _, email := range studentEmails {
+		if _, ok := rr.store.students[email]; !ok {
+			return db.ErrReferenceNotFound{}
+		}
+		if seen[email] || rr.store.registeredTo(email, teacherEmail, term) {
+			return db.ErrDuplicateObject{}
+		}
+		seen[email] = true
+	}
+
+	c := rr.store.defaultClass(teacherEmail, term)
+	now := time.Now()
+	for _, email := range studentEmails {
+		rr.store.enroll(email, c, now)
+	}
+	return nil
+}
+
+// Upsert registers the students like Register, skipping the students that are already registered
+// to one of the classes of the teacher in the term
+func (rr *RegisterRepository) Upsert(ctx context.Context, teacherEmail, term string, studentEmails []string) error {
+	rr.store.mu.Lock()
+	defer rr.store.mu.Unlock()
+
+	if _, ok := rr.store.teachers[teacherEmail]; !ok {
+		return db.ErrReferenceNotFound{}
+	}
+	for _, email := range studentEmails {
+		if _, ok := rr.store.students[email]; !ok {
+			return db.ErrReferenceNotFound{}
+		}
+	}
+
+	var rest []string
+	for _, email := range studentEmails {
+		if !rr.store.registeredTo(email, teacherEmail, term) {
+			rest = append(rest, email)
+		}
+	}
+	if len(rest) == 0 {
+		return nil
+	}
+	c := rr.store.defaultClass(teacherEmail, term)
+	now := time.Now()
+	for _, email := range rest {
+		rr.store.enroll(email, c, now)
+	}
+	return nil
+}
+
+// FindRegistered retrieves the given students that are registered to one of the classes of the teacher in the term
+func (rr *RegisterRepository) FindRegistered(ctx context.Context, teacherEmail, term string, studentEmails []string) ([]string, error) {
+	rr.store.mu.RLock()
+	defer rr.store.mu.RUnlock()
+
+	var registered []string
+	for _, email := range studentEmails {
+		if rr.store.registeredTo(email, teacherEmail, term) {
+			registered = append(registered, email)
+		}
+	}
+	return registered, nil
+}
+
+// Enroll registers the students to the class, skipping the students that are already enrolled
+func (rr *RegisterRepository) Enroll(ctx context.Context, classID int, studentEmails []string) error {
+	rr.store.mu.Lock()
+	defer rr.store.mu.Unlock()
+
+	i := rr.store.findClass(classID)
+	if i == -1 {
+		return db.ErrReferenceNotFound{}
+	}
+	for _, email := range studentEmails {
+		if _, ok := rr.store.students[email]; !ok {
+			return db.ErrReferenceNotFound{}
+		}
+	}
+
+	now := time.Now()
+	for _, email := range studentEmails {
+		rr.store.enroll(email, rr.store.classes[i], now)
+	}
+	return nil
+}
+
+// Unenroll soft deletes the registrations of the students to the class and returns the students
+// that were enrolled
+func (rr *RegisterRepository) Unenroll(ctx context.Context, classID int, studentEmails []string) ([]string, error) {
+	rr.store.mu.Lock()
+	defer rr.store.mu.Unlock()
+
+	now := time.Now()
+	var unenrolled []string
+	for _, email := range studentEmails {
+		i := rr.store.findRegister(email, classID)
+		if i == -1 || rr.store.registers[i].DeletedOn != nil {
+			continue
+		}
+		rr.store.registers[i].DeletedOn = &now
+		unenrolled = append(unenrolled, email)
+	}
+	return unenrolled, nil
+}
+
+// FindByClasses retrieves the non-suspended students enrolled to any of the given classes
+func (rr *RegisterRepository) FindByClasses(ctx context.Context, classIDs []int) ([]string, error) {
+	wanted := make(map[int]bool, len(classIDs))
+	for _, id := range classIDs {
+		wanted[id] = true
+	}
+	return rr.find(func(reg models.Register) bool { return wanted[reg.ClassID] }), nil
+}
+
+// FindByEmailArr retrieves the non-suspended students registered to the classes in the term of any of the given teachers
+func (rr *RegisterRepository) FindByEmailArr(ctx context.Context, emails []string, term string) ([]string, error) {
+	wanted := make(map[string]bool, len(emails))
+	for _, email := range emails {
+		wanted[email] = true
+	}
+	return rr.find(func(reg models.Register) bool { return wanted[reg.TeacherID] && rr.store.termOf(reg) == term }), nil
+}
+
+// find retrieves the non-suspended students of the registrations matching the filter, sorted
+func (rr *RegisterRepository) find(filter func(reg models.Register) bool) []string {
+	rr.store.mu.RLock()
+	defer rr.store.mu.RUnlock()
+
+	now := time.Now()
+	seen := map[string]bool{}
+	var studentEmails []string
+	for _, reg := range rr.store.registers {
+		if !filter(reg) || reg.DeletedOn != nil || seen[reg.StudentID] || rr.store.suspended(reg.StudentID, reg.TeacherID, now) {
+			continue
+		}
+		if student := rr.store.students[reg.StudentID]; student.DeletedOn != nil {
+			continue
+		}
+		if teacher := rr.store.teachers[reg.TeacherID]; teacher.DeletedOn != nil {
+			continue
+		}
+		seen[reg.StudentID] = true
+		studentEmails = append(studentEmails, reg.StudentID)
+	}
+	// keep the response order stable between calls
+	sort.Strings(studentEmails)
+	return studentEmails
+}
+
+// CountByTeacher counts the active and suspended students registered to the classes of the teacher in the term
+func (rr *RegisterRepository) CountByTeacher(ctx context.Context, email, term string) (active, suspended int, err error) {
+	rr.store.mu.RLock()
+	defer rr.store.mu.RUnlock()
+
+	now := time.Now()
+	// a student is counted once, whatever the number of classes of the teacher they are in
+	counted := map[string]bool{}
+	for _, reg := range rr.store.registers {
+		if reg.TeacherID != email || reg.DeletedOn != nil || counted[reg.StudentID] || rr.store.termOf(reg) != term ||
+			rr.store.students[reg.StudentID].DeletedOn != nil {
+			continue
+		}
+		counted[reg.StudentID] = true
+		if rr.store.suspended(reg.StudentID, reg.TeacherID, now) {
+			suspended++
+		} else {
+			active++
+		}
+	}
+	return active, suspended, nil
+}
+
+// Unregister soft deletes the registrations of the students to every class of the teacher in the
+// term and returns the students that were registered
+func (rr *RegisterRepository) Unregister(ctx context.Context, teacherEmail, term string, studentEmails []string) ([]string, error) {
+	rr.store.mu.Lock()
+	defer rr.store.mu.Unlock()
+
+	now := time.Now()
+	var unregistered []string
+	for _, email := range studentEmails {
+		if !rr.store.registeredTo(email, teacherEmail, term) {
+			continue
+		}
+		for i, reg := range rr.store.registers {
+			if reg.StudentID == email && reg.TeacherID == teacherEmail && reg.DeletedOn == nil && rr.store.termOf(reg) == term {
+				rr.store.registers[i].DeletedOn = &now
+			}
+		}
+		unregistered = append(unregistered, email)
+	}
+	return unregistered, nil
+}
+
+// CopyTerm creates the classes of a term in another term and enrolls the students of every class
+// to its copy, leaving the classes and registrations that exist in the other term as they are
+func (rr *RegisterRepository) CopyTerm(ctx context.Context, from, to string) error {
+	rr.store.mu.Lock()
+	defer rr.store.mu.Unlock()
+
+	now := time.Now()
+	copies := map[int]models.Class{}
+	for _, c := range rr.store.classes {
+		if c.Term != from {
+			continue
+		}
+		target := models.Class{TeacherID: c.TeacherID, Code: c.Code, Title: c.Title, Term: to}
+		found := false
+		for _, existing := range rr.store.classes {
+			if existing.TeacherID == target.TeacherID && existing.Code == target.Code && existing.Term == to {
+				target, found = existing, true
+				break
+			}
+		}
+		if !found {
+			target.ID = rr.store.nextID
+			target.CreatedOn = now
+			rr.store.nextID++
+			rr.store.classes = append(rr.store.classes, target)
+		}
+		copies[c.ID] = target
+	}
+
+	// enroll appends to the registrations, only go through the ones that existed before the copy
+	registers := append([]models.Register(nil), rr.store.registers...)
+	for _, reg := range registers {
+		if c, ok := copies[reg.ClassID]; ok && reg.DeletedOn == nil {
+			rr.store.enroll(reg.StudentID, c, now)
+		}
+	}
+	return nil
+}
+
+// ArchiveTerm soft deletes the registrations to the classes of the term and returns how many there were
+func (rr *RegisterRepository) ArchiveTerm(ctx context.Context, term string) (int, error) {
+	rr.store.mu.Lock()
+	defer rr.store.mu.Unlock()
+
+	now := time.Now()
+	archived := 0
+	for i, reg := range rr.store.registers {
+		if reg.DeletedOn == nil && rr.store.termOf(reg) == term {
+			rr.store.registers[i].DeletedOn = &now
+			archived++
+		}
+	}
+	return archived, nil
+}
@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"context"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"time"
+)
+
+type ScheduleRepository struct {
+	store *Store
+}
+
+// NewScheduleRepository an instance of the in-memory ScheduleRepository.
+func NewScheduleRepository(s *Store) *ScheduleRepository {
+	return &ScheduleRepository{store: s}
+}
+
+// Create queues a notification to be sent at its send date
+func (sc *ScheduleRepository) Create(ctx context.Context, input *models.ScheduledNotification) error {
+	sc.store.mu.Lock()
+	defer sc.store.mu.Unlock()
+
+	if _, ok := sc.store.teachers[input.TeacherID]; !ok {
+		return db.ErrReferenceNotFound{}
+	}
+
+	input.ID = sc.store.nextID
+	input.Status = models.SchedulePending
+	input.CreatedOn = time.Now()
+	sc.store.nextID++
+	sc.store.schedules = append(sc.store.schedules, *input)
+	return nil
+}
+
+// FindByID retrieves the scheduled notification of the teacher with the given id
+func (sc *ScheduleRepository) FindByID(ctx context.Context, teacherEmail string, id int) (models.ScheduledNotification, error) {
+	sc.store.mu.RLock()
+	defer sc.store.mu.RUnlock()
+
+	i := sc.store.findSchedule(id)
+	if i == -1 || sc.store.schedules[i].TeacherID != teacherEmail {
+		return models.ScheduledNotification{}, db.ErrObjectNotFound{}
+	}
+	return sc.store.schedules[i], nil
+}
+
+// FindPending retrieves the pending scheduled notifications of the teacher, the next one first
+func (sc *ScheduleRepository) FindPending(ctx context.Context, teacherEmail string) ([]models.ScheduledNotification, error) {
+	sc.store.mu.RLock()
+	defer sc.store.mu.RUnlock()
+
+	return sc.store.pendingSchedules(func(s models.ScheduledNotification) bool {
+		return s.TeacherID == teacherEmail
+	}), nil
+}
+
+// Due retrieves up to limit pending scheduled notifications whose send date passed at now, oldest first
+func (sc *ScheduleRepository) Due(ctx context.Context, now time.Time, limit int) ([]models.ScheduledNotification, error) {
+	sc.store.mu.RLock()
+	defer sc.store.mu.RUnlock()
+
+	schedules := sc.store.pendingSchedules(func(s models.ScheduledNotification) bool {
+		return !s.SendAt.After(now)
+	})
+	if len(schedules) > limit {
+		schedules = schedules[:limit]
+	}
+	return schedules, nil
+}
+
+// Claim checks that the scheduled notification is still pending. Units of work are serialized, so
+// no other one can claim it in the meantime.
+func (sc *ScheduleRepository) Claim(ctx context.Context, id int) error {
+	sc.store.mu.RLock()
+	defer sc.store.mu.RUnlock()
+
+	if i := sc.store.findSchedule(id); i == -1 || sc.store.schedules[i].Status != models.SchedulePending {
+		return db.ErrObjectNotFound{}
+	}
+	return nil
+}
+
+// MarkSent records the notification a pending scheduled notification was sent as
+func (sc *ScheduleRepository) MarkSent(ctx context.Context, id int, notificationID int) error {
+	return sc.finish(id, "", func(s *models.ScheduledNotification) {
+		s.Status = models.ScheduleSent
+		s.NotificationID = &notificationID
+		s.LastError = nil
+	})
+}
+
+// MarkFailed records why a pending scheduled notification could not be sent
+func (sc *ScheduleRepository) MarkFailed(ctx context.Context, id int, reason string) error {
+	return sc.finish(id, "", func(s *models.ScheduledNotification) {
+		s.Status = models.ScheduleFailed
+		s.LastError = &reason
+	})
+}
+
+// Cancel cancels a pending scheduled notification of the teacher
+func (sc *ScheduleRepository) Cancel(ctx context.Context, teacherEmail string, id int) error {
+	return sc.finish(id, teacherEmail, func(s *models.ScheduledNotification) {
+		s.Status = models.ScheduleCancelled
+	})
+}
+
+// finish applies fn to the pending scheduled notification, of the teacher when teacherEmail is given
+func (sc *ScheduleRepository) finish(id int, teacherEmail string, fn func(s *models.ScheduledNotification)) error {
+	sc.store.mu.Lock()
+	defer sc.store.mu.Unlock()
+
+	i := sc.store.findSchedule(id)
+	if i == -1 || sc.store.schedules[i].Status != models.SchedulePending ||
+		(teacherEmail != "" && sc.store.schedules[i].TeacherID != teacherEmail) {
+		return db.ErrObjectNotFound{}
+	}
+	now := time.Now()
+	s := &sc.store.schedules[i]
+	fn(s)
+	s.UpdatedOn = &now
+	return nil
+}
@@ -0,0 +1,191 @@
+package memory
+
+import (
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Store keeps students, their notification preferences, teachers, academic terms, classes, registrations, suspensions, notifications,
+// their outbox, templates and scheduled notifications in memory. It backs the in-memory repositories used
+// in unit tests and local demos where MySQL is not available.
+type Store struct {
+	mu            sync.RWMutex
+	txMu          sync.Mutex
+	students      map[string]models.Student
+	preferences   map[string]models.Preference
+	teachers      map[string]models.Teacher
+	terms         map[string]models.Term
+	classes       []models.Class
+	registers     []models.Register
+	suspensions   []models.Suspension
+	notifications []models.Notification
+	deliveries    []models.Delivery
+	templates     []models.Template
+	schedules     []models.ScheduledNotification
+	users         map[string]models.User
+	nextID        int
+}
+
+// New returns an empty in-memory Store
+func New() *Store {
+	return &Store{
+		students:    map[string]models.Student{},
+		preferences: map[string]models.Preference{},
+		teachers:    map[string]models.Teacher{},
+		terms:       map[string]models.Term{},
+		users:       map[string]models.User{},
+		nextID:      1,
+	}
+}
+
+// findRegister returns the index of the registration of the student to the class or -1
+func (s *Store) findRegister(studentEmail string, classID int) int {
+	for i, reg := range s.registers {
+		if reg.StudentID == studentEmail && reg.ClassID == classID {
+			return i
+		}
+	}
+	return -1
+}
+
+// registeredTo reports whether the student is registered to one of the classes of the teacher in the term
+func (s *Store) registeredTo(studentEmail, teacherEmail, term string) bool {
+	for _, reg := range s.registers {
+		if reg.StudentID == studentEmail && reg.TeacherID == teacherEmail && reg.DeletedOn == nil && s.termOf(reg) == term {
+			return true
+		}
+	}
+	return false
+}
+
+// findClass returns the index of the class or -1
+func (s *Store) findClass(id int) int {
+	for i, c := range s.classes {
+		if c.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// termOf returns the term of the class of the registration
+func (s *Store) termOf(reg models.Register) string {
+	if i := s.findClass(reg.ClassID); i > -1 {
+		return s.classes[i].Term
+	}
+	return ""
+}
+
+// defaultClass returns the default class of the teacher in the term, creating the class when the
+// teacher has none yet
+func (s *Store) defaultClass(teacherEmail, term string) models.Class {
+	for _, c := range s.classes {
+		if c.TeacherID == teacherEmail && c.Code == models.DefaultClassCode && c.Term == term {
+			return c
+		}
+	}
+	c := models.Class{ID: s.nextID, TeacherID: teacherEmail, Code: models.DefaultClassCode, Term: term, CreatedOn: time.Now()}
+	s.nextID++
+	s.classes = append(s.classes, c)
+	return c
+}
+
+// enroll registers the student to the class, restoring the registration removed by Unregister
+func (s *Store) enroll(studentEmail string, c models.Class, now time.Time) {
+	i := s.findRegister(studentEmail, c.ID)
+	if i > -1 && s.registers[i].DeletedOn == nil {
+		return
+	}
+	if i > -1 {
+		s.registers[i].DeletedOn = nil
+		s.registers[i].CreatedOn = now
+		return
+	}
+	s.registers = append(s.registers, models.Register{
+		ID:        s.nextID,
+		StudentID: studentEmail,
+		TeacherID: c.TeacherID,
+		ClassID:   c.ID,
+		CreatedOn: now,
+	})
+	s.nextID++
+}
+
+// suspended reports whether the student has a suspension in force at now, either global or,
+// when teacherEmail is given, from the teacher
+func (s *Store) suspended(email, teacherEmail string, now time.Time) bool {
+	for _, h := range s.suspensions {
+		if h.StudentID == email && h.Active(now) && h.AppliesTo(teacherEmail) {
+			return true
+		}
+	}
+	return false
+}
+
+// findDelivery returns the index of the delivery or -1
+func (s *Store) findDelivery(id int) int {
+	for i, d := range s.deliveries {
+		if d.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// notificationOf returns the teacher and message of the notification of the delivery
+func (s *Store) notificationOf(d models.Delivery) (teacherID, message string) {
+	for _, n := range s.notifications {
+		if n.ID != d.NotificationID {
+			continue
+		}
+		if m, ok := n.Messages[d.StudentID]; ok {
+			return n.TeacherID, m
+		}
+		return n.TeacherID, n.Message
+	}
+	return "", ""
+}
+
+// digestOf returns the digest frequency of the student
+func (s *Store) digestOf(studentEmail string) string {
+	if p, ok := s.preferences[studentEmail]; ok {
+		return p.Digest
+	}
+	return models.DigestImmediate
+}
+
+// findTemplate returns the index of the template of the teacher or -1
+func (s *Store) findTemplate(teacherEmail string, id int) int {
+	for i, t := range s.templates {
+		if t.ID == id && t.TeacherID == teacherEmail {
+			return i
+		}
+	}
+	return -1
+}
+
+// findSchedule returns the index of the scheduled notification or -1
+func (s *Store) findSchedule(id int) int {
+	for i, sched := range s.schedules {
+		if sched.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// pendingSchedules returns the pending scheduled notifications matching the filter, oldest send date first
+func (s *Store) pendingSchedules(filter func(s models.ScheduledNotification) bool) []models.ScheduledNotification {
+	schedules := []models.ScheduledNotification{}
+	for _, sched := range s.schedules {
+		if sched.Status == models.SchedulePending && filter(sched) {
+			schedules = append(schedules, sched)
+		}
+	}
+	sort.SliceStable(schedules, func(i, j int) bool {
+		return schedules[i].SendAt.Before(schedules[j].SendAt)
+	})
+	return schedules
+}
@@ -0,0 +1,248 @@
+package memory
+
+import (
+	"context"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"time"
+)
+
+type StudentRepository struct {
+	store *Store
+}
+
+// NewStudentRepository an instance of the in-memory StudentRepository.
+func NewStudentRepository(s *Store) *StudentRepository {
+	return &StudentRepository{store: s}
+}
+
+// Create sets the email and name in a new record
+func (sr *StudentRepository) Create(ctx context.Context, input *models.Student) error {
+	sr.store.mu.Lock()
+	defer sr.store.mu.Unlock()
+
+	if _, ok := sr.store.students[input.Email]; ok {
+		return db.ErrDuplicateObject{}
+	}
+	sr.store.students[input.Email] = models.Student{
+		Name:      input.Name,
+		Email:     input.Email,
+		CreatedOn: time.Now(),
+	}
+	return nil
+}
+
+// FindByEmail retrieves the student with the given email
+func (sr *StudentRepository) FindByEmail(ctx context.Context, email string) (models.Student, error) {
+	sr.store.mu.RLock()
+	defer sr.store.mu.RUnlock()
+
+	student, ok := sr.store.students[email]
+	if !ok || student.DeletedOn != nil {
+		return models.Student{}, db.ErrObjectNotFound{}
+	}
+	return student, nil
+}
+
+// FindByEmails retrieves the given students that exist
+func (sr *StudentRepository) FindByEmails(ctx context.Context, emails []string) ([]models.Student, error) {
+	sr.store.mu.RLock()
+	defer sr.store.mu.RUnlock()
+
+	seen := map[string]bool{}
+	students := []models.Student{}
+	for _, email := range emails {
+		if student, ok := sr.store.students[email]; ok && student.DeletedOn == nil && !seen[email] {
+			seen[email] = true
+			students = append(students, student)
+		}
+	}
+	return students, nil
+}
+
+// FindByEmailArr retrieves the emails with the given list of emails, leaving out
+// globally suspended students unless isSuspended is set
+func (sr *StudentRepository) FindByEmailArr(ctx context.Context, emails []string, isSuspended bool) ([]string, error) {
+	sr.store.mu.RLock()
+	defer sr.store.mu.RUnlock()
+
+	now := time.Now()
+	seen := map[string]bool{}
+	var studentEmails []string
+	for _, email := range emails {
+		if student, ok := sr.store.students[email]; !ok || student.DeletedOn != nil || seen[email] {
+			continue
+		}
+		if !isSuspended && sr.store.suspended(email, "", now) {
+			continue
+		}
+		seen[email] = true
+		studentEmails = append(studentEmails, email)
+	}
+	return studentEmails, nil
+}
+
+// FindNotSuspended retrieves the emails of the given students that are neither suspended
+// globally nor from the teacher
+func (sr *StudentRepository) FindNotSuspended(ctx context.Context, emails []string, teacherEmail string) ([]string, error) {
+	sr.store.mu.RLock()
+	defer sr.store.mu.RUnlock()
+
+	now := time.Now()
+	seen := map[string]bool{}
+	var studentEmails []string
+	for _, email := range emails {
+		if student, ok := sr.store.students[email]; !ok || student.DeletedOn != nil || seen[email] {
+			continue
+		}
+		if sr.store.suspended(email, teacherEmail, now) {
+			continue
+		}
+		seen[email] = true
+		studentEmails = append(studentEmails, email)
+	}
+	return studentEmails, nil
+}
+
+// Update changes the email and name of the student and moves its registrations, suspensions and notifications to the new email
+func (sr *StudentRepository) Update(ctx context.Context, email string, input *models.Student) error {
+	sr.store.mu.Lock()
+	defer sr.store.mu.Unlock()
+
+	student, ok := sr.store.students[email]
+	if !ok || student.DeletedOn != nil {
+		return nil
+	}
+	if _, ok := sr.store.students[input.Email]; ok && input.Email != email {
+		return db.ErrDuplicateObject{}
+	}
+
+	now := time.Now()
+	student.Email = input.Email
+	student.Name = input.Name
+	student.UpdatedOn = &now
+	delete(sr.store.students, email)
+	sr.store.students[input.Email] = student
+
+	for i := range sr.store.registers {
+		if sr.store.registers[i].StudentID == email {
+			sr.store.registers[i].StudentID = input.Email
+		}
+	}
+	for i := range sr.store.suspensions {
+		if sr.store.suspensions[i].StudentID == email {
+			sr.store.suspensions[i].StudentID = input.Email
+		}
+	}
+	for i, n := range sr.store.notifications {
+		if contains(n.Recipients, email) {
+			recipients := make([]string, 0, len(n.Recipients))
+			for _, r := range n.Recipients {
+				if r == email {
+					r = input.Email
+				}
+				recipients = append(recipients, r)
+			}
+			sr.store.notifications[i].Recipients = recipients
+		}
+		if m, ok := n.Messages[email]; ok {
+			messages := copyMessages(n.Messages)
+			delete(messages, email)
+			messages[input.Email] = m
+			sr.store.notifications[i].Messages = messages
+		}
+	}
+	for i := range sr.store.deliveries {
+		if sr.store.deliveries[i].StudentID == email {
+			sr.store.deliveries[i].StudentID = input.Email
+		}
+	}
+	if p, ok := sr.store.preferences[email]; ok {
+		p.StudentID = input.Email
+		delete(sr.store.preferences, email)
+		sr.store.preferences[input.Email] = p
+	}
+	return nil
+}
+
+// Delete soft deletes the student by setting its delete date
+func (sr *StudentRepository) Delete(ctx context.Context, email string) error {
+	sr.store.mu.Lock()
+	defer sr.store.mu.Unlock()
+
+	student, ok := sr.store.students[email]
+	if !ok || student.DeletedOn != nil {
+		return db.ErrObjectNotFound{}
+	}
+	now := time.Now()
+	student.DeletedOn = &now
+	sr.store.students[email] = student
+	return nil
+}
+
+// Restore clears the delete date of a soft deleted student
+func (sr *StudentRepository) Restore(ctx context.Context, email string) error {
+	sr.store.mu.Lock()
+	defer sr.store.mu.Unlock()
+
+	student, ok := sr.store.students[email]
+	if !ok || student.DeletedOn == nil {
+		return db.ErrObjectNotFound{}
+	}
+	now := time.Now()
+	student.DeletedOn = nil
+	student.UpdatedOn = &now
+	sr.store.students[email] = student
+	return nil
+}
+
+// List retrieves a page of the students matching the filter
+func (sr *StudentRepository) List(ctx context.Context, f models.ListFilter) ([]models.Student, error) {
+	sr.store.mu.RLock()
+	defer sr.store.mu.RUnlock()
+
+	matched := sr.filter(f, true)
+	people := make([]person, len(matched))
+	for i, s := range matched {
+		people[i] = person{email: s.Email, name: s.Name, createdOn: s.CreatedOn}
+	}
+
+	students := []models.Student{}
+	for _, i := range page(f, people) {
+		students = append(students, matched[i])
+	}
+	return students, nil
+}
+
+// Count counts every student matching the filter, ignoring its cursor and limit
+func (sr *StudentRepository) Count(ctx context.Context, f models.ListFilter) (int, error) {
+	sr.store.mu.RLock()
+	defer sr.store.mu.RUnlock()
+
+	return len(sr.filter(f, false)), nil
+}
+
+// filter returns the students matching f in no particular order
+func (sr *StudentRepository) filter(f models.ListFilter, withCursor bool) []models.Student {
+	now := time.Now()
+	registered := map[string]bool{}
+	for _, reg := range sr.store.registers {
+		if reg.TeacherID == f.Teacher && reg.DeletedOn == nil {
+			registered[reg.StudentID] = true
+		}
+	}
+
+	var students []models.Student
+	for _, s := range sr.store.students {
+		if s.DeletedOn != nil ||
+			(f.Teacher != "" && !registered[s.Email]) ||
+			(f.Suspended != nil && *f.Suspended != sr.store.suspended(s.Email, f.Teacher, now)) {
+			continue
+		}
+		if !(person{email: s.Email, name: s.Name, createdOn: s.CreatedOn}).matches(f, withCursor) {
+			continue
+		}
+		students = append(students, s)
+	}
+	return students
+}
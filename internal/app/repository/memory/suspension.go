@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"sort"
+	"time"
+)
+
+type SuspensionRepository struct {
+	store *Store
+}
+
+// NewSuspensionRepository an instance of the in-memory SuspensionRepository.
+func NewSuspensionRepository(s *Store) *SuspensionRepository {
+	return &SuspensionRepository{store: s}
+}
+
+// Create adds a suspension to the history of the student
+func (hr *SuspensionRepository) Create(ctx context.Context, input *models.Suspension) error {
+	hr.store.mu.Lock()
+	defer hr.store.mu.Unlock()
+
+	if _, ok := hr.store.students[input.StudentID]; !ok {
+		return db.ErrReferenceNotFound{}
+	}
+	if input.TeacherID != nil {
+		if _, ok := hr.store.teachers[*input.TeacherID]; !ok {
+			return db.ErrReferenceNotFound{}
+		}
+	}
+	input.ID = hr.store.nextID
+	input.SuspendedOn = time.Now()
+	hr.store.nextID++
+	hr.store.suspensions = append(hr.store.suspensions, *input)
+	return nil
+}
+
+// FindActive retrieves the suspension of the student in force globally or, when teacherEmail
+// is given, from the teacher
+func (hr *SuspensionRepository) FindActive(ctx context.Context, email, teacherEmail string) (models.Suspension, error) {
+	hr.store.mu.RLock()
+	defer hr.store.mu.RUnlock()
+
+	now := time.Now()
+	for i := len(hr.store.suspensions) - 1; i >= 0; i-- {
+		if s := hr.store.suspensions[i]; s.StudentID == email && s.Active(now) && s.AppliesTo(teacherEmail) {
+			return s, nil
+		}
+	}
+	return models.Suspension{}, db.ErrObjectNotFound{}
+}
+
+// Lift ends the suspensions of the student in force from the teacher or, when teacherEmail
+// is empty, every suspension in force. It returns the number of suspensions lifted.
+func (hr *SuspensionRepository) Lift(ctx context.Context, email, teacherEmail, liftedBy string) (int, error) {
+	hr.store.mu.Lock()
+	defer hr.store.mu.Unlock()
+
+	now := time.Now()
+	lifted := 0
+	for i, s := range hr.store.suspensions {
+		if s.StudentID != email || !s.Active(now) {
+			continue
+		}
+		if teacherEmail != "" && (s.TeacherID == nil || *s.TeacherID != teacherEmail) {
+			continue
+		}
+		hr.store.suspensions[i].LiftedOn = &now
+		hr.store.suspensions[i].LiftedBy = &liftedBy
+		lifted++
+	}
+	return lifted, nil
+}
+
+// FindByStudent retrieves the suspension history of the student, latest first
+func (hr *SuspensionRepository) FindByStudent(ctx context.Context, email string) ([]models.Suspension, error) {
+	hr.store.mu.RLock()
+	defer hr.store.mu.RUnlock()
+
+	suspensions := []models.Suspension{}
+	for _, s := range hr.store.suspensions {
+		if s.StudentID == email {
+			suspensions = append(suspensions, s)
+		}
+	}
+	sort.SliceStable(suspensions, func(i, j int) bool {
+		return suspensions[i].ID > suspensions[j].ID
+	})
+	return suspensions, nil
+}
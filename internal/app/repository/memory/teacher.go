@@ -0,0 +1,152 @@
+package memory
+
+import (
+	"context"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"time"
+)
+
+type TeacherRepository struct {
+	store *Store
+}
+
+// NewTeacherRepository an instance of the in-memory TeacherRepository.
+func NewTeacherRepository(s *Store) *TeacherRepository {
+	return &TeacherRepository{store: s}
+}
+
+// FindByEmail retrieves the teacher with the given email
+func (tr *TeacherRepository) FindByEmail(ctx context.Context, email string) (models.Teacher, error) {
+	tr.store.mu.RLock()
+	defer tr.store.mu.RUnlock()
+
+	teacher, ok := tr.store.teachers[email]
+	if !ok || teacher.DeletedOn != nil {
+		return models.Teacher{}, db.ErrObjectNotFound{}
+	}
+	return teacher, nil
+}
+
+// Create sets the teacher email and name in a new record
+func (tr *TeacherRepository) Create(ctx context.Context, input *models.Teacher) error {
+	tr.store.mu.Lock()
+	defer tr.store.mu.Unlock()
+
+	if _, ok := tr.store.teachers[input.Email]; ok {
+		return db.ErrDuplicateObject{}
+	}
+	tr.store.teachers[input.Email] = models.Teacher{
+		Name:      input.Name,
+		Email:     input.Email,
+		CreatedOn: time.Now(),
+	}
+	return nil
+}
+
+// Update changes the email and name of the teacher and moves its registrations, scoped suspensions and notifications to the new email
+func (tr *TeacherRepository) Update(ctx context.Context, email string, input *models.Teacher) error {
+	tr.store.mu.Lock()
+	defer tr.store.mu.Unlock()
+
+	teacher, ok := tr.store.teachers[email]
+	if !ok || teacher.DeletedOn != nil {
+		return nil
+	}
+	if _, ok := tr.store.teachers[input.Email]; ok && input.Email != email {
+		return db.ErrDuplicateObject{}
+	}
+
+	now := time.Now()
+	teacher.Email = input.Email
+	teacher.Name = input.Name
+	teacher.UpdatedOn = &now
+	delete(tr.store.teachers, email)
+	tr.store.teachers[input.Email] = teacher
+
+	for i := range tr.store.classes {
+		if tr.store.classes[i].TeacherID == email {
+			tr.store.classes[i].TeacherID = input.Email
+		}
+	}
+	for i := range tr.store.registers {
+		if tr.store.registers[i].TeacherID == email {
+			tr.store.registers[i].TeacherID = input.Email
+		}
+	}
+	for i, s := range tr.store.suspensions {
+		if s.TeacherID != nil && *s.TeacherID == email {
+			teacherID := input.Email
+			tr.store.suspensions[i].TeacherID = &teacherID
+		}
+	}
+	for i := range tr.store.notifications {
+		if tr.store.notifications[i].TeacherID == email {
+			tr.store.notifications[i].TeacherID = input.Email
+		}
+	}
+	for i := range tr.store.templates {
+		if tr.store.templates[i].TeacherID == email {
+			tr.store.templates[i].TeacherID = input.Email
+		}
+	}
+	for i := range tr.store.schedules {
+		if tr.store.schedules[i].TeacherID == email {
+			tr.store.schedules[i].TeacherID = input.Email
+		}
+	}
+	return nil
+}
+
+// Delete soft deletes the teacher by setting its delete date
+func (tr *TeacherRepository) Delete(ctx context.Context, email string) error {
+	tr.store.mu.Lock()
+	defer tr.store.mu.Unlock()
+
+	teacher, ok := tr.store.teachers[email]
+	if !ok || teacher.DeletedOn != nil {
+		return db.ErrObjectNotFound{}
+	}
+	now := time.Now()
+	teacher.DeletedOn = &now
+	tr.store.teachers[email] = teacher
+	return nil
+}
+
+// List retrieves a page of the teachers matching the filter
+func (tr *TeacherRepository) List(ctx context.Context, f models.ListFilter) ([]models.Teacher, error) {
+	tr.store.mu.RLock()
+	defer tr.store.mu.RUnlock()
+
+	matched := tr.filter(f, true)
+	people := make([]person, len(matched))
+	for i, t := range matched {
+		people[i] = person{email: t.Email, name: t.Name, createdOn: t.CreatedOn}
+	}
+
+	teachers := []models.Teacher{}
+	for _, i := range page(f, people) {
+		teachers = append(teachers, matched[i])
+	}
+	return teachers, nil
+}
+
+// Count counts every teacher matching the filter, ignoring its cursor and limit
+func (tr *TeacherRepository) Count(ctx context.Context, f models.ListFilter) (int, error) {
+	tr.store.mu.RLock()
+	defer tr.store.mu.RUnlock()
+
+	return len(tr.filter(f, false)), nil
+}
+
+// filter returns the teachers matching f in no particular order
+func (tr *TeacherRepository) filter(f models.ListFilter, withCursor bool) []models.Teacher {
+	var teachers []models.Teacher
+	for _, t := range tr.store.teachers {
+		if t.DeletedOn != nil || !(person{email: t.Email, name: t.Name, createdOn: t.CreatedOn}).matches(f, withCursor) {
+			continue
+		}
+		teachers = append(teachers, t)
+	}
+	return teachers
+}
@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"context"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"sort"
+	"time"
+)
+
+type TemplateRepository struct {
+	store *Store
+}
+
+// NewTemplateRepository an instance of the in-memory TemplateRepository.
+func NewTemplateRepository(s *Store) *TemplateRepository {
+	return &TemplateRepository{store: s}
+}
+
+// Create persists a new notification template of the teacher
+func (tm *TemplateRepository) Create(ctx context.Context, input *models.Template) error {
+	tm.store.mu.Lock()
+	defer tm.store.mu.Unlock()
+
+	if _, ok := tm.store.teachers[input.TeacherID]; !ok {
+		return db.ErrReferenceNotFound{}
+	}
+	for _, t := range tm.store.templates {
+		if t.TeacherID == input.TeacherID && t.Name == input.Name {
+			return db.ErrDuplicateObject{}
+		}
+	}
+
+	input.ID = tm.store.nextID
+	input.CreatedOn = time.Now()
+	tm.store.nextID++
+	tm.store.templates = append(tm.store.templates, *input)
+	return nil
+}
+
+// FindByID retrieves the template of the teacher with the given id
+func (tm *TemplateRepository) FindByID(ctx context.Context, teacherEmail string, id int) (models.Template, error) {
+	tm.store.mu.RLock()
+	defer tm.store.mu.RUnlock()
+
+	i := tm.store.findTemplate(teacherEmail, id)
+	if i == -1 {
+		return models.Template{}, db.ErrObjectNotFound{}
+	}
+	return tm.store.templates[i], nil
+}
+
+// FindByTeacher retrieves the templates of the teacher ordered by name
+func (tm *TemplateRepository) FindByTeacher(ctx context.Context, teacherEmail string) ([]models.Template, error) {
+	tm.store.mu.RLock()
+	defer tm.store.mu.RUnlock()
+
+	templates := []models.Template{}
+	for _, t := range tm.store.templates {
+		if t.TeacherID == teacherEmail {
+			templates = append(templates, t)
+		}
+	}
+	sort.SliceStable(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates, nil
+}
+
+// Update changes the name and body of the template
+func (tm *TemplateRepository) Update(ctx context.Context, input *models.Template) error {
+	tm.store.mu.Lock()
+	defer tm.store.mu.Unlock()
+
+	i := tm.store.findTemplate(input.TeacherID, input.ID)
+	if i == -1 {
+		return nil
+	}
+	for _, t := range tm.store.templates {
+		if t.TeacherID == input.TeacherID && t.Name == input.Name && t.ID != input.ID {
+			return db.ErrDuplicateObject{}
+		}
+	}
+
+	now := time.Now()
+	t := &tm.store.templates[i]
+	t.Name = input.Name
+	t.Body = input.Body
+	t.UpdatedOn = &now
+	return nil
+}
+
+// Delete removes the template of the teacher
+func (tm *TemplateRepository) Delete(ctx context.Context, teacherEmail string, id int) error {
+	tm.store.mu.Lock()
+	defer tm.store.mu.Unlock()
+
+	i := tm.store.findTemplate(teacherEmail, id)
+	if i == -1 {
+		return db.ErrObjectNotFound{}
+	}
+	tm.store.templates = append(tm.store.templates[:i], tm.store.templates[i+1:]...)
+	return nil
+}
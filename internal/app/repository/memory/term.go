@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"sort"
+	"time"
+)
+
+type TermRepository struct {
+	store *Store
+}
+
+// NewTermRepository an instance of the in-memory TermRepository.
+func NewTermRepository(s *Store) *TermRepository {
+	return &TermRepository{store: s}
+}
+
+// Create adds an academic term
+func (tt *TermRepository) Create(ctx context.Context, input *models.Term) error {
+	tt.store.mu.Lock()
+	defer tt.store.mu.Unlock()
+
+	if _, ok := tt.store.terms[input.Code]; ok {
+		return db.ErrDuplicateObject{}
+	}
+	input.CreatedOn = time.Now()
+	tt.store.terms[input.Code] = *input
+	return nil
+}
+
+// FindByCode retrieves the term with the given code
+func (tt *TermRepository) FindByCode(ctx context.Context, code string) (models.Term, error) {
+	tt.store.mu.RLock()
+	defer tt.store.mu.RUnlock()
+
+	t, ok := tt.store.terms[code]
+	if !ok {
+		return models.Term{}, db.ErrObjectNotFound{}
+	}
+	return t, nil
+}
+
+// FindAt retrieves the term whose dates include at, the latest starting one when terms overlap
+func (tt *TermRepository) FindAt(ctx context.Context, at time.Time) (models.Term, error) {
+	terms, _ := tt.FindAll(ctx)
+	for i := len(terms) - 1; i >= 0; i-- {
+		if terms[i].Includes(at) {
+			return terms[i], nil
+		}
+	}
+	return models.Term{}, db.ErrObjectNotFound{}
+}
+
+// FindAll retrieves every term ordered by start date
+func (tt *TermRepository) FindAll(ctx context.Context) ([]models.Term, error) {
+	tt.store.mu.RLock()
+	defer tt.store.mu.RUnlock()
+
+	terms := make([]models.Term, 0, len(tt.store.terms))
+	for _, t := range tt.store.terms {
+		terms = append(terms, t)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if !terms[i].StartsOn.Equal(terms[j].StartsOn) {
+			return terms[i].StartsOn.Before(terms[j].StartsOn)
+		}
+		return terms[i].Code < terms[j].Code
+	})
+	return terms, nil
+}
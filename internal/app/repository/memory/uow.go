@@ -0,0 +1,171 @@
+package memory
+
+import (
+	"context"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"time"
+)
+
+// txKey marks a context that is already running inside a UnitOfWork
+type txKey struct{}
+
+// UnitOfWork gives the in-memory repositories the all-or-nothing behavior of a
+// MySQL transaction. Units of work are serialized and the store is restored to
+// its previous state when one fails.
+type UnitOfWork struct {
+	store *Store
+}
+
+// NewUnitOfWork an instance of the in-memory UnitOfWork.
+func NewUnitOfWork(s *Store) *UnitOfWork {
+	return &UnitOfWork{store: s}
+}
+
+// Do runs fn and discards every change it made to the store if it returns an error or panics
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if ctx.Value(txKey{}) != nil {
+		return fn(ctx)
+	}
+
+	u.store.txMu.Lock()
+	defer u.store.txMu.Unlock()
+
+	snapshot := u.store.snapshot()
+	defer func() {
+		if p := recover(); p != nil {
+			u.store.restore(snapshot)
+			panic(p)
+		}
+		if err != nil {
+			u.store.restore(snapshot)
+		}
+	}()
+
+	return fn(context.WithValue(ctx, txKey{}, true))
+}
+
+// snapshot returns a deep copy of the store data, so that the changes a unit of work makes in
+// place to the pointers, slices and maps of the stored values are rolled back too
+func (s *Store) snapshot() *Store {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c := &Store{
+		students:      make(map[string]models.Student, len(s.students)),
+		preferences:   make(map[string]models.Preference, len(s.preferences)),
+		teachers:      make(map[string]models.Teacher, len(s.teachers)),
+		terms:         make(map[string]models.Term, len(s.terms)),
+		classes:       make([]models.Class, 0, len(s.classes)),
+		registers:     make([]models.Register, 0, len(s.registers)),
+		suspensions:   make([]models.Suspension, 0, len(s.suspensions)),
+		notifications: make([]models.Notification, 0, len(s.notifications)),
+		deliveries:    make([]models.Delivery, 0, len(s.deliveries)),
+		templates:     make([]models.Template, 0, len(s.templates)),
+		schedules:     make([]models.ScheduledNotification, 0, len(s.schedules)),
+		users:         make(map[string]models.User, len(s.users)),
+		nextID:        s.nextID,
+	}
+	for k, v := range s.students {
+		v.UpdatedOn, v.DeletedOn = copyTime(v.UpdatedOn), copyTime(v.DeletedOn)
+		c.students[k] = v
+	}
+	for k, v := range s.preferences {
+		v.QuietStart, v.QuietEnd = copyString(v.QuietStart), copyString(v.QuietEnd)
+		v.UpdatedOn = copyTime(v.UpdatedOn)
+		c.preferences[k] = v
+	}
+	for k, v := range s.teachers {
+		v.UpdatedOn, v.DeletedOn = copyTime(v.UpdatedOn), copyTime(v.DeletedOn)
+		c.teachers[k] = v
+	}
+	for k, v := range s.terms {
+		c.terms[k] = v
+	}
+	for _, v := range s.classes {
+		v.UpdatedOn = copyTime(v.UpdatedOn)
+		c.classes = append(c.classes, v)
+	}
+	for _, v := range s.registers {
+		v.DeletedOn, v.SuspendedOn = copyTime(v.DeletedOn), copyTime(v.SuspendedOn)
+		c.registers = append(c.registers, v)
+	}
+	for _, v := range s.suspensions {
+		v.TeacherID, v.LiftedBy = copyString(v.TeacherID), copyString(v.LiftedBy)
+		v.EndsOn, v.LiftedOn = copyTime(v.EndsOn), copyTime(v.LiftedOn)
+		c.suspensions = append(c.suspensions, v)
+	}
+	for _, v := range s.notifications {
+		v.Recipients = append([]string(nil), v.Recipients...)
+		v.Messages = copyMessages(v.Messages)
+		if v.DeliverAt != nil {
+			deliverAt := make(map[string]time.Time, len(v.DeliverAt))
+			for k, at := range v.DeliverAt {
+				deliverAt[k] = at
+			}
+			v.DeliverAt = deliverAt
+		}
+		c.notifications = append(c.notifications, v)
+	}
+	for _, v := range s.deliveries {
+		v.LastError, v.SentOn = copyString(v.LastError), copyTime(v.SentOn)
+		c.deliveries = append(c.deliveries, v)
+	}
+	for _, v := range s.templates {
+		v.UpdatedOn = copyTime(v.UpdatedOn)
+		c.templates = append(c.templates, v)
+	}
+	for _, v := range s.schedules {
+		v.TemplateID, v.ClassID, v.NotificationID = copyInt(v.TemplateID), copyInt(v.ClassID), copyInt(v.NotificationID)
+		v.Variables = copyMessages(v.Variables)
+		v.LastError, v.UpdatedOn = copyString(v.LastError), copyTime(v.UpdatedOn)
+		c.schedules = append(c.schedules, v)
+	}
+	for k, v := range s.users {
+		c.users[k] = v
+	}
+	return c
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+func copyString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	return &c
+}
+
+func copyInt(i *int) *int {
+	if i == nil {
+		return nil
+	}
+	c := *i
+	return &c
+}
+
+// restore replaces the store data with a snapshot
+func (s *Store) restore(c *Store) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.students = c.students
+	s.preferences = c.preferences
+	s.teachers = c.teachers
+	s.terms = c.terms
+	s.classes = c.classes
+	s.registers = c.registers
+	s.suspensions = c.suspensions
+	s.notifications = c.notifications
+	s.deliveries = c.deliveries
+	s.templates = c.templates
+	s.schedules = c.schedules
+	s.users = c.users
+	s.nextID = c.nextID
+}
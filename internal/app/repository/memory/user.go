@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"context"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"time"
+)
+
+type UserRepository struct {
+	store *Store
+}
+
+// NewUserRepository an instance of the in-memory UserRepository.
+func NewUserRepository(s *Store) *UserRepository {
+	return &UserRepository{store: s}
+}
+
+// Create sets the user credentials in a new record and assigns the generated id to input
+func (ur *UserRepository) Create(ctx context.Context, input *models.User) error {
+	ur.store.mu.Lock()
+	defer ur.store.mu.Unlock()
+
+	if _, ok := ur.store.users[input.Email]; ok {
+		return db.ErrDuplicateObject{}
+	}
+	input.ID = ur.store.nextID
+	input.CreateDate = time.Now()
+	ur.store.nextID++
+	ur.store.users[input.Email] = *input
+	return nil
+}
+
+// UpdateEmail moves the user with the given email to a new email
+func (ur *UserRepository) UpdateEmail(ctx context.Context, email, newEmail string) error {
+	ur.store.mu.Lock()
+	defer ur.store.mu.Unlock()
+
+	user, ok := ur.store.users[email]
+	if !ok {
+		return db.ErrObjectNotFound{}
+	}
+	if _, ok := ur.store.users[newEmail]; ok {
+		return db.ErrDuplicateObject{}
+	}
+	user.Email = newEmail
+	delete(ur.store.users, email)
+	ur.store.users[newEmail] = user
+	return nil
+}
+
+// FindByEmail retrieves the user with the given email
+func (ur *UserRepository) FindByEmail(ctx context.Context, email string) (models.User, error) {
+	ur.store.mu.RLock()
+	defer ur.store.mu.RUnlock()
+
+	user, ok := ur.store.users[email]
+	if !ok {
+		return models.User{}, db.ErrObjectNotFound{}
+	}
+	return user, nil
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/opentracing/opentracing-go"
+	log "github.com/sirupsen/logrus"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type NotificationRepository struct {
+	DB *sql.DB
+}
+
+// NewNotificationRepository an instance of the NotificationRepository.
+func NewNotificationRepository(db *db.MySQL) *NotificationRepository {
+	return &NotificationRepository{DB: db.DBClient}
+}
+
+// Create persists the notification with its recipients and queues a delivery to every recipient
+// in the notification outbox
+func (nr *NotificationRepository) Create(ctx context.Context, input *models.Notification) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "NotificationRepository.Create")
+	defer span.Finish()
+
+	conn := db.Conn(ctx, nr.DB)
+	res, err := conn.ExecContext(ctx, createNotificationQuery, input.TeacherID, input.Message)
+	if err == nil {
+		var id int64
+		id, err = res.LastInsertId()
+		input.ID = int(id)
+		input.CreatedOn = time.Now()
+	}
+
+	// each recipient row takes three placeholders
+	for _, emailsChunk := range chunk(input.Recipients, maxPlaceholders/3) {
+		if err != nil {
+			break
+		}
+		var recipientArgs, outboxArgs []interface{}
+		for _, email := range emailsChunk {
+			var message *string
+			if m, ok := input.Messages[email]; ok {
+				message = &m
+			}
+			var deliverAt *time.Time
+			if t, ok := input.DeliverAt[email]; ok {
+				deliverAt = &t
+			}
+			recipientArgs = append(recipientArgs, input.ID, email, message)
+			outboxArgs = append(outboxArgs, input.ID, email, deliverAt)
+		}
+		_, err = conn.ExecContext(ctx, fmt.Sprintf(createNotificationRecipientsQuery, placeholders(len(emailsChunk), "(?, ?, ?)")), recipientArgs...)
+		if err == nil {
+			_, err = conn.ExecContext(ctx, fmt.Sprintf(createOutboxQuery, placeholders(len(emailsChunk), "(?, ?, COALESCE(?, CURRENT_TIMESTAMP))")), outboxArgs...)
+		}
+	}
+	if err != nil {
+		log.Println("[Notification][Create][Repository] Problem to querying to db, err: ", err.Error())
+		return db.HandleError(err)
+	}
+
+	return nil
+}
+
+// List retrieves the notifications matching the filter with their recipients, latest first.
+// When filtering on a student the message is the one the student received.
+func (nr *NotificationRepository) List(ctx context.Context, f models.NotificationFilter) ([]models.Notification, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "NotificationRepository.List")
+	defer span.Finish()
+
+	conn := db.Conn(ctx, nr.DB)
+	query, args := notificationListQuery(f)
+	notifications := []models.Notification{}
+	index := map[int]int{}
+	err := queryRows(ctx, conn, query, args, func(rows *sql.Rows) error {
+		var n models.Notification
+		if err := rows.Scan(&n.ID, &n.TeacherID, &n.Message, &n.CreatedOn); err != nil {
+			return err
+		}
+		n.Recipients = []string{}
+		index[n.ID] = len(notifications)
+		notifications = append(notifications, n)
+		return nil
+	})
+
+	ids := make([]string, 0, len(notifications))
+	for _, n := range notifications {
+		ids = append(ids, strconv.Itoa(n.ID))
+	}
+	for _, idsChunk := range chunk(ids, maxPlaceholders) {
+		if err != nil {
+			break
+		}
+		query, args := inClause(getNotificationRecipientsQuery, idsChunk)
+		err = queryRows(ctx, conn, query, args, func(rows *sql.Rows) error {
+			var (
+				id      int
+				email   string
+				message sql.NullString
+			)
+			if err := rows.Scan(&id, &email, &message); err != nil {
+				return err
+			}
+			n := &notifications[index[id]]
+			n.Recipients = append(n.Recipients, email)
+			if message.Valid {
+				if n.Messages == nil {
+					n.Messages = map[string]string{}
+				}
+				n.Messages[email] = message.String
+			}
+			return nil
+		})
+	}
+	// a student sees the message they received
+	for i, n := range notifications {
+		if m, ok := n.Messages[f.Student]; ok && f.Student != "" {
+			notifications[i].Message = m
+		}
+	}
+	if err != nil {
+		log.Println("[Notification][List][Repository] Problem to querying to db, err: ", err.Error())
+		return nil, db.HandleError(err)
+	}
+
+	return notifications, nil
+}
+
+// notificationListQuery builds the query selecting a page of the notifications matching the filter
+func notificationListQuery(f models.NotificationFilter) (string, []interface{}) {
+	var (
+		conds []string
+		args  []interface{}
+	)
+
+	if f.Teacher != "" {
+		conds = append(conds, "notification.teacher_id = ?")
+		args = append(args, f.Teacher)
+	}
+	if f.Student != "" {
+		conds = append(conds, sentToStudentCond)
+		args = append(args, f.Student)
+	}
+	if f.Before > 0 {
+		conds = append(conds, "notification.id < ?")
+		args = append(args, f.Before)
+	}
+
+	query := "SELECT " + notificationColumns + " FROM notification"
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY notification.id DESC"
+	if f.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, f.Limit)
+	}
+	return query, args
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/opentracing/opentracing-go"
+	log "github.com/sirupsen/logrus"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"time"
+)
+
+type OutboxRepository struct {
+	DB *sql.DB
+}
+
+// NewOutboxRepository an instance of the OutboxRepository.
+func NewOutboxRepository(db *db.MySQL) *OutboxRepository {
+	return &OutboxRepository{DB: db.DBClient}
+}
+
+// Due retrieves up to limit pending deliveries whose next attempt is due at now, oldest first
+func (ob *OutboxRepository) Due(ctx context.Context, now time.Time, limit int) ([]models.Delivery, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "OutboxRepository.Due")
+	defer span.Finish()
+
+	deliveries, err := queryDeliveries(ctx, db.Conn(ctx, ob.DB), getDueDeliveriesQuery, now, limit)
+	if err != nil {
+		log.Println("[Outbox][Due][Repository] Problem to querying to db, err: ", err.Error())
+		return nil, db.HandleError(err)
+	}
+
+	return deliveries, nil
+}
+
+// DueByStudent retrieves every pending delivery to the student whose next attempt is due at now, oldest first
+func (ob *OutboxRepository) DueByStudent(ctx context.Context, studentEmail string, now time.Time) ([]models.Delivery, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "OutboxRepository.DueByStudent")
+	defer span.Finish()
+
+	deliveries, err := queryDeliveries(ctx, db.Conn(ctx, ob.DB), getStudentDueDeliveriesQuery, now, studentEmail)
+	if err != nil {
+		log.Println("[Outbox][DueByStudent][Repository] Problem to querying to db, err: ", err.Error())
+		return nil, db.HandleError(err)
+	}
+
+	return deliveries, nil
+}
+
+// FindByNotification retrieves the deliveries of the notification
+func (ob *OutboxRepository) FindByNotification(ctx context.Context, notificationID int) ([]models.Delivery, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "OutboxRepository.FindByNotification")
+	defer span.Finish()
+
+	deliveries, err := queryDeliveries(ctx, db.Conn(ctx, ob.DB), getDeliveriesQuery, notificationID)
+	if err != nil {
+		log.Println("[Outbox][FindByNotification][Repository] Problem to querying to db, err: ", err.Error())
+		return nil, db.HandleError(err)
+	}
+
+	return deliveries, nil
+}
+
+// Claim leases the pending delivery due at now until the given time, when it is due again if it was
+// neither sent nor failed. It returns db.ErrObjectNotFound if the delivery is no longer due, e.g.
+// because another dispatcher claimed it.
+func (ob *OutboxRepository) Claim(ctx context.Context, id int, now, until time.Time) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "OutboxRepository.Claim")
+	defer span.Finish()
+
+	err := execOne(ctx, db.Conn(ctx, ob.DB), claimDeliveryQuery, until, id, now)
+	if err != nil {
+		log.Println("[Outbox][Claim][Repository] Problem to querying to db, err: ", err.Error())
+		return db.HandleError(err)
+	}
+
+	return nil
+}
+
+// MarkSent records that the delivery was sent
+func (ob *OutboxRepository) MarkSent(ctx context.Context, id int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "OutboxRepository.MarkSent")
+	defer span.Finish()
+
+	_, err := db.Conn(ctx, ob.DB).ExecContext(ctx, markDeliverySentQuery, id)
+	if err != nil {
+		log.Println("[Outbox][MarkSent][Repository] Problem to querying to db, err: ", err.Error())
+		return db.HandleError(err)
+	}
+
+	return nil
+}
+
+// MarkFailed records a failed attempt of the delivery. It is retried at next, or given up when next is nil.
+func (ob *OutboxRepository) MarkFailed(ctx context.Context, id int, reason string, next *time.Time) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "OutboxRepository.MarkFailed")
+	defer span.Finish()
+
+	status := models.DeliveryPending
+	if next == nil {
+		status = models.DeliveryFailed
+	}
+	_, err := db.Conn(ctx, ob.DB).ExecContext(ctx, markDeliveryFailedQuery, status, truncate(reason, 255), next, id)
+	if err != nil {
+		log.Println("[Outbox][MarkFailed][Repository] Problem to querying to db, err: ", err.Error())
+		return db.HandleError(err)
+	}
+
+	return nil
+}
+
+// queryDeliveries runs a query selecting the columns of getDeliveriesQuery
+func queryDeliveries(ctx context.Context, conn db.Querier, query string, args ...interface{}) ([]models.Delivery, error) {
+	deliveries := []models.Delivery{}
+	err := queryRows(ctx, conn, query, args, func(rows *sql.Rows) error {
+		var (
+			d         models.Delivery
+			lastError sql.NullString
+		)
+		err := rows.Scan(
+			&d.ID,
+			&d.NotificationID,
+			&d.StudentID,
+			&d.Status,
+			&d.Attempts,
+			&d.NextAttemptOn,
+			&lastError,
+			&d.SentOn,
+			&d.TeacherID,
+			&d.Message,
+			&d.Digest,
+		)
+		if lastError.Valid {
+			d.LastError = &lastError.String
+		}
+		deliveries = append(deliveries, d)
+		return err
+	})
+	return deliveries, err
+}
+
+// truncate shortens s to at most n bytes without splitting a UTF-8 sequence
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && s[n]&0xC0 == 0x80 {
+		n--
+	}
+	return s[:n]
+}
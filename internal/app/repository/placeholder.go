@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"strings"
+)
+
+// maxPlaceholders is the number of bound parameters MySQL accepts in a single prepared statement
+const maxPlaceholders = 65535
+
+// inClause formats query, which must contain a single %s verb, with a "?, ?, ..." list
+// holding one placeholder per value and returns the values as the matching bound args.
+// The values never end up in the SQL text, so they don't need to be escaped.
+func inClause(query string, values []string) (string, []interface{}) {
+	args := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		args = append(args, v)
+	}
+	return fmt.Sprintf(query, placeholders(len(values), "?")), args
+}
+
+// placeholders returns n copies of group separated by commas, e.g. "(?, ?), (?, ?)"
+func placeholders(n int, group string) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.TrimSuffix(strings.Repeat(group+", ", n), ", ")
+}
+
+// chunk splits values in slices of at most size elements so every statement built
+// from them stays within maxPlaceholders
+func chunk(values []string, size int) [][]string {
+	var chunks [][]string
+	for size < len(values) {
+		chunks = append(chunks, values[:size:size])
+		values = values[size:]
+	}
+	if len(values) > 0 {
+		chunks = append(chunks, values)
+	}
+	return chunks
+}
+
+// queryStrings runs query and returns the single string column of every row
+func queryStrings(ctx context.Context, conn db.Querier, query string, args []interface{}) ([]string, error) {
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			return
+		}
+	}(rows)
+
+	var values []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return values, err
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
+
+// execOne runs query and returns sql.ErrNoRows when it changed no row
+func execOne(ctx context.Context, conn db.Querier, query string, args ...interface{}) error {
+	res, err := conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
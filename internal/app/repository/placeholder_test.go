@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"strings"
+)
+
+var _ = Describe("Placeholder", func() {
+	const hostile = "x' OR '1'='1"
+
+	It("inClause should bind every value instead of formatting it into the query", func() {
+		query, args := inClause("SELECT email FROM student WHERE email IN (%s)", []string{"a@gmail.com", hostile})
+		Expect(query).Should(Equal("SELECT email FROM student WHERE email IN (?, ?)"))
+		Expect(args).Should(Equal([]interface{}{"a@gmail.com", hostile}))
+	})
+	It("placeholders should repeat the group", func() {
+		Expect(placeholders(0, "?")).Should(BeEmpty())
+		Expect(placeholders(2, "(?, ?)")).Should(Equal("(?, ?), (?, ?)"))
+	})
+	It("chunk should split values above the limit", func() {
+		Expect(chunk(nil, 2)).Should(BeEmpty())
+		Expect(chunk([]string{"a", "b", "c", "d", "e"}, 2)).Should(Equal([][]string{{"a", "b"}, {"c", "d"}, {"e"}}))
+	})
+
+	Describe("repositories", func() {
+		var (
+			ctx context.Context
+			rec *recorder
+			sr  *StudentRepository
+			rr  *RegisterRepository
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			rec = &recorder{}
+			conn := newRecorderDB(rec)
+			sr = &StudentRepository{DB: conn}
+			rr = &RegisterRepository{DB: conn}
+		})
+
+		expectBound := func() {
+			stmts := rec.recorded()
+			Expect(stmts).ShouldNot(BeEmpty())
+			for _, stmt := range stmts {
+				Expect(stmt.query).ShouldNot(ContainSubstring("OR '1'='1"))
+				Expect(stmt.args).Should(ContainElement(hostile))
+			}
+		}
+
+		It("StudentRepository.FindByEmailArr should not inject hostile emails", func() {
+			_, err := sr.FindByEmailArr(ctx, []string{"a@gmail.com", hostile}, false)
+			Expect(err).Should(BeNil())
+			expectBound()
+		})
+		It("RegisterRepository.FindByEmailArr should not inject hostile emails", func() {
+			_, err := rr.FindByEmailArr(ctx, []string{hostile}, hostile)
+			Expect(err).Should(BeNil())
+			expectBound()
+		})
+		It("RegisterRepository.Register should not inject hostile emails", func() {
+			Expect(rr.Register(ctx, hostile, hostile, []string{hostile})).Should(Succeed())
+			expectBound()
+		})
+		It("RegisterRepository.Unregister should not inject hostile emails", func() {
+			_, err := rr.Unregister(ctx, hostile, hostile, []string{hostile})
+			Expect(err).Should(BeNil())
+			expectBound()
+		})
+		It("RegisterRepository.CopyTerm and ArchiveTerm should not inject hostile terms", func() {
+			Expect(rr.CopyTerm(ctx, hostile, hostile)).Should(Succeed())
+			_, err := rr.ArchiveTerm(ctx, hostile)
+			Expect(err).Should(BeNil())
+			expectBound()
+		})
+		It("FindByEmailArr should split lists above the placeholder limit", func() {
+			emails := make([]string, maxPlaceholders+1)
+			for i := range emails {
+				emails[i] = hostile
+			}
+			_, err := rr.FindByEmailArr(ctx, emails, "")
+			Expect(err).Should(BeNil())
+
+			stmts := rec.recorded()
+			Expect(stmts).Should(HaveLen(2))
+			// the term takes one placeholder of every statement
+			Expect(stmts[0].args).Should(HaveLen(maxPlaceholders))
+			Expect(stmts[1].args).Should(HaveLen(3))
+			Expect(strings.Count(stmts[1].query, "?")).Should(Equal(3))
+		})
+		It("FindByEmailArr should not query for an empty list", func() {
+			res, err := sr.FindByEmailArr(ctx, nil, false)
+			Expect(err).Should(BeNil())
+			Expect(res).Should(BeEmpty())
+			Expect(rec.recorded()).Should(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/opentracing/opentracing-go"
+	log "github.com/sirupsen/logrus"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"time"
+)
+
+type PreferenceRepository struct {
+	DB *sql.DB
+}
+
+// NewPreferenceRepository an instance of the PreferenceRepository.
+func NewPreferenceRepository(db *db.MySQL) *PreferenceRepository {
+	return &PreferenceRepository{DB: db.DBClient}
+}
+
+// FindByStudent retrieves the notification preferences of the student
+func (pr *PreferenceRepository) FindByStudent(ctx context.Context, studentEmail string) (models.Preference, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "PreferenceRepository.FindByStudent")
+	defer span.Finish()
+
+	p, err := scanPreference(db.Conn(ctx, pr.DB).QueryRowContext(ctx, getPreferenceQuery, studentEmail).Scan)
+	if err != nil {
+		log.Println("[Preference][FindByStudent][Repository] Problem to querying to db, err: ", err.Error())
+		return p, db.HandleError(err)
+	}
+
+	return p, nil
+}
+
+// FindByStudents retrieves the notification preferences of the given students that set any
+func (pr *PreferenceRepository) FindByStudents(ctx context.Context, studentEmails []string) ([]models.Preference, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "PreferenceRepository.FindByStudents")
+	defer span.Finish()
+
+	preferences := []models.Preference{}
+	for _, emailsChunk := range chunk(studentEmails, maxPlaceholders) {
+		query, args := inClause(getPreferencesQuery, emailsChunk)
+		err := queryRows(ctx, db.Conn(ctx, pr.DB), query, args, func(rows *sql.Rows) error {
+			p, err := scanPreference(rows.Scan)
+			preferences = append(preferences, p)
+			return err
+		})
+		if err != nil {
+			log.Println("[Preference][FindByStudents][Repository] Problem to querying to db, err: ", err.Error())
+			return nil, db.HandleError(err)
+		}
+	}
+
+	return preferences, nil
+}
+
+// Save creates or replaces the notification preferences of the student
+func (pr *PreferenceRepository) Save(ctx context.Context, input *models.Preference) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "PreferenceRepository.Save")
+	defer span.Finish()
+
+	_, err := db.Conn(ctx, pr.DB).ExecContext(ctx, savePreferenceQuery,
+		input.StudentID,
+		input.Unsubscribed,
+		input.BroadcastOptOut,
+		input.Digest,
+		input.QuietStart,
+		input.QuietEnd,
+		input.Timezone,
+	)
+	if err != nil {
+		log.Println("[Preference][Save][Repository] Problem to querying to db, err: ", err.Error())
+		return db.HandleError(err)
+	}
+
+	now := time.Now()
+	input.UpdatedOn = &now
+	return nil
+}
+
+// scanPreference reads the preferenceColumns of a row
+func scanPreference(scan func(dest ...interface{}) error) (models.Preference, error) {
+	var p models.Preference
+	err := scan(
+		&p.StudentID,
+		&p.Unsubscribed,
+		&p.BroadcastOptOut,
+		&p.Digest,
+		&p.QuietStart,
+		&p.QuietEnd,
+		&p.Timezone,
+		&p.UpdatedOn,
+	)
+	return p, err
+}
@@ -21,6 +21,50 @@ const (
 		student
 	WHERE
 		email=?
+		AND deleted_on IS NULL
+`
+	getStudentsQuery = `
+	SELECT
+		email,
+		name,
+		created_on,
+		deleted_on,
+		updated_on
+	FROM
+		student
+	WHERE
+		email IN (%s)
+		AND deleted_on IS NULL
+`
+	updateStudentQuery = `
+	UPDATE
+		student
+	SET
+		email = ?,
+		name = ?,
+		updated_on = NOW()
+	WHERE
+		email = ?
+		AND deleted_on IS NULL
+`
+	deleteStudentQuery = `
+	UPDATE
+		student
+	SET
+		deleted_on = NOW()
+	WHERE
+		email = ?
+		AND deleted_on IS NULL
+`
+	restoreStudentQuery = `
+	UPDATE
+		student
+	SET
+		deleted_on = NULL,
+		updated_on = NOW()
+	WHERE
+		email = ?
+		AND deleted_on IS NOT NULL
 `
 	getTeacherQuery = `
 	SELECT
@@ -33,6 +77,27 @@ const (
 		teacher
 	WHERE
 		email=?
+		AND deleted_on IS NULL
+`
+	updateTeacherQuery = `
+	UPDATE
+		teacher
+	SET
+		email = ?,
+		name = ?,
+		updated_on = NOW()
+	WHERE
+		email = ?
+		AND deleted_on IS NULL
+`
+	deleteTeacherQuery = `
+	UPDATE
+		teacher
+	SET
+		deleted_on = NOW()
+	WHERE
+		email = ?
+		AND deleted_on IS NULL
 `
 	createTeacher = `
 	INSERT INTO teacher (
@@ -62,12 +127,631 @@ const (
 		ORDER BY
 			title
 `
-	updateRegisterQuery = `
+	// upsertRegistersQuery skips the registrations that exist, restoring them if they were unregistered meanwhile.
+	// Each row is a defaultClassRegisterRow or a classRegisterRow.
+	upsertRegistersQuery = `
+	INSERT INTO register (
+		student_id,
+		teacher_id,
+		class_id
+	) VALUES %s
+	ON DUPLICATE KEY UPDATE
+		created_on = IF(deleted_on IS NULL, created_on, NOW()),
+		deleted_on = NULL
+`
+	// defaultClassRegisterRow registers a student to the default class of a teacher in a term, it takes the student,
+	// twice the teacher and the term
+	defaultClassRegisterRow = "(?, ?, (SELECT id FROM class WHERE teacher_id = ? AND code = 'default' AND term = ?))"
+	// classRegisterRow registers a student to a class, it takes the student and twice the class
+	classRegisterRow        = "(?, (SELECT teacher_id FROM class WHERE id = ?), ?)"
+	ensureDefaultClassQuery = `
+	INSERT INTO class (
+		teacher_id,
+		code,
+		term
+	) VALUES (
+		?,
+		'default',
+		?
+	) ON DUPLICATE KEY UPDATE
+		id = id
+`
+	getStudentsByEmailQuery = `
+	SELECT
+		email
+	FROM
+		student
+	WHERE
+		deleted_on IS NULL
+		AND email IN (%s)
+`
+	notSuspendedStudentCond = `
+		AND NOT EXISTS (
+			SELECT 1 FROM suspension_history WHERE suspension_history.student_id = student.email AND ` + globalSuspensionCond + `
+		)
+`
+	getNotSuspendedStudentsQuery = `
+	SELECT
+		email
+	FROM
+		student
+	WHERE
+		deleted_on IS NULL
+		AND email IN (%s)
+		AND NOT EXISTS (
+			SELECT 1 FROM suspension_history WHERE suspension_history.student_id = student.email AND ` + activeSuspensionCond + `
+				AND (suspension_history.teacher_id IS NULL OR suspension_history.teacher_id = ?)
+		)
+`
+	// termRegisterCond restricts registrations to the classes of a term, it takes the term
+	termRegisterCond          = "class_id IN (SELECT id FROM class WHERE term = ?)"
+	getStudentsByTeacherQuery = `
+	SELECT
+		student_id
+	FROM
+		register
+	WHERE
+		deleted_on IS NULL
+		AND teacher_id IN (%s)
+		AND ` + termRegisterCond + `
+		AND NOT ` + suspendedRegisterCond + `
+		AND student_id IN (SELECT email FROM student WHERE deleted_on IS NULL)
+		AND teacher_id IN (SELECT email FROM teacher WHERE deleted_on IS NULL)
+	GROUP BY
+		student_id
+`
+	getStudentsByClassQuery = `
+	SELECT
+		student_id
+	FROM
+		register
+	WHERE
+		deleted_on IS NULL
+		AND class_id IN (%s)
+		AND NOT ` + suspendedRegisterCond + `
+		AND student_id IN (SELECT email FROM student WHERE deleted_on IS NULL)
+		AND teacher_id IN (SELECT email FROM teacher WHERE deleted_on IS NULL)
+	GROUP BY
+		student_id
+`
+	// countStudentsByTeacherQuery counts every student once, whatever the number of classes of the teacher they are in
+	countStudentsByTeacherQuery = `
+	SELECT
+		COALESCE(SUM(NOT ` + suspendedRegisterCond + `), 0),
+		COALESCE(SUM(` + suspendedRegisterCond + `), 0)
+	FROM
+		(SELECT DISTINCT student_id, teacher_id FROM register WHERE teacher_id = ? AND ` + termRegisterCond + ` AND deleted_on IS NULL) AS register
+		JOIN student ON student.email = register.student_id AND student.deleted_on IS NULL
+`
+	getRegisteredStudentsQuery = `
+	SELECT
+		student_id
+	FROM
+		register
+	WHERE
+		teacher_id = ?
+		AND ` + termRegisterCond + `
+		AND deleted_on IS NULL
+		AND student_id IN (%s)
+	FOR UPDATE
+`
+	unregisterQuery = `
 	UPDATE
 		register
 	SET
-		suspended_on = NOW()
+		deleted_on = NOW()
+	WHERE
+		teacher_id = ?
+		AND ` + termRegisterCond + `
+		AND deleted_on IS NULL
+		AND student_id IN (%s)
+`
+	getEnrolledStudentsQuery = `
+	SELECT
+		student_id
+	FROM
+		register
+	WHERE
+		class_id = ?
+		AND deleted_on IS NULL
+		AND student_id IN (%s)
+	FOR UPDATE
+`
+	unenrollQuery = `
+	UPDATE
+		register
+	SET
+		deleted_on = NOW()
+	WHERE
+		class_id = ?
+		AND deleted_on IS NULL
+		AND student_id IN (%s)
+`
+	// activeSuspensionCond matches the suspensions that are neither lifted nor over
+	activeSuspensionCond = "suspension_history.lifted_on IS NULL AND (suspension_history.ends_on IS NULL OR suspension_history.ends_on > NOW())"
+	// globalSuspensionCond matches the active suspensions that are not scoped to a teacher
+	globalSuspensionCond = activeSuspensionCond + " AND suspension_history.teacher_id IS NULL"
+	// suspendedRegisterCond matches the registrations whose student is suspended globally or from the teacher
+	suspendedRegisterCond = `EXISTS (
+			SELECT 1 FROM suspension_history WHERE suspension_history.student_id = register.student_id AND ` + activeSuspensionCond + `
+				AND (suspension_history.teacher_id IS NULL OR suspension_history.teacher_id = register.teacher_id)
+		)`
+	createSuspensionQuery = `
+	INSERT INTO suspension_history (
+		student_id,
+		teacher_id,
+		reason,
+		suspended_by,
+		ends_on
+	) VALUES (
+		?,
+		?,
+		?,
+		?,
+		?
+	)
+`
+	getActiveSuspensionQuery = `
+	SELECT
+		id,
+		student_id,
+		teacher_id,
+		reason,
+		suspended_by,
+		suspended_on,
+		ends_on,
+		lifted_on,
+		lifted_by
+	FROM
+		suspension_history
+	WHERE
+		student_id = ?
+		AND ` + activeSuspensionCond + `
+		AND (teacher_id IS NULL OR teacher_id = ?)
+	ORDER BY
+		suspended_on DESC,
+		id DESC
+	LIMIT 1
+`
+	liftSuspensionQuery = `
+	UPDATE
+		suspension_history
+	SET
+		lifted_on = NOW(),
+		lifted_by = ?
+	WHERE
+		student_id = ?
+		AND ` + activeSuspensionCond + `
+		AND (? = '' OR teacher_id = ?)
+`
+	getSuspensionsQuery = `
+	SELECT
+		id,
+		student_id,
+		teacher_id,
+		reason,
+		suspended_by,
+		suspended_on,
+		ends_on,
+		lifted_on,
+		lifted_by
+	FROM
+		suspension_history
 	WHERE
 		student_id = ?
+	ORDER BY
+		suspended_on DESC,
+		id DESC
+`
+	createUser = `
+	INSERT INTO users (
+		email,
+		username,
+		password_hash,
+		fullname,
+		role
+	) VALUES (
+		?,
+		?,
+		?,
+		?,
+		?
+	)
+`
+	updateUserEmailQuery = "UPDATE users SET email = ? WHERE email = ?"
+	getUserQuery         = `
+	SELECT
+		id,
+		email,
+		username,
+		password_hash,
+		fullname,
+		role,
+		created_on
+	FROM
+		users
+	WHERE
+		email=?
+`
+	createNotificationQuery = `
+	INSERT INTO notification (
+		teacher_id,
+		message
+	) VALUES (
+		?,
+		?
+	)
+`
+	createNotificationRecipientsQuery = "INSERT INTO notification_recipient(notification_id, student_id, message) VALUES %s"
+	getNotificationRecipientsQuery    = `
+	SELECT
+		notification_id,
+		student_id,
+		message
+	FROM
+		notification_recipient
+	WHERE
+		notification_id IN (%s)
+	ORDER BY
+		student_id
+`
+	// notificationColumns are the columns of the notification table in the order they are scanned
+	notificationColumns   = "notification.id, notification.teacher_id, notification.message, notification.created_on"
+	sentToStudentCond     = "EXISTS (SELECT 1 FROM notification_recipient WHERE notification_recipient.notification_id = notification.id AND notification_recipient.student_id = ?)"
+	createOutboxQuery     = "INSERT INTO notification_outbox(notification_id, student_id, next_attempt_on) VALUES %s"
+	getDueDeliveriesQuery = `
+	SELECT
+		notification_outbox.id,
+		notification_outbox.notification_id,
+		notification_outbox.student_id,
+		notification_outbox.status,
+		notification_outbox.attempts,
+		notification_outbox.next_attempt_on,
+		notification_outbox.last_error,
+		notification_outbox.sent_on,
+		notification.teacher_id,
+		COALESCE(notification_recipient.message, notification.message),
+		COALESCE(student_preference.digest, 'immediate')
+	FROM
+		notification_outbox
+		JOIN notification ON notification.id = notification_outbox.notification_id
+		LEFT JOIN notification_recipient ON notification_recipient.notification_id = notification_outbox.notification_id
+			AND notification_recipient.student_id = notification_outbox.student_id
+		LEFT JOIN student_preference ON student_preference.student_id = notification_outbox.student_id
+	WHERE
+		notification_outbox.status = 'pending'
+		AND notification_outbox.next_attempt_on <= ?
+	ORDER BY
+		notification_outbox.next_attempt_on,
+		notification_outbox.id
+	LIMIT ?
+`
+	getStudentDueDeliveriesQuery = `
+	SELECT
+		notification_outbox.id,
+		notification_outbox.notification_id,
+		notification_outbox.student_id,
+		notification_outbox.status,
+		notification_outbox.attempts,
+		notification_outbox.next_attempt_on,
+		notification_outbox.last_error,
+		notification_outbox.sent_on,
+		notification.teacher_id,
+		COALESCE(notification_recipient.message, notification.message),
+		COALESCE(student_preference.digest, 'immediate')
+	FROM
+		notification_outbox
+		JOIN notification ON notification.id = notification_outbox.notification_id
+		LEFT JOIN notification_recipient ON notification_recipient.notification_id = notification_outbox.notification_id
+			AND notification_recipient.student_id = notification_outbox.student_id
+		LEFT JOIN student_preference ON student_preference.student_id = notification_outbox.student_id
+	WHERE
+		notification_outbox.status = 'pending'
+		AND notification_outbox.next_attempt_on <= ?
+		AND notification_outbox.student_id = ?
+	ORDER BY
+		notification_outbox.next_attempt_on,
+		notification_outbox.id
+`
+	getDeliveriesQuery = `
+	SELECT
+		notification_outbox.id,
+		notification_outbox.notification_id,
+		notification_outbox.student_id,
+		notification_outbox.status,
+		notification_outbox.attempts,
+		notification_outbox.next_attempt_on,
+		notification_outbox.last_error,
+		notification_outbox.sent_on,
+		notification.teacher_id,
+		COALESCE(notification_recipient.message, notification.message),
+		COALESCE(student_preference.digest, 'immediate')
+	FROM
+		notification_outbox
+		JOIN notification ON notification.id = notification_outbox.notification_id
+		LEFT JOIN notification_recipient ON notification_recipient.notification_id = notification_outbox.notification_id
+			AND notification_recipient.student_id = notification_outbox.student_id
+		LEFT JOIN student_preference ON student_preference.student_id = notification_outbox.student_id
+	WHERE
+		notification_outbox.notification_id = ?
+	ORDER BY
+		notification_outbox.id
+`
+	claimDeliveryQuery = `
+	UPDATE
+		notification_outbox
+	SET
+		next_attempt_on = ?
+	WHERE
+		id = ?
+		AND status = 'pending'
+		AND next_attempt_on <= ?
+`
+	markDeliverySentQuery = `
+	UPDATE
+		notification_outbox
+	SET
+		status = 'sent',
+		attempts = attempts + 1,
+		last_error = NULL,
+		sent_on = NOW()
+	WHERE
+		id = ?
+`
+	markDeliveryFailedQuery = `
+	UPDATE
+		notification_outbox
+	SET
+		status = ?,
+		attempts = attempts + 1,
+		last_error = ?,
+		next_attempt_on = COALESCE(?, next_attempt_on)
+	WHERE
+		id = ?
+`
+	createTemplateQuery = `
+	INSERT INTO notification_template (
+		teacher_id,
+		name,
+		body
+	) VALUES (
+		?,
+		?,
+		?
+	)
+`
+	getTemplateQuery = `
+	SELECT
+		id,
+		teacher_id,
+		name,
+		body,
+		created_on,
+		updated_on
+	FROM
+		notification_template
+	WHERE
+		id = ?
+		AND teacher_id = ?
+`
+	getTemplatesByTeacherQuery = `
+	SELECT
+		id,
+		teacher_id,
+		name,
+		body,
+		created_on,
+		updated_on
+	FROM
+		notification_template
+	WHERE
+		teacher_id = ?
+	ORDER BY
+		name
+`
+	updateTemplateQuery = `
+	UPDATE
+		notification_template
+	SET
+		name = ?,
+		body = ?,
+		updated_on = NOW()
+	WHERE
+		id = ?
+		AND teacher_id = ?
+`
+	deleteTemplateQuery = `
+	DELETE FROM
+		notification_template
+	WHERE
+		id = ?
+		AND teacher_id = ?
+`
+	createClassQuery = `
+	INSERT INTO class (
+		teacher_id,
+		code,
+		title,
+		term
+	) VALUES (
+		?,
+		?,
+		?,
+		?
+	)
+`
+	// classColumns are the columns of the class table in the order they are scanned
+	classColumns             = "id, teacher_id, code, title, term, created_on, updated_on"
+	getClassQuery            = "SELECT " + classColumns + " FROM class WHERE id = ? AND teacher_id = ?"
+	getClassesByTeacherQuery = "SELECT " + classColumns + " FROM class WHERE teacher_id = ? ORDER BY term, code"
+	createTermQuery          = `
+	INSERT INTO term (
+		code,
+		name,
+		starts_on,
+		ends_on
+	) VALUES (
+		?,
+		?,
+		?,
+		?
+	)
+`
+	// termColumns are the columns of the term table in the order they are scanned
+	termColumns    = "code, name, starts_on, ends_on, created_on"
+	getTermQuery   = "SELECT " + termColumns + " FROM term WHERE code = ?"
+	getTermsQuery  = "SELECT " + termColumns + " FROM term ORDER BY starts_on, code"
+	getTermAtQuery = "SELECT " + termColumns + " FROM term WHERE starts_on <= ? AND ends_on >= ? ORDER BY starts_on DESC, code LIMIT 1"
+	// copyClassesQuery creates the classes of a term in another term, skipping the classes that exist there. It
+	// takes the target term then the source term.
+	copyClassesQuery = `
+	INSERT INTO class (
+		teacher_id,
+		code,
+		title,
+		term
+	)
+	SELECT
+		copied.teacher,
+		copied.class_code,
+		copied.class_title,
+		?
+	FROM (
+		SELECT
+			teacher_id AS teacher,
+			code AS class_code,
+			title AS class_title
+		FROM
+			class
+		WHERE
+			term = ?
+	) AS copied
+	ON DUPLICATE KEY UPDATE
+		id = id
+`
+	// copyRegistersQuery enrolls the students of the classes of a term to the classes of the same teacher and code in
+	// another term, restoring the registrations removed meanwhile. It takes the target term then the source term.
+	copyRegistersQuery = `
+	INSERT INTO register (
+		student_id,
+		teacher_id,
+		class_id
+	)
+	SELECT
+		copied.student,
+		copied.teacher,
+		copied.class
+	FROM (
+		SELECT
+			register.student_id AS student,
+			register.teacher_id AS teacher,
+			target.id AS class
+		FROM
+			register
+			JOIN class AS source ON source.id = register.class_id
+			JOIN class AS target ON target.teacher_id = source.teacher_id AND target.code = source.code AND target.term = ?
+		WHERE
+			source.term = ?
+			AND register.deleted_on IS NULL
+	) AS copied
+	ON DUPLICATE KEY UPDATE
+		created_on = IF(deleted_on IS NULL, created_on, NOW()),
+		deleted_on = NULL
+`
+	archiveRegistersQuery = `
+	UPDATE
+		register
+	SET
+		deleted_on = NOW()
+	WHERE
+		` + termRegisterCond + `
+		AND deleted_on IS NULL
+`
+	// preferenceColumns are the columns of the student_preference table in the order they are scanned
+	preferenceColumns   = "student_id, unsubscribed, broadcast_opt_out, digest, quiet_start, quiet_end, timezone, updated_on"
+	getPreferenceQuery  = "SELECT " + preferenceColumns + " FROM student_preference WHERE student_id = ?"
+	getPreferencesQuery = "SELECT " + preferenceColumns + " FROM student_preference WHERE student_id IN (%s)"
+	savePreferenceQuery = `
+	INSERT INTO student_preference (
+		student_id,
+		unsubscribed,
+		broadcast_opt_out,
+		digest,
+		quiet_start,
+		quiet_end,
+		timezone
+	) VALUES (
+		?,
+		?,
+		?,
+		?,
+		?,
+		?,
+		?
+	) ON DUPLICATE KEY UPDATE
+		unsubscribed = VALUES(unsubscribed),
+		broadcast_opt_out = VALUES(broadcast_opt_out),
+		digest = VALUES(digest),
+		quiet_start = VALUES(quiet_start),
+		quiet_end = VALUES(quiet_end),
+		timezone = VALUES(timezone),
+		updated_on = NOW()
+`
+	createScheduleQuery = `
+	INSERT INTO scheduled_notification (
+		teacher_id,
+		message,
+		template_id,
+		variables,
+		class_id,
+		send_at
+	) VALUES (
+		?,
+		?,
+		?,
+		?,
+		?,
+		?
+	)
+`
+	// scheduleColumns are the columns of the scheduled_notification table in the order they are scanned
+	scheduleColumns          = "id, teacher_id, message, template_id, variables, class_id, send_at, status, notification_id, last_error, created_on, updated_on"
+	getScheduleQuery         = "SELECT " + scheduleColumns + " FROM scheduled_notification WHERE id = ? AND teacher_id = ?"
+	getPendingSchedulesQuery = "SELECT " + scheduleColumns + " FROM scheduled_notification WHERE teacher_id = ? AND status = 'pending' ORDER BY send_at, id"
+	getDueSchedulesQuery     = "SELECT " + scheduleColumns + " FROM scheduled_notification WHERE status = 'pending' AND send_at <= ? ORDER BY send_at, id LIMIT ?"
+	claimScheduleQuery       = "SELECT id FROM scheduled_notification WHERE id = ? AND status = 'pending' FOR UPDATE SKIP LOCKED"
+	markScheduleSentQuery    = `
+	UPDATE
+		scheduled_notification
+	SET
+		status = 'sent',
+		notification_id = ?,
+		last_error = NULL,
+		updated_on = NOW()
+	WHERE
+		id = ?
+		AND status = 'pending'
+`
+	markScheduleFailedQuery = `
+	UPDATE
+		scheduled_notification
+	SET
+		status = 'failed',
+		last_error = ?,
+		updated_on = NOW()
+	WHERE
+		id = ?
+		AND status = 'pending'
+`
+	cancelScheduleQuery = `
+	UPDATE
+		scheduled_notification
+	SET
+		status = 'cancelled',
+		updated_on = NOW()
+	WHERE
+		id = ?
+		AND teacher_id = ?
+		AND status = 'pending'
 `
 )
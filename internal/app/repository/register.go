@@ -6,9 +6,8 @@ import (
 	"fmt"
 	"github.com/opentracing/opentracing-go"
 	log "github.com/sirupsen/logrus"
-	"github.com/whittier16/student-reg-svc/internal/app/models"
 	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
-	"strings"
+	"strconv"
 )
 
 type RegisterRepository struct {
@@ -16,99 +15,310 @@ type RegisterRepository struct {
 }
 
 // NewRegisterRepository an instance of the RegisterRepository.
-func NewRegisterRepository(db *db.MySQL) RegisterRepository {
-	return RegisterRepository{DB: db.DBClient}
+func NewRegisterRepository(db *db.MySQL) *RegisterRepository {
+	return &RegisterRepository{DB: db.DBClient}
 }
 
-// Register sets the student and teacher emails in a new db record
-func (rr *RegisterRepository) Register(ctx context.Context, teacherEmail string, studentEmails []string) (err error) {
+// Register registers the students to the default class of the teacher in the term. It returns
+// db.ErrDuplicateObject when a student is listed twice or is already registered to one of the
+// classes of the teacher in the term. Registrations removed by Unregister are restored instead.
+func (rr *RegisterRepository) Register(ctx context.Context, teacherEmail, term string, studentEmails []string) (err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "RegisterRepository.Register")
 	defer span.Finish()
 
-	var valueStrings []string
-	var valueArgs []interface{}
-	for _, email := range studentEmails {
-		valueStrings = append(valueStrings, "(?, ?)")
-		valueArgs = append(valueArgs, email)
-		valueArgs = append(valueArgs, teacherEmail)
+	if len(unique(studentEmails)) < len(studentEmails) {
+		return db.ErrDuplicateObject{}
+	}
+	registered, err := rr.FindRegistered(ctx, teacherEmail, term, studentEmails)
+	if err != nil {
+		return err
+	}
+	if len(registered) > 0 {
+		return db.ErrDuplicateObject{}
 	}
 
-	createRegisterStmt := "INSERT INTO register(student_id, teacher_id) VALUES %s"
-	createRegisterStmt = fmt.Sprintf(createRegisterStmt, strings.Join(valueStrings, ", "))
-	tx, err := rr.DB.Begin()
+	err = rr.insertDefault(ctx, teacherEmail, term, studentEmails)
 	if err != nil {
 		log.Println("[Register][Register][Repository] Problem to querying to db, err: ", err.Error())
+		return db.HandleError(err)
+	}
+	return nil
+}
+
+// Upsert registers the students like Register, leaving the students that are already registered
+// to one of the classes of the teacher in the term as they are
+func (rr *RegisterRepository) Upsert(ctx context.Context, teacherEmail, term string, studentEmails []string) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RegisterRepository.Upsert")
+	defer span.Finish()
+
+	registered, err := rr.FindRegistered(ctx, teacherEmail, term, studentEmails)
+	if err != nil {
 		return err
 	}
 
-	_, err = tx.Exec(createRegisterStmt, valueArgs...)
+	err = rr.insertDefault(ctx, teacherEmail, term, without(unique(studentEmails), registered))
 	if err != nil {
-		err := tx.Rollback()
+		log.Println("[Register][Upsert][Repository] Problem to querying to db, err: ", err.Error())
+		return db.HandleError(err)
+	}
+	return nil
+}
+
+// FindRegistered retrieves the given students that are registered to one of the classes of the teacher in the term
+func (rr *RegisterRepository) FindRegistered(ctx context.Context, teacherEmail, term string, studentEmails []string) ([]string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RegisterRepository.FindRegistered")
+	defer span.Finish()
+
+	conn := db.Conn(ctx, rr.DB)
+	var registered []string
+	for _, emailsChunk := range chunk(studentEmails, maxPlaceholders-2) {
+		res, err := queryByTeacher(ctx, conn, getRegisteredStudentsQuery, teacherEmail, term, emailsChunk)
 		if err != nil {
-			return err
+			log.Println("[Register][FindRegistered][Repository] Problem to querying to db, err: ", err.Error())
+			return nil, db.HandleError(err)
 		}
-		log.Println("[Register][Register][Repository] Problem to querying to db, err: ", err.Error())
-		return err
+		registered = append(registered, res...)
 	}
+	// a student is listed once per class of the teacher
+	return unique(registered), nil
+}
 
-	if err := tx.Commit(); err != nil {
-		return err
+// Enroll registers the students to the class, leaving the students that are already enrolled as
+// they are. It returns db.ErrReferenceNotFound if the class or a student does not exist.
+func (rr *RegisterRepository) Enroll(ctx context.Context, classID int, studentEmails []string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RegisterRepository.Enroll")
+	defer span.Finish()
+
+	conn := db.Conn(ctx, rr.DB)
+	// each row takes three placeholders
+	for _, emailsChunk := range chunk(unique(studentEmails), maxPlaceholders/3) {
+		var valueArgs []interface{}
+		for _, email := range emailsChunk {
+			valueArgs = append(valueArgs, email, classID, classID)
+		}
+		_, err := conn.ExecContext(ctx, fmt.Sprintf(upsertRegistersQuery, placeholders(len(emailsChunk), classRegisterRow)), valueArgs...)
+		if err != nil {
+			log.Println("[Register][Enroll][Repository] Problem to querying to db, err: ", err.Error())
+			return db.HandleError(err)
+		}
 	}
-	return
+	return nil
 }
 
-// FindByEmailArr retrieves the emails with the given list of emails
-func (rr *RegisterRepository) FindByEmailArr(ctx context.Context, emails []string) (resp []string, err error) {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "RegisterRepository.FindByEmails")
+// Unenroll soft deletes the registrations of the students to the class and returns the students
+// that were enrolled. Students that are not enrolled are left out.
+func (rr *RegisterRepository) Unenroll(ctx context.Context, classID int, studentEmails []string) ([]string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RegisterRepository.Unenroll")
+	defer span.Finish()
+
+	conn := db.Conn(ctx, rr.DB)
+	var unenrolled []string
+	for _, emailsChunk := range chunk(studentEmails, maxPlaceholders-1) {
+		query, args := inClause(getEnrolledStudentsQuery, emailsChunk)
+		enrolled, err := queryStrings(ctx, conn, query, append([]interface{}{classID}, args...))
+		if err == nil && len(enrolled) > 0 {
+			query, args = inClause(unenrollQuery, enrolled)
+			_, err = conn.ExecContext(ctx, query, append([]interface{}{classID}, args...)...)
+		}
+		if err != nil {
+			log.Println("[Register][Unenroll][Repository] Problem to querying to db, err: ", err.Error())
+			return nil, db.HandleError(err)
+		}
+		unenrolled = append(unenrolled, enrolled...)
+	}
+	return unenrolled, nil
+}
+
+// FindByClasses retrieves the students enrolled to any of the given classes, leaving out the
+// registrations the student is suspended from
+func (rr *RegisterRepository) FindByClasses(ctx context.Context, classIDs []int) ([]string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RegisterRepository.FindByClasses")
 	defer span.Finish()
 
-	var valueStrings []string
-	for _, email := range emails {
-		valueStrings = append(valueStrings, fmt.Sprintf("'%s'", email))
+	ids := make([]string, 0, len(classIDs))
+	for _, id := range classIDs {
+		ids = append(ids, strconv.Itoa(id))
+	}
+
+	var studentEmails []string
+	for _, idsChunk := range chunk(ids, maxPlaceholders) {
+		query, args := inClause(getStudentsByClassQuery, idsChunk)
+		res, err := queryStrings(ctx, db.Conn(ctx, rr.DB), query, args)
+		if err != nil {
+			log.Println("[Register][FindByClasses][Repository] Problem to querying to db, err: ", err.Error())
+			return nil, db.HandleError(err)
+		}
+		studentEmails = append(studentEmails, res...)
+	}
+	// the same student may be returned by more than one chunk
+	return unique(studentEmails), nil
+}
+
+// CopyTerm creates the classes of a term in another term and enrolls the students of every class
+// to its copy. Classes and registrations that already exist in the other term are left as they are.
+func (rr *RegisterRepository) CopyTerm(ctx context.Context, from, to string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RegisterRepository.CopyTerm")
+	defer span.Finish()
+
+	conn := db.Conn(ctx, rr.DB)
+	_, err := conn.ExecContext(ctx, copyClassesQuery, to, from)
+	if err == nil {
+		_, err = conn.ExecContext(ctx, copyRegistersQuery, to, from)
+	}
+	if err != nil {
+		log.Println("[Register][CopyTerm][Repository] Problem to querying to db, err: ", err.Error())
+		return db.HandleError(err)
 	}
+	return nil
+}
+
+// ArchiveTerm soft deletes the registrations to the classes of the term and returns how many there were
+func (rr *RegisterRepository) ArchiveTerm(ctx context.Context, term string) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RegisterRepository.ArchiveTerm")
+	defer span.Finish()
 
-	var getRegQuery = "SELECT student_id FROM register WHERE suspended_on IS NULL AND teacher_id in (%s) GROUP BY student_id"
-	getRegQuery = fmt.Sprintf(getRegQuery, strings.Join(valueStrings, ", "))
+	res, err := db.Conn(ctx, rr.DB).ExecContext(ctx, archiveRegistersQuery, term)
+	var archived int64
+	if err == nil {
+		archived, err = res.RowsAffected()
+	}
+	if err != nil {
+		log.Println("[Register][ArchiveTerm][Repository] Problem to querying to db, err: ", err.Error())
+		return 0, db.HandleError(err)
+	}
+	return int(archived), nil
+}
 
-	rows, err := rr.DB.QueryContext(ctx, getRegQuery)
+// insertDefault creates the default class of the teacher in the term when it does not exist yet
+// and registers the students to it, restoring the registrations removed by Unregister
+func (rr *RegisterRepository) insertDefault(ctx context.Context, teacherEmail, term string, studentEmails []string) error {
+	if len(studentEmails) == 0 {
+		return nil
+	}
+	conn := db.Conn(ctx, rr.DB)
+	_, err := conn.ExecContext(ctx, ensureDefaultClassQuery, teacherEmail, term)
 	if err != nil {
-		log.Println("[Register][FindByEmailArr][Repository] Problem to querying to db, err: ", err.Error())
-		return nil, err
+		return err
 	}
-	defer func(rows *sql.Rows) {
-		err := rows.Close()
+
+	// each row takes four placeholders
+	for _, emailsChunk := range chunk(studentEmails, maxPlaceholders/4) {
+		var valueArgs []interface{}
+		for _, email := range emailsChunk {
+			valueArgs = append(valueArgs, email, teacherEmail, teacherEmail, term)
+		}
+		_, err = conn.ExecContext(ctx, fmt.Sprintf(upsertRegistersQuery, placeholders(len(emailsChunk), defaultClassRegisterRow)), valueArgs...)
 		if err != nil {
-			return
+			return err
 		}
-	}(rows)
+	}
+	return nil
+}
+
+// FindByEmailArr retrieves the students registered to the classes in the term of any of the given teachers
+func (rr *RegisterRepository) FindByEmailArr(ctx context.Context, emails []string, term string) (resp []string, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RegisterRepository.FindByEmails")
+	defer span.Finish()
 
 	// An studentEmails slice to hold data from returned rows.
 	var studentEmails []string
-
-	// Loop through rows, using Scan to assign column data to struct fields.
-	for rows.Next() {
-		var reg models.Register
-		if err := rows.Scan(&reg.StudentID); err != nil {
+	seen := map[string]bool{}
+	// the term takes the last placeholder
+	for _, emailsChunk := range chunk(emails, maxPlaceholders-1) {
+		getRegQuery, args := inClause(getStudentsByTeacherQuery, emailsChunk)
+		res, err := queryStrings(ctx, db.Conn(ctx, rr.DB), getRegQuery, append(args, term))
+		if err != nil {
+			log.Println("[Register][FindByEmailArr][Repository] Problem to querying to db, err: ", err.Error())
 			return studentEmails, err
 		}
-		studentEmails = append(studentEmails, reg.StudentID)
-	}
-	if err = rows.Err(); err != nil {
-		log.Println("[Register][FindByEmailArr][Repository] Problem to querying to db, err: ", err.Error())
-		return studentEmails, err
+		// the same student may be returned by more than one chunk
+		for _, email := range res {
+			if !seen[email] {
+				seen[email] = true
+				studentEmails = append(studentEmails, email)
+			}
+		}
 	}
 	return studentEmails, nil
 }
 
-// Suspend sets the suspend date on an existing record on the requested email of the student
-func (rr *RegisterRepository) Suspend(ctx context.Context, email string) error {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "RegisterRepository.Update")
+// CountByTeacher counts the active and suspended students registered to the classes of the teacher in the term
+func (rr *RegisterRepository) CountByTeacher(ctx context.Context, email, term string) (active, suspended int, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RegisterRepository.CountByTeacher")
 	defer span.Finish()
 
-	_, err := rr.DB.ExecContext(ctx, updateRegisterQuery, email)
+	err = db.Conn(ctx, rr.DB).QueryRowContext(ctx, countStudentsByTeacherQuery, email, term).Scan(&active, &suspended)
 	if err != nil {
-		log.Println("[Register][Suspend][Repository] Problem to querying to db, err: ", err.Error())
-		return err
+		log.Println("[Register][CountByTeacher][Repository] Problem to querying to db, err: ", err.Error())
+		return 0, 0, db.HandleError(err)
 	}
+	return active, suspended, nil
+}
+
+// Unregister soft deletes the registrations of the students to every class of the teacher in the term and returns the
+// students that were registered. Students that are not registered are left out.
+func (rr *RegisterRepository) Unregister(ctx context.Context, teacherEmail, term string, studentEmails []string) ([]string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "RegisterRepository.Unregister")
+	defer span.Finish()
+
+	conn := db.Conn(ctx, rr.DB)
+	var unregistered []string
+	for _, emailsChunk := range chunk(studentEmails, maxPlaceholders-2) {
+		registered, err := queryByTeacher(ctx, conn, getRegisteredStudentsQuery, teacherEmail, term, emailsChunk)
+		// a student is listed once per class of the teacher
+		registered = unique(registered)
+		if err == nil && len(registered) > 0 {
+			err = execByTeacher(ctx, conn, unregisterQuery, teacherEmail, term, registered)
+		}
+		if err != nil {
+			log.Println("[Register][Unregister][Repository] Problem to querying to db, err: ", err.Error())
+			return nil, db.HandleError(err)
+		}
+		unregistered = append(unregistered, registered...)
+	}
+	return unregistered, nil
+}
+
+// queryByTeacher runs query, whose first placeholders are the teacher and the term followed by an IN list of students
+func queryByTeacher(ctx context.Context, conn db.Querier, query, teacherEmail, term string, studentEmails []string) ([]string, error) {
+	query, args := inClause(query, studentEmails)
+	return queryStrings(ctx, conn, query, append([]interface{}{teacherEmail, term}, args...))
+}
+
+// execByTeacher runs query, whose first placeholders are the teacher and the term followed by an IN list of students
+func execByTeacher(ctx context.Context, conn db.Querier, query, teacherEmail, term string, studentEmails []string) error {
+	query, args := inClause(query, studentEmails)
+	_, err := conn.ExecContext(ctx, query, append([]interface{}{teacherEmail, term}, args...)...)
 	return err
 }
+
+// without returns the values that are not in exclude
+func without(values, exclude []string) []string {
+	if len(exclude) == 0 {
+		return values
+	}
+	skip := make(map[string]bool, len(exclude))
+	for _, v := range exclude {
+		skip[v] = true
+	}
+	var rest []string
+	for _, v := range values {
+		if !skip[v] {
+			rest = append(rest, v)
+		}
+	}
+	return rest
+}
+
+// unique returns the values without their repetitions, in the order they first appear
+func unique(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var rest []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			rest = append(rest, v)
+		}
+	}
+	return rest
+}
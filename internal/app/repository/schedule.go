@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/opentracing/opentracing-go"
+	log "github.com/sirupsen/logrus"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"time"
+)
+
+type ScheduleRepository struct {
+	DB *sql.DB
+}
+
+// NewScheduleRepository an instance of the ScheduleRepository.
+func NewScheduleRepository(db *db.MySQL) *ScheduleRepository {
+	return &ScheduleRepository{DB: db.DBClient}
+}
+
+// Create queues a notification to be sent at its send date
+func (sc *ScheduleRepository) Create(ctx context.Context, input *models.ScheduledNotification) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "ScheduleRepository.Create")
+	defer span.Finish()
+
+	// a JSON column rejects binary strings, so the variables are sent as text
+	var variables *string
+	if len(input.Variables) > 0 {
+		b, _ := json.Marshal(input.Variables)
+		v := string(b)
+		variables = &v
+	}
+	res, err := db.Conn(ctx, sc.DB).ExecContext(ctx, createScheduleQuery,
+		input.TeacherID,
+		input.Message,
+		input.TemplateID,
+		variables,
+		input.ClassID,
+		input.SendAt,
+	)
+	if err == nil {
+		var id int64
+		id, err = res.LastInsertId()
+		input.ID = int(id)
+		input.Status = models.SchedulePending
+		input.CreatedOn = time.Now()
+	}
+	if err != nil {
+		log.Println("[Schedule][Create][Repository] Problem to querying to db, err: ", err.Error())
+		return db.HandleError(err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves the scheduled notification of the teacher with the given id
+func (sc *ScheduleRepository) FindByID(ctx context.Context, teacherEmail string, id int) (models.ScheduledNotification, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "ScheduleRepository.FindByID")
+	defer span.Finish()
+
+	row := db.Conn(ctx, sc.DB).QueryRowContext(ctx, getScheduleQuery, id, teacherEmail)
+	resp, err := scanSchedule(row.Scan)
+	if err != nil {
+		log.Println("[Schedule][FindByID][Repository] Problem to querying to db, err: ", err.Error())
+		return resp, db.HandleError(err)
+	}
+
+	return resp, nil
+}
+
+// FindPending retrieves the pending scheduled notifications of the teacher, the next one first
+func (sc *ScheduleRepository) FindPending(ctx context.Context, teacherEmail string) ([]models.ScheduledNotification, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "ScheduleRepository.FindPending")
+	defer span.Finish()
+
+	schedules, err := querySchedules(ctx, db.Conn(ctx, sc.DB), getPendingSchedulesQuery, teacherEmail)
+	if err != nil {
+		log.Println("[Schedule][FindPending][Repository] Problem to querying to db, err: ", err.Error())
+		return nil, db.HandleError(err)
+	}
+
+	return schedules, nil
+}
+
+// Due retrieves up to limit pending scheduled notifications whose send date passed at now, oldest first
+func (sc *ScheduleRepository) Due(ctx context.Context, now time.Time, limit int) ([]models.ScheduledNotification, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "ScheduleRepository.Due")
+	defer span.Finish()
+
+	schedules, err := querySchedules(ctx, db.Conn(ctx, sc.DB), getDueSchedulesQuery, now, limit)
+	if err != nil {
+		log.Println("[Schedule][Due][Repository] Problem to querying to db, err: ", err.Error())
+		return nil, db.HandleError(err)
+	}
+
+	return schedules, nil
+}
+
+// Claim locks the pending scheduled notification until the transaction of ctx ends. Another
+// transaction skips the locked row instead of waiting for it.
+func (sc *ScheduleRepository) Claim(ctx context.Context, id int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "ScheduleRepository.Claim")
+	defer span.Finish()
+
+	var claimed int
+	err := db.Conn(ctx, sc.DB).QueryRowContext(ctx, claimScheduleQuery, id).Scan(&claimed)
+	if err != nil {
+		log.Println("[Schedule][Claim][Repository] Problem to querying to db, err: ", err.Error())
+		return db.HandleError(err)
+	}
+
+	return nil
+}
+
+// MarkSent records the notification a pending scheduled notification was sent as
+func (sc *ScheduleRepository) MarkSent(ctx context.Context, id int, notificationID int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "ScheduleRepository.MarkSent")
+	defer span.Finish()
+
+	err := execOne(ctx, db.Conn(ctx, sc.DB), markScheduleSentQuery, notificationID, id)
+	if err != nil {
+		log.Println("[Schedule][MarkSent][Repository] Problem to querying to db, err: ", err.Error())
+		return db.HandleError(err)
+	}
+
+	return nil
+}
+
+// MarkFailed records why a pending scheduled notification could not be sent
+func (sc *ScheduleRepository) MarkFailed(ctx context.Context, id int, reason string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "ScheduleRepository.MarkFailed")
+	defer span.Finish()
+
+	err := execOne(ctx, db.Conn(ctx, sc.DB), markScheduleFailedQuery, truncate(reason, 255), id)
+	if err != nil {
+		log.Println("[Schedule][MarkFailed][Repository] Problem to querying to db, err: ", err.Error())
+		return db.HandleError(err)
+	}
+
+	return nil
+}
+
+// Cancel cancels a pending scheduled notification of the teacher
+func (sc *ScheduleRepository) Cancel(ctx context.Context, teacherEmail string, id int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "ScheduleRepository.Cancel")
+	defer span.Finish()
+
+	err := execOne(ctx, db.Conn(ctx, sc.DB), cancelScheduleQuery, id, teacherEmail)
+	if err != nil {
+		log.Println("[Schedule][Cancel][Repository] Problem to querying to db, err: ", err.Error())
+		return db.HandleError(err)
+	}
+
+	return nil
+}
+
+// querySchedules runs a query selecting the scheduleColumns
+func querySchedules(ctx context.Context, conn db.Querier, query string, args ...interface{}) ([]models.ScheduledNotification, error) {
+	schedules := []models.ScheduledNotification{}
+	err := queryRows(ctx, conn, query, args, func(rows *sql.Rows) error {
+		s, err := scanSchedule(rows.Scan)
+		schedules = append(schedules, s)
+		return err
+	})
+	return schedules, err
+}
+
+// scanSchedule reads the scheduleColumns of a row
+func scanSchedule(scan func(dest ...interface{}) error) (models.ScheduledNotification, error) {
+	var (
+		s              models.ScheduledNotification
+		templateID     sql.NullInt64
+		variables      []byte
+		classID        sql.NullInt64
+		notificationID sql.NullInt64
+		lastError      sql.NullString
+	)
+	err := scan(
+		&s.ID,
+		&s.TeacherID,
+		&s.Message,
+		&templateID,
+		&variables,
+		&classID,
+		&s.SendAt,
+		&s.Status,
+		&notificationID,
+		&lastError,
+		&s.CreatedOn,
+		&s.UpdatedOn,
+	)
+	if err != nil {
+		return s, err
+	}
+	if classID.Valid {
+		id := int(classID.Int64)
+		s.ClassID = &id
+	}
+	if templateID.Valid {
+		id := int(templateID.Int64)
+		s.TemplateID = &id
+	}
+	if notificationID.Valid {
+		id := int(notificationID.Int64)
+		s.NotificationID = &id
+	}
+	if lastError.Valid {
+		s.LastError = &lastError.String
+	}
+	if len(variables) > 0 {
+		err = json.Unmarshal(variables, &s.Variables)
+	}
+	return s, err
+}
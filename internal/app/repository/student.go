@@ -3,12 +3,10 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"fmt"
 	"github.com/opentracing/opentracing-go"
 	log "github.com/sirupsen/logrus"
 	"github.com/whittier16/student-reg-svc/internal/app/models"
 	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
-	"strings"
 )
 
 type StudentRepository struct {
@@ -16,8 +14,8 @@ type StudentRepository struct {
 }
 
 // NewStudentRepository an instance of the StudentRepository.
-func NewStudentRepository(db *db.MySQL) StudentRepository {
-	return StudentRepository{DB: db.DBClient}
+func NewStudentRepository(db *db.MySQL) *StudentRepository {
+	return &StudentRepository{DB: db.DBClient}
 }
 
 // Create sets the email and name in a new db record
@@ -25,13 +23,13 @@ func (sr *StudentRepository) Create(ctx context.Context, input *models.Student)
 	span, ctx := opentracing.StartSpanFromContext(ctx, "studentRepository.Create")
 	defer span.Finish()
 
-	_, err = sr.DB.ExecContext(ctx, createStudent,
+	_, err = db.Conn(ctx, sr.DB).ExecContext(ctx, createStudent,
 		input.Email,
 		input.Name,
 	)
 	if err != nil {
 		log.Println("[Student][Create][Repository] Problem to querying to db, err: ", err.Error())
-		return err
+		return db.HandleError(err)
 	}
 
 	return nil
@@ -42,7 +40,7 @@ func (sr *StudentRepository) FindByEmail(ctx context.Context, email string) (res
 	span, ctx := opentracing.StartSpanFromContext(ctx, "StudentRepository.FindByEmail")
 	defer span.Finish()
 
-	err = sr.DB.QueryRowContext(ctx, getStudentQuery, email).Scan(
+	err = db.Conn(ctx, sr.DB).QueryRowContext(ctx, getStudentQuery, email).Scan(
 		&resp.Email,
 		&resp.Name,
 		&resp.CreatedOn,
@@ -51,56 +49,160 @@ func (sr *StudentRepository) FindByEmail(ctx context.Context, email string) (res
 	)
 	if err != nil {
 		log.Println("[Student][FindByEmail][Repository] Problem to querying to db, err: ", err.Error())
-		return resp, err
+		return resp, db.HandleError(err)
 	}
 
 	return resp, nil
 }
 
-// FindByEmailArr retrieves the emails with the given list of emails
-func (sr *StudentRepository) FindByEmailArr(ctx context.Context, emails []string, isSuspended bool) (resp []string, err error) {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "StudentRepository.FindByEmailArr")
+// FindByEmails retrieves the given students that exist
+func (sr *StudentRepository) FindByEmails(ctx context.Context, emails []string) ([]models.Student, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "StudentRepository.FindByEmails")
 	defer span.Finish()
 
-	var valueStrings []string
-	for _, email := range emails {
-		valueStrings = append(valueStrings, fmt.Sprintf("'%s'", email))
+	students := []models.Student{}
+	for _, emailsChunk := range chunk(emails, maxPlaceholders) {
+		query, args := inClause(getStudentsQuery, emailsChunk)
+		err := queryRows(ctx, db.Conn(ctx, sr.DB), query, args, func(rows *sql.Rows) error {
+			var s models.Student
+			if err := rows.Scan(&s.Email, &s.Name, &s.CreatedOn, &s.DeletedOn, &s.UpdatedOn); err != nil {
+				return err
+			}
+			students = append(students, s)
+			return nil
+		})
+		if err != nil {
+			log.Println("[Student][FindByEmails][Repository] Problem to querying to db, err: ", err.Error())
+			return nil, db.HandleError(err)
+		}
 	}
+	return students, nil
+}
+
+// FindByEmailArr retrieves the emails with the given list of emails, leaving out
+// globally suspended students unless isSuspended is set
+func (sr *StudentRepository) FindByEmailArr(ctx context.Context, emails []string, isSuspended bool) (resp []string, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "StudentRepository.FindByEmailArr")
+	defer span.Finish()
 
-	getQuery := "SELECT email FROM student JOIN register ON student.email = register.student_id WHERE student.email in (%s)%s"
 	suspendedQry := ""
 	if !isSuspended {
-		suspendedQry = ` AND register.suspended_on IS NULL`
-	}
-	getQuery = fmt.Sprintf(getQuery, strings.Join(valueStrings, ", "), suspendedQry)
-	fmt.Println(getQuery)
-	rows, err := sr.DB.QueryContext(ctx, getQuery)
-	if err != nil {
-		log.Println("[Student][FindByEmailArr][Repository] Problem to querying to db, err: ", err.Error())
-		return nil, err
+		suspendedQry = notSuspendedStudentCond
 	}
-	defer func(rows *sql.Rows) {
-		err := rows.Close()
+
+	// studentEmails slice to hold data from returned rows.
+	var studentEmails []string
+	for _, emailsChunk := range chunk(emails, maxPlaceholders) {
+		getQuery, args := inClause(getStudentsByEmailQuery+suspendedQry, emailsChunk)
+		res, err := queryStrings(ctx, db.Conn(ctx, sr.DB), getQuery, args)
 		if err != nil {
 			log.Println("[Student][FindByEmailArr][Repository] Problem to querying to db, err: ", err.Error())
-			return
+			return studentEmails, err
 		}
-	}(rows)
+		studentEmails = append(studentEmails, res...)
+	}
+	return studentEmails, nil
+}
 
-	// studentEmails slice to hold data from returned rows.
-	var studentEmails []string
+// FindNotSuspended retrieves the emails of the given students that are neither suspended
+// globally nor from the teacher
+func (sr *StudentRepository) FindNotSuspended(ctx context.Context, emails []string, teacherEmail string) (resp []string, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "StudentRepository.FindNotSuspended")
+	defer span.Finish()
 
-	// Loop through rows, using Scan to assign column data to struct fields.
-	for rows.Next() {
-		var student models.Student
-		if err := rows.Scan(&student.Email); err != nil {
+	var studentEmails []string
+	// one placeholder of each chunk is taken by the teacher
+	for _, emailsChunk := range chunk(emails, maxPlaceholders-1) {
+		getQuery, args := inClause(getNotSuspendedStudentsQuery, emailsChunk)
+		res, err := queryStrings(ctx, db.Conn(ctx, sr.DB), getQuery, append(args, teacherEmail))
+		if err != nil {
+			log.Println("[Student][FindNotSuspended][Repository] Problem to querying to db, err: ", err.Error())
 			return studentEmails, err
 		}
-		studentEmails = append(studentEmails, student.Email)
-	}
-	if err = rows.Err(); err != nil {
-		log.Println("[Student][FindByEmailArr][Repository] Problem to querying to db, err: ", err.Error())
-		return studentEmails, err
+		studentEmails = append(studentEmails, res...)
 	}
 	return studentEmails, nil
 }
+
+// Update changes the email and name of the student with the given email. The registrations
+// follow an email change through the ON UPDATE CASCADE of the register foreign key.
+func (sr *StudentRepository) Update(ctx context.Context, email string, input *models.Student) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "StudentRepository.Update")
+	defer span.Finish()
+
+	_, err := db.Conn(ctx, sr.DB).ExecContext(ctx, updateStudentQuery,
+		input.Email,
+		input.Name,
+		email,
+	)
+	if err != nil {
+		log.Println("[Student][Update][Repository] Problem to querying to db, err: ", err.Error())
+		return db.HandleError(err)
+	}
+
+	return nil
+}
+
+// Delete soft deletes the student by setting its delete date
+func (sr *StudentRepository) Delete(ctx context.Context, email string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "StudentRepository.Delete")
+	defer span.Finish()
+
+	err := execOne(ctx, db.Conn(ctx, sr.DB), deleteStudentQuery, email)
+	if err != nil {
+		log.Println("[Student][Delete][Repository] Problem to querying to db, err: ", err.Error())
+		return db.HandleError(err)
+	}
+
+	return nil
+}
+
+// Restore clears the delete date of a soft deleted student
+func (sr *StudentRepository) Restore(ctx context.Context, email string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "StudentRepository.Restore")
+	defer span.Finish()
+
+	err := execOne(ctx, db.Conn(ctx, sr.DB), restoreStudentQuery, email)
+	if err != nil {
+		log.Println("[Student][Restore][Repository] Problem to querying to db, err: ", err.Error())
+		return db.HandleError(err)
+	}
+
+	return nil
+}
+
+// List retrieves a page of the students matching the filter
+func (sr *StudentRepository) List(ctx context.Context, f models.ListFilter) ([]models.Student, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "StudentRepository.List")
+	defer span.Finish()
+
+	students := []models.Student{}
+	query, args := listQuery("student", personColumns, f)
+	err := queryRows(ctx, db.Conn(ctx, sr.DB), query, args, func(rows *sql.Rows) error {
+		var s models.Student
+		if err := rows.Scan(&s.Email, &s.Name, &s.CreatedOn, &s.DeletedOn, &s.UpdatedOn); err != nil {
+			return err
+		}
+		students = append(students, s)
+		return nil
+	})
+	if err != nil {
+		log.Println("[Student][List][Repository] Problem to querying to db, err: ", err.Error())
+		return nil, db.HandleError(err)
+	}
+	return students, nil
+}
+
+// Count counts every student matching the filter, ignoring its cursor and limit
+func (sr *StudentRepository) Count(ctx context.Context, f models.ListFilter) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "StudentRepository.Count")
+	defer span.Finish()
+
+	query, args := countQuery("student", f)
+	n, err := queryCount(ctx, db.Conn(ctx, sr.DB), query, args)
+	if err != nil {
+		log.Println("[Student][Count][Repository] Problem to querying to db, err: ", err.Error())
+		return 0, db.HandleError(err)
+	}
+	return n, nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/opentracing/opentracing-go"
+	log "github.com/sirupsen/logrus"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+)
+
+type SuspensionRepository struct {
+	DB *sql.DB
+}
+
+// NewSuspensionRepository an instance of the SuspensionRepository.
+func NewSuspensionRepository(db *db.MySQL) *SuspensionRepository {
+	return &SuspensionRepository{DB: db.DBClient}
+}
+
+// Create adds a suspension to the history of the student
+func (hr *SuspensionRepository) Create(ctx context.Context, input *models.Suspension) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "SuspensionRepository.Create")
+	defer span.Finish()
+
+	res, err := db.Conn(ctx, hr.DB).ExecContext(ctx, createSuspensionQuery,
+		input.StudentID,
+		input.TeacherID,
+		input.Reason,
+		input.SuspendedBy,
+		input.EndsOn,
+	)
+	if err == nil {
+		var id int64
+		id, err = res.LastInsertId()
+		input.ID = int(id)
+	}
+	if err != nil {
+		log.Println("[Suspension][Create][Repository] Problem to querying to db, err: ", err.Error())
+		return db.HandleError(err)
+	}
+
+	return nil
+}
+
+// FindActive retrieves the suspension of the student in force globally or, when teacherEmail
+// is given, from the teacher
+func (hr *SuspensionRepository) FindActive(ctx context.Context, email, teacherEmail string) (models.Suspension, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "SuspensionRepository.FindActive")
+	defer span.Finish()
+
+	row := db.Conn(ctx, hr.DB).QueryRowContext(ctx, getActiveSuspensionQuery, email, teacherEmail)
+	resp, err := scanSuspension(row.Scan)
+	if err != nil {
+		log.Println("[Suspension][FindActive][Repository] Problem to querying to db, err: ", err.Error())
+		return resp, db.HandleError(err)
+	}
+
+	return resp, nil
+}
+
+// Lift ends the suspensions of the student in force from the teacher or, when teacherEmail
+// is empty, every suspension in force. It returns the number of suspensions lifted.
+func (hr *SuspensionRepository) Lift(ctx context.Context, email, teacherEmail, liftedBy string) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "SuspensionRepository.Lift")
+	defer span.Finish()
+
+	res, err := db.Conn(ctx, hr.DB).ExecContext(ctx, liftSuspensionQuery, liftedBy, email, teacherEmail, teacherEmail)
+	var n int64
+	if err == nil {
+		n, err = res.RowsAffected()
+	}
+	if err != nil {
+		log.Println("[Suspension][Lift][Repository] Problem to querying to db, err: ", err.Error())
+		return 0, db.HandleError(err)
+	}
+
+	return int(n), nil
+}
+
+// FindByStudent retrieves the suspension history of the student, latest first
+func (hr *SuspensionRepository) FindByStudent(ctx context.Context, email string) ([]models.Suspension, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "SuspensionRepository.FindByStudent")
+	defer span.Finish()
+
+	suspensions := []models.Suspension{}
+	err := queryRows(ctx, db.Conn(ctx, hr.DB), getSuspensionsQuery, []interface{}{email}, func(rows *sql.Rows) error {
+		s, err := scanSuspension(rows.Scan)
+		if err != nil {
+			return err
+		}
+		suspensions = append(suspensions, s)
+		return nil
+	})
+	if err != nil {
+		log.Println("[Suspension][FindByStudent][Repository] Problem to querying to db, err: ", err.Error())
+		return nil, db.HandleError(err)
+	}
+
+	return suspensions, nil
+}
+
+// scanSuspension scans a suspension_history row selected by getSuspensionsQuery
+func scanSuspension(scan func(dest ...interface{}) error) (models.Suspension, error) {
+	var (
+		s           models.Suspension
+		teacherID   sql.NullString
+		suspendedBy sql.NullString
+		liftedBy    sql.NullString
+	)
+	err := scan(
+		&s.ID,
+		&s.StudentID,
+		&teacherID,
+		&s.Reason,
+		&suspendedBy,
+		&s.SuspendedOn,
+		&s.EndsOn,
+		&s.LiftedOn,
+		&liftedBy,
+	)
+	if teacherID.Valid {
+		s.TeacherID = &teacherID.String
+	}
+	s.SuspendedBy = suspendedBy.String
+	if liftedBy.Valid {
+		s.LiftedBy = &liftedBy.String
+	}
+	return s, err
+}
@@ -14,8 +14,8 @@ type TeacherRepository struct {
 }
 
 // NewTeacherRepository an instance of the NewTeacherRepository.
-func NewTeacherRepository(db *db.MySQL) TeacherRepository {
-	return TeacherRepository{DB: db.DBClient}
+func NewTeacherRepository(db *db.MySQL) *TeacherRepository {
+	return &TeacherRepository{DB: db.DBClient}
 }
 
 // FindByEmail retrieves the teacher with the given email
@@ -23,7 +23,7 @@ func (tr *TeacherRepository) FindByEmail(ctx context.Context, email string) (res
 	span, ctx := opentracing.StartSpanFromContext(ctx, "TeacherRepository.FindByEmail")
 	defer span.Finish()
 
-	err = tr.DB.QueryRowContext(ctx, getTeacherQuery, email).Scan(
+	err = db.Conn(ctx, tr.DB).QueryRowContext(ctx, getTeacherQuery, email).Scan(
 		&resp.Email,
 		&resp.Name,
 		&resp.CreatedOn,
@@ -32,7 +32,7 @@ func (tr *TeacherRepository) FindByEmail(ctx context.Context, email string) (res
 	)
 	if err != nil {
 		log.Println("[Teacher][FindByEmail][Repository] Problem to querying to db, err: ", err.Error())
-		return resp, err
+		return resp, db.HandleError(err)
 	}
 
 	return resp, nil
@@ -43,14 +43,83 @@ func (tr *TeacherRepository) Create(ctx context.Context, input *models.Teacher)
 	span, ctx := opentracing.StartSpanFromContext(ctx, "TeacherRepository.Create")
 	defer span.Finish()
 
-	_, err = tr.DB.ExecContext(ctx, createTeacher,
+	_, err = db.Conn(ctx, tr.DB).ExecContext(ctx, createTeacher,
 		input.Email,
 		input.Name,
 	)
 	if err != nil {
 		log.Println("[Teacher][Create][Repository] Problem to querying to db, err: ", err.Error())
-		return err
+		return db.HandleError(err)
 	}
 
 	return nil
 }
+
+// Update changes the email and name of the teacher with the given email. The registrations
+// follow an email change through the ON UPDATE CASCADE of the register foreign key.
+func (tr *TeacherRepository) Update(ctx context.Context, email string, input *models.Teacher) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "TeacherRepository.Update")
+	defer span.Finish()
+
+	_, err := db.Conn(ctx, tr.DB).ExecContext(ctx, updateTeacherQuery,
+		input.Email,
+		input.Name,
+		email,
+	)
+	if err != nil {
+		log.Println("[Teacher][Update][Repository] Problem to querying to db, err: ", err.Error())
+		return db.HandleError(err)
+	}
+
+	return nil
+}
+
+// Delete soft deletes the teacher by setting its delete date
+func (tr *TeacherRepository) Delete(ctx context.Context, email string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "TeacherRepository.Delete")
+	defer span.Finish()
+
+	err := execOne(ctx, db.Conn(ctx, tr.DB), deleteTeacherQuery, email)
+	if err != nil {
+		log.Println("[Teacher][Delete][Repository] Problem to querying to db, err: ", err.Error())
+		return db.HandleError(err)
+	}
+
+	return nil
+}
+
+// List retrieves a page of the teachers matching the filter
+func (tr *TeacherRepository) List(ctx context.Context, f models.ListFilter) ([]models.Teacher, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "TeacherRepository.List")
+	defer span.Finish()
+
+	teachers := []models.Teacher{}
+	query, args := listQuery("teacher", personColumns, f)
+	err := queryRows(ctx, db.Conn(ctx, tr.DB), query, args, func(rows *sql.Rows) error {
+		var t models.Teacher
+		if err := rows.Scan(&t.Email, &t.Name, &t.CreatedOn, &t.DeletedOn, &t.UpdatedOn); err != nil {
+			return err
+		}
+		teachers = append(teachers, t)
+		return nil
+	})
+	if err != nil {
+		log.Println("[Teacher][List][Repository] Problem to querying to db, err: ", err.Error())
+		return nil, db.HandleError(err)
+	}
+	return teachers, nil
+}
+
+// Count counts every teacher matching the filter, ignoring its cursor and limit
+func (tr *TeacherRepository) Count(ctx context.Context, f models.ListFilter) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "TeacherRepository.Count")
+	defer span.Finish()
+
+	query, args := countQuery("teacher", f)
+	n, err := queryCount(ctx, db.Conn(ctx, tr.DB), query, args)
+	if err != nil {
+		log.Println("[Teacher][Count][Repository] Problem to querying to db, err: ", err.Error())
+		return 0, db.HandleError(err)
+	}
+	return n, nil
+}
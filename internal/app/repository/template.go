@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/opentracing/opentracing-go"
+	log "github.com/sirupsen/logrus"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"time"
+)
+
+type TemplateRepository struct {
+	DB *sql.DB
+}
+
+// NewTemplateRepository an instance of the TemplateRepository.
+func NewTemplateRepository(db *db.MySQL) *TemplateRepository {
+	return &TemplateRepository{DB: db.DBClient}
+}
+
+// Create persists a new notification template of the teacher
+func (tm *TemplateRepository) Create(ctx context.Context, input *models.Template) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "TemplateRepository.Create")
+	defer span.Finish()
+
+	res, err := db.Conn(ctx, tm.DB).ExecContext(ctx, createTemplateQuery,
+		input.TeacherID,
+		input.Name,
+		input.Body,
+	)
+	if err == nil {
+		var id int64
+		id, err = res.LastInsertId()
+		input.ID = int(id)
+		input.CreatedOn = time.Now()
+	}
+	if err != nil {
+		log.Println("[Template][Create][Repository] Problem to querying to db, err: ", err.Error())
+		return db.HandleError(err)
+	}
+
+	return nil
+}
+
+// FindByID retrieves the template of the teacher with the given id
+func (tm *TemplateRepository) FindByID(ctx context.Context, teacherEmail string, id int) (models.Template, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "TemplateRepository.FindByID")
+	defer span.Finish()
+
+	var t models.Template
+	err := db.Conn(ctx, tm.DB).QueryRowContext(ctx, getTemplateQuery, id, teacherEmail).Scan(
+		&t.ID,
+		&t.TeacherID,
+		&t.Name,
+		&t.Body,
+		&t.CreatedOn,
+		&t.UpdatedOn,
+	)
+	if err != nil {
+		log.Println("[Template][FindByID][Repository] Problem to querying to db, err: ", err.Error())
+		return t, db.HandleError(err)
+	}
+
+	return t, nil
+}
+
+// FindByTeacher retrieves the templates of the teacher ordered by name
+func (tm *TemplateRepository) FindByTeacher(ctx context.Context, teacherEmail string) ([]models.Template, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "TemplateRepository.FindByTeacher")
+	defer span.Finish()
+
+	templates := []models.Template{}
+	err := queryRows(ctx, db.Conn(ctx, tm.DB), getTemplatesByTeacherQuery, []interface{}{teacherEmail}, func(rows *sql.Rows) error {
+		var t models.Template
+		if err := rows.Scan(&t.ID, &t.TeacherID, &t.Name, &t.Body, &t.CreatedOn, &t.UpdatedOn); err != nil {
+			return err
+		}
+		templates = append(templates, t)
+		return nil
+	})
+	if err != nil {
+		log.Println("[Template][FindByTeacher][Repository] Problem to querying to db, err: ", err.Error())
+		return nil, db.HandleError(err)
+	}
+
+	return templates, nil
+}
+
+// Update changes the name and body of the template
+func (tm *TemplateRepository) Update(ctx context.Context, input *models.Template) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "TemplateRepository.Update")
+	defer span.Finish()
+
+	_, err := db.Conn(ctx, tm.DB).ExecContext(ctx, updateTemplateQuery,
+		input.Name,
+		input.Body,
+		input.ID,
+		input.TeacherID,
+	)
+	if err != nil {
+		log.Println("[Template][Update][Repository] Problem to querying to db, err: ", err.Error())
+		return db.HandleError(err)
+	}
+
+	return nil
+}
+
+// Delete removes the template of the teacher
+func (tm *TemplateRepository) Delete(ctx context.Context, teacherEmail string, id int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "TemplateRepository.Delete")
+	defer span.Finish()
+
+	err := execOne(ctx, db.Conn(ctx, tm.DB), deleteTemplateQuery, id, teacherEmail)
+	if err != nil {
+		log.Println("[Template][Delete][Repository] Problem to querying to db, err: ", err.Error())
+		return db.HandleError(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/opentracing/opentracing-go"
+	log "github.com/sirupsen/logrus"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"time"
+)
+
+type TermRepository struct {
+	DB *sql.DB
+}
+
+// NewTermRepository an instance of the TermRepository.
+func NewTermRepository(db *db.MySQL) *TermRepository {
+	return &TermRepository{DB: db.DBClient}
+}
+
+// Create adds an academic term
+func (tt *TermRepository) Create(ctx context.Context, input *models.Term) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "TermRepository.Create")
+	defer span.Finish()
+
+	_, err := db.Conn(ctx, tt.DB).ExecContext(ctx, createTermQuery,
+		input.Code,
+		input.Name,
+		input.StartsOn.Format(models.TermDateLayout),
+		input.EndsOn.Format(models.TermDateLayout),
+	)
+	if err != nil {
+		log.Println("[Term][Create][Repository] Problem to querying to db, err: ", err.Error())
+		return db.HandleError(err)
+	}
+
+	input.CreatedOn = time.Now()
+	return nil
+}
+
+// FindByCode retrieves the term with the given code
+func (tt *TermRepository) FindByCode(ctx context.Context, code string) (models.Term, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "TermRepository.FindByCode")
+	defer span.Finish()
+
+	t, err := scanTerm(db.Conn(ctx, tt.DB).QueryRowContext(ctx, getTermQuery, code).Scan)
+	if err != nil {
+		log.Println("[Term][FindByCode][Repository] Problem to querying to db, err: ", err.Error())
+		return t, db.HandleError(err)
+	}
+
+	return t, nil
+}
+
+// FindAt retrieves the term whose dates include at, the latest starting one when terms overlap
+func (tt *TermRepository) FindAt(ctx context.Context, at time.Time) (models.Term, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "TermRepository.FindAt")
+	defer span.Finish()
+
+	day := at.Format(models.TermDateLayout)
+	t, err := scanTerm(db.Conn(ctx, tt.DB).QueryRowContext(ctx, getTermAtQuery, day, day).Scan)
+	if err != nil {
+		log.Println("[Term][FindAt][Repository] Problem to querying to db, err: ", err.Error())
+		return t, db.HandleError(err)
+	}
+
+	return t, nil
+}
+
+// FindAll retrieves every term ordered by start date
+func (tt *TermRepository) FindAll(ctx context.Context) ([]models.Term, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "TermRepository.FindAll")
+	defer span.Finish()
+
+	terms := []models.Term{}
+	err := queryRows(ctx, db.Conn(ctx, tt.DB), getTermsQuery, nil, func(rows *sql.Rows) error {
+		t, err := scanTerm(rows.Scan)
+		terms = append(terms, t)
+		return err
+	})
+	if err != nil {
+		log.Println("[Term][FindAll][Repository] Problem to querying to db, err: ", err.Error())
+		return nil, db.HandleError(err)
+	}
+
+	return terms, nil
+}
+
+// scanTerm reads the termColumns of a row
+func scanTerm(scan func(dest ...interface{}) error) (models.Term, error) {
+	var t models.Term
+	err := scan(
+		&t.Code,
+		&t.Name,
+		&t.StartsOn,
+		&t.EndsOn,
+		&t.CreatedOn,
+	)
+	return t, err
+}
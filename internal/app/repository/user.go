@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/opentracing/opentracing-go"
+	log "github.com/sirupsen/logrus"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+)
+
+type UserRepository struct {
+	DB *sql.DB
+}
+
+// NewUserRepository an instance of the UserRepository.
+func NewUserRepository(db *db.MySQL) *UserRepository {
+	return &UserRepository{DB: db.DBClient}
+}
+
+// Create sets the user credentials in a new db record and assigns the generated id to input
+func (ur *UserRepository) Create(ctx context.Context, input *models.User) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserRepository.Create")
+	defer span.Finish()
+
+	res, err := db.Conn(ctx, ur.DB).ExecContext(ctx, createUser,
+		input.Email,
+		input.Username,
+		input.Passwordhash,
+		input.Fullname,
+		input.Role,
+	)
+	if err != nil {
+		log.Println("[User][Create][Repository] Problem to querying to db, err: ", err.Error())
+		return db.HandleError(err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	input.ID = int(id)
+	return nil
+}
+
+// UpdateEmail moves the user with the given email to a new email
+func (ur *UserRepository) UpdateEmail(ctx context.Context, email, newEmail string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserRepository.UpdateEmail")
+	defer span.Finish()
+
+	res, err := db.Conn(ctx, ur.DB).ExecContext(ctx, updateUserEmailQuery, newEmail, email)
+	var updated int64
+	if err == nil {
+		updated, err = res.RowsAffected()
+	}
+	if err != nil {
+		log.Println("[User][UpdateEmail][Repository] Problem to querying to db, err: ", err.Error())
+		return db.HandleError(err)
+	}
+	if updated == 0 {
+		return db.ErrObjectNotFound{}
+	}
+	return nil
+}
+
+// FindByEmail retrieves the user with the given email
+func (ur *UserRepository) FindByEmail(ctx context.Context, email string) (resp models.User, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "UserRepository.FindByEmail")
+	defer span.Finish()
+
+	var username, fullname sql.NullString
+	err = db.Conn(ctx, ur.DB).QueryRowContext(ctx, getUserQuery, email).Scan(
+		&resp.ID,
+		&resp.Email,
+		&username,
+		&resp.Passwordhash,
+		&fullname,
+		&resp.Role,
+		&resp.CreateDate,
+	)
+	if err != nil {
+		log.Println("[User][FindByEmail][Repository] Problem to querying to db, err: ", err.Error())
+		return resp, db.HandleError(err)
+	}
+	resp.Username = username.String
+	resp.Fullname = fullname.String
+
+	return resp, nil
+}
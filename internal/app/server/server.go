@@ -8,10 +8,14 @@ import (
 	"github.com/rs/cors"
 	"github.com/sirupsen/logrus"
 	"github.com/whittier16/student-reg-svc/internal/app/config"
+	"github.com/whittier16/student-reg-svc/internal/app/repository"
+	"github.com/whittier16/student-reg-svc/internal/app/repository/memory"
 	"github.com/whittier16/student-reg-svc/internal/pkg/database/cache"
 	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
 	"github.com/whittier16/student-reg-svc/internal/pkg/handlers"
 	"github.com/whittier16/student-reg-svc/internal/pkg/logger"
+	"github.com/whittier16/student-reg-svc/internal/pkg/notifier"
+	"github.com/whittier16/student-reg-svc/internal/pkg/services"
 	"go.elastic.co/apm/module/apmgorilla"
 	"net/http"
 	"os"
@@ -25,6 +29,10 @@ type Server struct {
 	logger *logrus.Logger
 	router *mux.Router
 	cfg    *config.MainConfig
+	// dispatcher delivers the notification outbox, nil when no mail server is configured
+	dispatcher *notifier.Dispatcher
+	// scheduler sends the scheduled notifications once they are due
+	scheduler *notifier.Scheduler
 }
 
 // New returns a new instance of Server
@@ -33,21 +41,36 @@ func New() (*Server, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := cnf.JWT.Validate(); err != nil {
+		return nil, err
+	}
 
-	// creates a new instance of database
-	db, err := db.New(
-		cnf.Database.User,
-		cnf.Database.Pass,
-		cnf.Database.Host,
-		cnf.Database.Port,
-		cnf.Database.DBName,
-	)
+	// creates the storage backend the service runs on
+	svc, outbox, err := newService(cnf.Database)
 	if err != nil {
 		return nil, err
 	}
 
+	svc.SetActiveTerm(cnf.Term.Active)
+
+	// creates the first administrator account if configured
+	if cnf.Admin.Email != "" && cnf.Admin.Password == "" {
+		logrus.Warn("admin.password is not set, the administrator account is not created")
+	}
+	if cnf.Admin.Email != "" {
+		err = svc.EnsureAdmin(context.Background(), cnf.Admin.Email, cnf.Admin.Password)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// creates a new instance of redis
-	c := cache.NewRedis(cnf.Redis.Host, cnf.Redis.Port, cnf.Redis.Password, cnf.Redis.DB, cnf.Redis.UseTLS)
+	var c handlers.TokenStore
+	if cnf.Redis.Driver == "memory" {
+		c = cache.NewMemory()
+	} else {
+		c = cache.NewRedis(cnf.Redis.Host, cnf.Redis.Port, cnf.Redis.Password, cnf.Redis.DB, cnf.Redis.UseTLS)
+	}
 
 	// creates a new instance of a logger
 	log := logger.NewLogger()
@@ -56,32 +79,87 @@ func New() (*Server, error) {
 	router := mux.NewRouter().StrictSlash(true)
 	// instrument the application
 	apmgorilla.Instrument(router)
-	handlers.RegisterRoutes(router, log, db, c, cnf)
+	handlers.RegisterRoutes(router, log, svc, c, cnf)
 
 	s := &Server{
-		logger: log,
-		cfg:    cnf,
-		router: router,
+		logger:    log,
+		cfg:       cnf,
+		router:    router,
+		scheduler: notifier.NewScheduler(&svc, cnf.Scheduler, log),
+	}
+	if cnf.SMTP.Host != "" {
+		s.dispatcher = notifier.NewDispatcher(outbox, notifier.NewSMTP(cnf.SMTP), cnf.Dispatcher, log)
 	}
 	return s, nil
 }
 
+// CORS wraps the router with the CORS policy. It answers the preflight requests of every write
+// route and exposes the response headers browser clients read.
+func CORS(router http.Handler) http.Handler {
+	return cors.New(cors.Options{
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedOrigins:   []string{"*"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "Cache-Control"},
+		ExposedHeaders:   []string{"X-Total-Count"},
+		AllowCredentials: true,
+	}).Handler(router)
+}
+
+// newService returns a Service and the notification outbox backed by the database selected in the config
+func newService(cfg config.DatabaseConfig) (services.Service, notifier.Outbox, error) {
+	if cfg.Driver == "memory" {
+		store := memory.New()
+		return services.NewService(
+			memory.NewStudentRepository(store),
+			memory.NewTeacherRepository(store),
+			memory.NewRegisterRepository(store),
+			memory.NewSuspensionRepository(store),
+			memory.NewNotificationRepository(store),
+			memory.NewTemplateRepository(store),
+			memory.NewScheduleRepository(store),
+			memory.NewPreferenceRepository(store),
+			memory.NewClassRepository(store),
+			memory.NewTermRepository(store),
+			memory.NewUserRepository(store),
+			memory.NewUnitOfWork(store),
+		), memory.NewOutboxRepository(store), nil
+	}
+
+	// creates a new instance of database
+	mysql, err := db.New(
+		cfg.User,
+		cfg.Pass,
+		cfg.Host,
+		cfg.Port,
+		cfg.DBName,
+	)
+	if err != nil {
+		return services.Service{}, nil, err
+	}
+	return services.NewService(
+		repository.NewStudentRepository(mysql),
+		repository.NewTeacherRepository(mysql),
+		repository.NewRegisterRepository(mysql),
+		repository.NewSuspensionRepository(mysql),
+		repository.NewNotificationRepository(mysql),
+		repository.NewTemplateRepository(mysql),
+		repository.NewScheduleRepository(mysql),
+		repository.NewPreferenceRepository(mysql),
+		repository.NewClassRepository(mysql),
+		repository.NewTermRepository(mysql),
+		repository.NewUserRepository(mysql),
+		db.NewUnitOfWork(mysql),
+	), repository.NewOutboxRepository(mysql), nil
+}
+
 // Start starts the API server
 func (s *Server) Start(ctx context.Context) error {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Start")
 	defer span.Finish()
 
-	// setup CORS
-	cors := cors.New(cors.Options{
-		AllowedMethods:   []string{"GET", "POST", "OPTIONS"},
-		AllowedOrigins:   []string{"*"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "Cache-Control"},
-		AllowCredentials: true,
-	}).Handler(s.router)
-
 	server := http.Server{
 		Addr:    fmt.Sprintf(":%d", s.cfg.Server.Address),
-		Handler: cors,
+		Handler: CORS(s.router),
 	}
 	stopServer := make(chan os.Signal, 1)
 	signal.Notify(stopServer, syscall.SIGINT, syscall.SIGTERM)
@@ -91,6 +169,24 @@ func (s *Server) Start(ctx context.Context) error {
 	// channel to listen for errors coming from the listener.
 	serverErrors := make(chan error, 1)
 	var wg sync.WaitGroup
+
+	// sends the scheduled notifications and delivers the notification outbox until the server is shut down
+	dispatchCtx, stopDispatcher := context.WithCancel(context.Background())
+	defer stopDispatcher()
+	wg.Add(1)
+	go func(wg *sync.WaitGroup) {
+		defer wg.Done()
+		s.scheduler.Run(dispatchCtx)
+	}(&wg)
+	if s.dispatcher != nil {
+		wg.Add(1)
+		go func(wg *sync.WaitGroup) {
+			defer wg.Done()
+			s.logger.Printf("notification dispatcher delivering through %s", s.cfg.SMTP.Host)
+			s.dispatcher.Run(dispatchCtx)
+		}(&wg)
+	}
+
 	wg.Add(1)
 	go func(wg *sync.WaitGroup) {
 		defer wg.Done()
@@ -106,6 +202,7 @@ func (s *Server) Start(ctx context.Context) error {
 		s.logger.Warn("server received STOP signal")
 		// asking listener to shut down
 		err := server.Shutdown(ctx)
+		stopDispatcher()
 		if err != nil {
 			return fmt.Errorf("graceful shutdown did not complete: %w", err)
 		}
@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory keeps the token state of Redis in memory. It backs unit tests and local
+// demos where Redis is not available.
+type Memory struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	value     string
+	expiresAt time.Time
+}
+
+// NewMemory gets new instance of the in-memory cache
+func NewMemory() *Memory {
+	return &Memory{entries: map[string]memoryEntry{}}
+}
+
+// get returns the value of key if it is set and not expired. The caller must hold mu.
+func (m *Memory) get(key string) (memoryEntry, bool) {
+	e, ok := m.entries[key]
+	if ok && time.Now().After(e.expiresAt) {
+		delete(m.entries, key)
+		return memoryEntry{}, false
+	}
+	return e, ok
+}
+
+// set stores value under key for ttl. The caller must hold mu.
+func (m *Memory) set(key, value string, ttl time.Duration) {
+	m.entries[key] = memoryEntry{value: value, expiresAt: time.Now().Add(ttl)}
+}
+
+// exists reports whether key is set and not expired
+func (m *Memory) exists(key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.get(key)
+	return ok
+}
+
+// SaveRefreshToken records the refresh token jti as a member of family until it expires
+func (m *Memory) SaveRefreshToken(ctx context.Context, jti, family string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.set(refreshKeyPrefix+jti, family, ttl)
+	return nil
+}
+
+// UseRefreshToken marks the refresh token jti as exchanged and returns its family. reused
+// reports whether the token had already been exchanged before, which means it was stolen.
+func (m *Memory) UseRefreshToken(ctx context.Context, jti string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.get(refreshKeyPrefix + jti)
+	if !ok {
+		return "", false, ErrTokenNotFound
+	}
+	if _, used := m.get(usedRefreshKeyPrefix + jti); used {
+		return e.value, true, nil
+	}
+	m.set(usedRefreshKeyPrefix+jti, "1", time.Until(e.expiresAt))
+	return e.value, false, nil
+}
+
+// RevokeFamily revokes every access and refresh token issued to family
+func (m *Memory) RevokeFamily(ctx context.Context, family string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.set(revokedFamilyKeyPrefix+family, "1", ttl)
+	return nil
+}
+
+// IsFamilyRevoked reports whether RevokeFamily was called for family
+func (m *Memory) IsFamilyRevoked(ctx context.Context, family string) (bool, error) {
+	return m.exists(revokedFamilyKeyPrefix + family), nil
+}
+
+// RevokeToken revokes the access token jti until it expires
+func (m *Memory) RevokeToken(ctx context.Context, jti string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.set(revokedTokenKeyPrefix+jti, "1", ttl)
+	return nil
+}
+
+// IsTokenRevoked reports whether RevokeToken was called for jti
+func (m *Memory) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	return m.exists(revokedTokenKeyPrefix + jti), nil
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"github.com/go-redis/redis/v8"
+	"time"
+)
+
+const (
+	// refreshKeyPrefix keys the family of every issued refresh token
+	refreshKeyPrefix = "refresh:"
+	// usedRefreshKeyPrefix marks refresh tokens that were already exchanged
+	usedRefreshKeyPrefix = "refresh_used:"
+	// revokedFamilyKeyPrefix marks token families revoked on logout or refresh token reuse
+	revokedFamilyKeyPrefix = "revoked_family:"
+	// revokedTokenKeyPrefix marks revoked access tokens by jti
+	revokedTokenKeyPrefix = "revoked_token:"
+)
+
+// ErrTokenNotFound is returned when a refresh token was never issued or already expired
+var ErrTokenNotFound = errors.New("token not found")
+
+// SaveRefreshToken records the refresh token jti as a member of family until it expires
+func (r *Redis) SaveRefreshToken(ctx context.Context, jti, family string, ttl time.Duration) error {
+	return r.Source.Set(ctx, refreshKeyPrefix+jti, family, ttl).Err()
+}
+
+// UseRefreshToken marks the refresh token jti as exchanged and returns its family. reused
+// reports whether the token had already been exchanged before, which means it was stolen.
+func (r *Redis) UseRefreshToken(ctx context.Context, jti string) (family string, reused bool, err error) {
+	family, err = r.Source.Get(ctx, refreshKeyPrefix+jti).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, ErrTokenNotFound
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	ttl, err := r.Source.TTL(ctx, refreshKeyPrefix+jti).Result()
+	if err != nil {
+		return "", false, err
+	}
+	if ttl <= 0 {
+		ttl = time.Minute
+	}
+	// SETNX makes concurrent exchanges of the same token detect each other
+	ok, err := r.Source.SetNX(ctx, usedRefreshKeyPrefix+jti, 1, ttl).Result()
+	if err != nil {
+		return "", false, err
+	}
+	return family, !ok, nil
+}
+
+// RevokeFamily revokes every access and refresh token issued to family
+func (r *Redis) RevokeFamily(ctx context.Context, family string, ttl time.Duration) error {
+	return r.Source.Set(ctx, revokedFamilyKeyPrefix+family, 1, ttl).Err()
+}
+
+// IsFamilyRevoked reports whether RevokeFamily was called for family
+func (r *Redis) IsFamilyRevoked(ctx context.Context, family string) (bool, error) {
+	n, err := r.Source.Exists(ctx, revokedFamilyKeyPrefix+family).Result()
+	return n > 0, err
+}
+
+// RevokeToken revokes the access token jti until it expires
+func (r *Redis) RevokeToken(ctx context.Context, jti string, ttl time.Duration) error {
+	return r.Source.Set(ctx, revokedTokenKeyPrefix+jti, 1, ttl).Err()
+}
+
+// IsTokenRevoked reports whether RevokeToken was called for jti
+func (r *Redis) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	n, err := r.Source.Exists(ctx, revokedTokenKeyPrefix+jti).Result()
+	return n > 0, err
+}
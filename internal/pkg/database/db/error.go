@@ -4,12 +4,30 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"github.com/go-sql-driver/mysql"
+)
+
+const (
+	// mysqlErrDupEntry is returned by MySQL when a UNIQUE or PRIMARY KEY constraint is violated
+	mysqlErrDupEntry = 1062
+	// mysqlErrNoReferencedRow is returned by MySQL when a FOREIGN KEY constraint fails on insert/update
+	mysqlErrNoReferencedRow = 1452
 )
 
 func HandleError(err error) error {
 	if errors.Is(err, sql.ErrNoRows) {
 		return ErrObjectNotFound{}
 	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case mysqlErrDupEntry:
+			return ErrDuplicateObject{}
+		case mysqlErrNoReferencedRow:
+			return ErrReferenceNotFound{}
+		}
+	}
 	return err
 }
 
@@ -23,3 +41,19 @@ func (ErrObjectNotFound) Error() string {
 func (ErrObjectNotFound) Unwrap() error {
 	return fmt.Errorf("object not found")
 }
+
+// ErrDuplicateObject is used to indicate that inserting an object violated
+// a unique constraint.
+type ErrDuplicateObject struct{}
+
+func (ErrDuplicateObject) Error() string {
+	return "object already exists"
+}
+
+// ErrReferenceNotFound is used to indicate that inserting an object failed
+// because a referenced object does not exist.
+type ErrReferenceNotFound struct{}
+
+func (ErrReferenceNotFound) Error() string {
+	return "referenced object not found"
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	log "github.com/sirupsen/logrus"
+)
+
+// Querier is the subset of *sql.DB and *sql.Tx used by the repositories, so the
+// same repository method can run either on its own or as part of a transaction.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// txKey is the context key under which the active transaction is stored
+type txKey struct{}
+
+// Conn returns the transaction carried by ctx, or fallback when ctx has none
+func Conn(ctx context.Context, fallback *sql.DB) Querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return fallback
+}
+
+// UnitOfWork runs a group of repository calls in a single MySQL transaction
+type UnitOfWork struct {
+	DB *sql.DB
+}
+
+// NewUnitOfWork an instance of the UnitOfWork.
+func NewUnitOfWork(db *MySQL) *UnitOfWork {
+	return &UnitOfWork{DB: db.DBClient}
+}
+
+// Do begins a transaction, passes it to fn through the context and commits it when fn
+// succeeds. The transaction is rolled back if fn returns an error or panics. Calls made
+// with a context that already carries a transaction join that transaction instead.
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := u.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	// Defer a rollback in case anything fails.
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.Println("[UnitOfWork][Do] Problem to rolling back transaction, err: ", rbErr.Error())
+			}
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"net/http"
+	"strings"
+)
+
+// Policy decides whether the principal may perform the request. It returns a
+// non-nil error describing the violation when the request must be rejected.
+type Policy func(r *http.Request, p Principal) error
+
+// authorize wraps next so it only runs when the principal satisfies every policy.
+// Administrators pass every policy. It must run behind AuthMiddleware.
+func (h *Handler) authorize(next http.HandlerFunc, policies ...Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			h.respondWithError(w, r, "unauthorized", "Not Authorized", http.StatusUnauthorized)
+			return
+		}
+
+		if p.Role != models.RoleAdmin {
+			for _, policy := range policies {
+				if err := policy(r, p); err != nil {
+					h.respondWithError(w, r, "forbidden", err.Error(), http.StatusForbidden)
+					return
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+// allowRoles only lets the given roles through. Administrators are always allowed.
+func allowRoles(roles ...models.Role) Policy {
+	return func(r *http.Request, p Principal) error {
+		names := []string{}
+		for _, role := range roles {
+			if p.Role == role {
+				return nil
+			}
+			names = append(names, role.String())
+		}
+		return fmt.Errorf("only %s may perform this operation", strings.Join(append(names, models.RoleAdmin.String()), ", "))
+	}
+}
+
+// selfBodyField requires the JSON body field to be the principal's own email when the
+// principal has the given role, e.g. a teacher may only register students to themselves.
+func (h *Handler) selfBodyField(role models.Role, field string) Policy {
+	return func(r *http.Request, p Principal) error {
+		if p.Role != role {
+			return nil
+		}
+
+		body, err := h.readRequestBody(r)
+		if err != nil {
+			return err
+		}
+		h.restoreRequestBody(r, body)
+
+		// decode the same way Handler.decode does, so the policy sees the values the handler will use
+		fields := map[string]interface{}{}
+		if err := json.NewDecoder(bytes.NewReader(body)).Decode(&fields); err != nil {
+			// let the handler report the malformed body
+			return nil
+		}
+
+		// encoding/json matches struct fields case-insensitively, so every spelling of the
+		// field must hold the principal's email. Emails are compared case-insensitively
+		// like the database collation does.
+		found := false
+		for k, v := range fields {
+			if !strings.EqualFold(k, field) {
+				continue
+			}
+			found = true
+			if email, _ := v.(string); !strings.EqualFold(email, p.Email) {
+				return fmt.Errorf("a %s may only use their own email as %s", role, field)
+			}
+		}
+		if !found {
+			return fmt.Errorf("a %s may only use their own email as %s", role, field)
+		}
+		return nil
+	}
+}
+
+// selfPathVar requires the route variable to be the principal's own email when the
+// principal has the given role, e.g. a student may only read their own record.
+func selfPathVar(role models.Role, name string) Policy {
+	return func(r *http.Request, p Principal) error {
+		if p.Role != role {
+			return nil
+		}
+		if !strings.EqualFold(mux.Vars(r)[name], p.Email) {
+			return fmt.Errorf("a %s may only access their own %s", role, name)
+		}
+		return nil
+	}
+}
+
+// selfQueryParam requires the query parameter to be the principal's own email when the
+// principal has the given role, e.g. a teacher may only browse their own notifications.
+func selfQueryParam(role models.Role, name string) Policy {
+	return func(r *http.Request, p Principal) error {
+		if p.Role != role {
+			return nil
+		}
+		if !strings.EqualFold(r.URL.Query().Get(name), p.Email) {
+			return fmt.Errorf("a %s may only use their own email as %s", role, name)
+		}
+		return nil
+	}
+}
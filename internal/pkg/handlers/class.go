@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/services"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// classResponse is a class of a teacher
+type classResponse struct {
+	ID        int        `json:"id"`
+	Teacher   string     `json:"teacher"`
+	Code      string     `json:"code"`
+	Title     string     `json:"title"`
+	Term      string     `json:"term"`
+	CreatedOn time.Time  `json:"created_on"`
+	UpdatedOn *time.Time `json:"updated_on,omitempty"`
+}
+
+func newClassResponse(c models.Class) classResponse {
+	return classResponse{
+		ID:        c.ID,
+		Teacher:   c.TeacherID,
+		Code:      c.Code,
+		Title:     c.Title,
+		Term:      c.Term,
+		CreatedOn: c.CreatedOn,
+		UpdatedOn: c.UpdatedOn,
+	}
+}
+
+// classID reads the id route variable, which the route restricts to digits
+func classID(r *http.Request) int {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	return id
+}
+
+// ListClasses handles "GET /api/teachers/{email}/classes"
+// Lists the classes of a teacher ordered by term and code, including the default class holding
+// the students registered to the teacher directly.
+// ---
+// Responses:
+//
+//	200:
+//	401:
+//	403:
+//	404:
+//	500:
+func (h *Handler) ListClasses() http.HandlerFunc {
+	type response struct {
+		Classes []classResponse `json:"classes"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := h.svc.ListClasses(r.Context(), mux.Vars(r)["email"])
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		classes := make([]classResponse, 0, len(res))
+		for _, c := range res {
+			classes = append(classes, newClassResponse(c))
+		}
+		h.response(w, response{Classes: classes}, http.StatusOK)
+	}
+}
+
+// CreateClass handles "POST /api/teachers/{email}/classes"
+// Adds a class to a teacher. The code of a class is unique per term.
+// ---
+// Responses:
+//
+//	201:
+//	400:
+//	401:
+//	403:
+//	404:
+//	409:
+//	422:
+//	500:
+func (h *Handler) CreateClass() http.HandlerFunc {
+	type request struct {
+		Code  string `json:"code"`
+		Title string `json:"title"`
+		Term  string `json:"term"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := request{}
+		// Try to decode the request body into the struct. If there is an error,
+		// respond to the client with the error message and a 400 status code.
+		err := h.decode(r, &req)
+		if err != nil {
+			h.respondWithError(w, r, "invalid_request_body", err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		res, err := h.svc.CreateClass(r.Context(), services.CreateClassParams{
+			Teacher: mux.Vars(r)["email"],
+			Code:    req.Code,
+			Title:   req.Title,
+			Term:    req.Term,
+		})
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		h.response(w, newClassResponse(res), http.StatusCreated)
+	}
+}
+
+// GetClass handles "GET /api/teachers/{email}/classes/{id}"
+// Retrieves a class of a teacher with the students enrolled to it who are not suspended.
+// ---
+// Responses:
+//
+//	200:
+//	401:
+//	403:
+//	404:
+//	500:
+func (h *Handler) GetClass() http.HandlerFunc {
+	type response struct {
+		classResponse
+		Students []string `json:"students"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		c, students, err := h.svc.GetClass(r.Context(), mux.Vars(r)["email"], classID(r))
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		h.response(w, response{
+			classResponse: newClassResponse(c),
+			Students:      append([]string{}, students...),
+		}, http.StatusOK)
+	}
+}
+
+// EnrollStudents handles "POST /api/teachers/{email}/classes/{id}/students"
+// Enrolls students to a class of a teacher. Either every student is enrolled or none; students
+// that are already enrolled are left as they are.
+// ---
+// Responses:
+//
+//	204:
+//	400:
+//	401:
+//	403:
+//	404:
+//	422:
+//	500:
+func (h *Handler) EnrollStudents() http.HandlerFunc {
+	type request struct {
+		Students []string `json:"students"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := request{}
+		// Try to decode the request body into the struct. If there is an error,
+		// respond to the client with the error message and a 400 status code.
+		err := h.decode(r, &req)
+		if err != nil {
+			h.respondWithError(w, r, "invalid_request_body", err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		err = h.svc.EnrollStudents(r.Context(), mux.Vars(r)["email"], classID(r), services.EnrollStudentsParams{
+			Students: req.Students,
+		})
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		h.response(w, "", http.StatusNoContent)
+	}
+}
+
+// UnenrollStudents handles "DELETE /api/teachers/{email}/classes/{id}/students"
+// Removes students from a class of a teacher. Students that are not enrolled are skipped, the
+// response lists the students that were unenrolled.
+// ---
+// Responses:
+//
+//	200:
+//	400:
+//	401:
+//	403:
+//	404:
+//	422:
+//	500:
+func (h *Handler) UnenrollStudents() http.HandlerFunc {
+	type request struct {
+		Students []string `json:"students"`
+	}
+	type response struct {
+		Unenrolled []string `json:"unenrolled"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := request{}
+		// Try to decode the request body into the struct. If there is an error,
+		// respond to the client with the error message and a 400 status code.
+		err := h.decode(r, &req)
+		if err != nil {
+			h.respondWithError(w, r, "invalid_request_body", err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		res, err := h.svc.UnenrollStudents(r.Context(), mux.Vars(r)["email"], classID(r), services.EnrollStudentsParams{
+			Students: req.Students,
+		})
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		h.response(w, response{
+			Unenrolled: res,
+		}, http.StatusOK)
+	}
+}
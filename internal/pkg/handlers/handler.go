@@ -1,13 +1,16 @@
 package handlers
 
 import (
+	"errors"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	"github.com/whittier16/student-reg-svc/internal/app/config"
-	"github.com/whittier16/student-reg-svc/internal/app/repository"
-	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/cache"
 	"github.com/whittier16/student-reg-svc/internal/pkg/services"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 // Handler handles API requests
@@ -15,59 +18,342 @@ type Handler struct {
 	logger *logrus.Logger
 	router *mux.Router
 	svc    services.Service
+	tokens TokenStore
+	keys   keySet
 	cfg    *config.MainConfig
 }
 
 // New returns a new instance of the Handler
-func New(log *logrus.Logger, db *db.MySQL, cfg *config.MainConfig) *Handler {
+func New(log *logrus.Logger, svc services.Service, tokens TokenStore, cfg *config.MainConfig) *Handler {
 	return &Handler{
 		logger: log,
-		svc: services.NewService(
-			repository.NewStudentRepository(db),
-			repository.NewTeacherRepository(db),
-			repository.NewRegisterRepository(db),
-		),
-		cfg: cfg,
+		svc:    svc,
+		tokens: tokens,
+		keys:   newKeySet(cfg.JWT, log),
+		cfg:    cfg,
 	}
 }
 
-// Auth handles "POST /auth"
-// Generates a new token.
+// Login handles "POST /auth/login"
+// Verifies the user credentials and generates a new access and refresh token.
 // ---
 // Responses:
 //
-//	204:
+//	200:
+//	400:
 //	401:
-func (h *Handler) Auth() http.HandlerFunc {
+//	422:
+//	500:
+func (h *Handler) Login() http.HandlerFunc {
+	type request struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+
 	return func(w http.ResponseWriter, r *http.Request) {
+		req := request{}
+		// Try to decode the request body into the struct. If there is an error,
+		// respond to the client with the error message and a 400 status code.
+		err := h.decode(r, &req)
+		if err != nil {
+			h.respondWithError(w, r, "invalid_request_body", err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		user, err := h.svc.Login(r.Context(), services.LoginParams{
+			Email:    req.Email,
+			Password: req.Password,
+		})
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		// every login starts a new token family
+		family, err := newTokenID()
+		if err != nil {
+			h.respondWithServiceError(w, r, services.Internal(err))
+			return
+		}
+		tokens, err := h.issueTokens(r.Context(), user, family)
+		if err != nil {
+			h.respondWithServiceError(w, r, services.Internal(err))
+			return
+		}
+
+		w.Header().Set("Token", tokens.Token)
+		h.response(w, tokens, http.StatusOK)
+	}
+}
+
+// Refresh handles "POST /auth/refresh"
+// Exchanges a refresh token for a new access and refresh token. Refresh tokens can only be
+// used once; presenting one again revokes every token of its family.
+// ---
+// Responses:
+//
+//	200:
+//	400:
+//	401:
+//	500:
+func (h *Handler) Refresh() http.HandlerFunc {
+	type request struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := request{}
+		// Try to decode the request body into the struct. If there is an error,
+		// respond to the client with the error message and a 400 status code.
+		err := h.decode(r, &req)
+		if err != nil {
+			h.respondWithError(w, r, "invalid_request_body", err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		jti, family, email, err := h.parseRefreshToken(req.RefreshToken)
+		if err != nil {
+			h.respondWithError(w, r, "invalid_refresh_token", err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		usedFamily, reused, err := h.tokens.UseRefreshToken(r.Context(), jti)
+		switch {
+		case errors.Is(err, cache.ErrTokenNotFound) || (err == nil && usedFamily != family):
+			h.respondWithError(w, r, "invalid_refresh_token", "refresh token is not valid", http.StatusUnauthorized)
+			return
+		case err != nil:
+			h.respondWithServiceError(w, r, services.Internal(err))
+			return
+		case reused:
+			// the token was already exchanged, so someone else holds a copy of it
+			if err := h.tokens.RevokeFamily(r.Context(), family, h.refreshTokenTTL()); err != nil {
+				h.respondWithServiceError(w, r, services.Internal(err))
+				return
+			}
+			h.respondWithError(w, r, "refresh_token_reused", "refresh token was already used, please log in again", http.StatusUnauthorized)
+			return
+		}
+
+		revoked, err := h.tokens.IsFamilyRevoked(r.Context(), family)
+		if err != nil {
+			h.respondWithServiceError(w, r, services.Internal(err))
+			return
+		}
+		if revoked {
+			h.respondWithError(w, r, "token_revoked", "refresh token was revoked, please log in again", http.StatusUnauthorized)
+			return
+		}
 
-		token, err := h.generateJWT()
+		// reload the user so role changes apply to the new tokens
+		user, err := h.svc.GetUser(r.Context(), email)
 		if err != nil {
-			h.respondWithError(w, err.Error(), http.StatusUnauthorized)
+			h.respondWithError(w, r, "invalid_refresh_token", "refresh token is not valid", http.StatusUnauthorized)
+			return
+		}
+		tokens, err := h.issueTokens(r.Context(), user, family)
+		if err != nil {
+			h.respondWithServiceError(w, r, services.Internal(err))
+			return
+		}
+
+		w.Header().Set("Token", tokens.Token)
+		h.response(w, tokens, http.StatusOK)
+	}
+}
+
+// Logout handles "POST /auth/logout"
+// Revokes the access token of the request and every token of its family.
+// ---
+// Responses:
+//
+//	204:
+//	401:
+//	500:
+func (h *Handler) Logout() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			h.respondWithError(w, r, "unauthorized", "Not Authorized", http.StatusUnauthorized)
 			return
 		}
 
-		w.Header().Set("Token", token)
-		w.Header().Set("Content-Type", "application/json")
+		var err error
+		if p.TokenID != "" {
+			err = h.tokens.RevokeToken(r.Context(), p.TokenID, time.Until(p.ExpiresAt))
+		}
+		if err == nil && p.Family != "" {
+			err = h.tokens.RevokeFamily(r.Context(), p.Family, h.refreshTokenTTL())
+		}
+		if err != nil {
+			h.respondWithServiceError(w, r, services.Internal(err))
+			return
+		}
 
 		h.response(w, "", http.StatusNoContent)
 	}
 }
 
+// CreateUser handles "POST /api/users"
+// Adds a user account. Only administrators may create users.
+// ---
+// Responses:
+//
+//	201:
+//	400:
+//	401:
+//	403:
+//	409:
+//	422:
+//	500:
+func (h *Handler) CreateUser() http.HandlerFunc {
+	type request struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+		Username string `json:"username"`
+		Fullname string `json:"fullname"`
+		Role     string `json:"role"`
+	}
+	type response struct {
+		ID       int    `json:"id"`
+		Email    string `json:"email"`
+		Username string `json:"username"`
+		Fullname string `json:"fullname"`
+		Role     string `json:"role"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := request{}
+		// Try to decode the request body into the struct. If there is an error,
+		// respond to the client with the error message and a 400 status code.
+		err := h.decode(r, &req)
+		if err != nil {
+			h.respondWithError(w, r, "invalid_request_body", err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		user, err := h.svc.CreateUser(r.Context(), services.CreateUserParams{
+			Email:    req.Email,
+			Password: req.Password,
+			Username: req.Username,
+			Fullname: req.Fullname,
+			Role:     req.Role,
+		})
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		h.response(w, response{
+			ID:       user.ID,
+			Email:    user.Email,
+			Username: user.Username,
+			Fullname: user.Fullname,
+			Role:     user.Role.String(),
+		}, http.StatusCreated)
+	}
+}
+
 // Register handles "POST /api/register"
-// Registers one or more students to a specified teacher.
+// Registers one or more students to a specified teacher. The default "strict" mode registers
+// every student or none. The "upsert" mode skips existing registrations and "partial" registers
+// what it can; both respond with the outcome for each student, "partial" with 207 when some
+// students could not be registered. With "create_missing" unknown students are created, named
+// after "names", and listed in "created".
 // ---
 // Responses:
 //
+//	200:
 //	204:
+//	207:
 //	400:
 //	401:
+//	404:
+//	409:
 //	422:
+//	500:
 func (h *Handler) Register() http.HandlerFunc {
+	type request struct {
+		TeacherEmail  string            `json:"teacher"`
+		StudentEmails []string          `json:"students"`
+		Mode          string            `json:"mode"`
+		CreateMissing bool              `json:"create_missing"`
+		Names         map[string]string `json:"names"`
+	}
+	type result struct {
+		Student string `json:"student"`
+		Status  string `json:"status"`
+	}
+	type response struct {
+		Results []result `json:"results"`
+		Created []string `json:"created,omitempty"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := request{}
+		// Try to decode the request body into the struct. If there is an error,
+		// respond to the client with the error message and a 400 status code.
+		err := h.decode(r, &req)
+		if err != nil {
+			h.respondWithError(w, r, "invalid_request_body", err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		res, err := h.svc.Register(r.Context(), services.RegisterStudentsParams{
+			TeacherEmail:  req.TeacherEmail,
+			StudentEmails: req.StudentEmails,
+			Mode:          req.Mode,
+			CreateMissing: req.CreateMissing,
+			Names:         req.Names,
+		})
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		if (req.Mode == "" || req.Mode == services.RegisterStrict) && !req.CreateMissing {
+			h.response(w, "", http.StatusNoContent)
+			return
+		}
+
+		status := http.StatusOK
+		results := make([]result, 0, len(res))
+		var created []string
+		for _, rr := range res {
+			if rr.Status == services.StatusNotFound || rr.Status == services.StatusSuspended {
+				status = http.StatusMultiStatus
+			}
+			if rr.Created {
+				created = append(created, rr.Student)
+			}
+			results = append(results, result{Student: rr.Student, Status: rr.Status})
+		}
+		h.response(w, response{
+			Results: results,
+			Created: created,
+		}, status)
+	}
+}
+
+// Unregister handles "DELETE /api/register"
+// Removes the registrations of one or more students to a specified teacher.
+// ---
+// Responses:
+//
+//	200:
+//	400:
+//	401:
+//	404:
+//	422:
+//	500:
+func (h *Handler) Unregister() http.HandlerFunc {
 	type request struct {
 		TeacherEmail  string   `json:"teacher"`
 		StudentEmails []string `json:"students"`
 	}
+	type response struct {
+		Unregistered []string `json:"unregistered"`
+	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
 		req := request{}
@@ -75,32 +361,35 @@ func (h *Handler) Register() http.HandlerFunc {
 		// respond to the client with the error message and a 400 status code.
 		err := h.decode(r, &req)
 		if err != nil {
-			h.respondWithError(w, err.Error(), http.StatusBadRequest)
+			h.respondWithError(w, r, "invalid_request_body", err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		err = h.svc.Register(r.Context(), services.RegisterStudentsParams{
+		res, err := h.svc.Unregister(r.Context(), services.UnregisterStudentsParams{
 			TeacherEmail:  req.TeacherEmail,
 			StudentEmails: req.StudentEmails,
 		})
 		if err != nil {
-			h.respondWithError(w, err.Error(), http.StatusUnprocessableEntity)
+			h.respondWithServiceError(w, r, err)
 			return
 		}
 
-		h.response(w, "", http.StatusNoContent)
+		h.response(w, response{
+			Unregistered: res,
+		}, http.StatusOK)
 	}
 }
 
 // GetCommonStudents handles "GET /api/commonstudents"
-// Gets list of common students to a given list of teachers.
+// Gets list of common students to a given list of teachers, across all of their classes in the
+// active term or the given term, and of classes given by id.
 // ---
 // Responses:
 //
 //	200:
-//	400:
 //	401:
 //	422:
+//	500:
 func (h *Handler) GetCommonStudents() http.HandlerFunc {
 	type response struct {
 		Students []string `json:"students"`
@@ -108,8 +397,9 @@ func (h *Handler) GetCommonStudents() http.HandlerFunc {
 
 	return func(w http.ResponseWriter, r *http.Request) {
 		tq := r.URL.Query()["teacher"]
-		if len(tq) == 0 {
-			h.respondWithError(w, "missing required query params", http.StatusUnprocessableEntity)
+		cq := r.URL.Query()["class"]
+		if len(tq) == 0 && len(cq) == 0 {
+			h.respondWithError(w, r, "validation_failed", "missing required query params", http.StatusUnprocessableEntity)
 			return
 		}
 
@@ -119,9 +409,11 @@ func (h *Handler) GetCommonStudents() http.HandlerFunc {
 		}
 		res, err := h.svc.GetCommonStudents(r.Context(), services.GetCommonStudentsParams{
 			Teacher: emails,
+			Class:   cq,
+			Term:    r.URL.Query().Get("term"),
 		})
 		if err != nil {
-			h.respondWithError(w, err.Error(), http.StatusBadRequest)
+			h.respondWithServiceError(w, r, err)
 			return
 		}
 
@@ -131,17 +423,26 @@ func (h *Handler) GetCommonStudents() http.HandlerFunc {
 	}
 }
 
-// Suspend handles "GET /api/suspend"
-// Suspends a student.
+// Suspend handles "POST /api/suspend"
+// Suspends a student for the given reason, until the suspension is lifted or its end date passes.
+// With a teacher the student is only suspended from the teacher's class. Teachers may only suspend
+// from their own class; global suspensions are left to administrators.
 // ---
 // Responses:
 //
 //	204:
 //	400:
 //	401:
+//	404:
+//	409:
+//	422:
+//	500:
 func (h *Handler) Suspend() http.HandlerFunc {
 	type request struct {
 		Student string `json:"student"`
+		Teacher string `json:"teacher"`
+		Reason  string `json:"reason"`
+		Until   string `json:"until"`
 	}
 	return func(w http.ResponseWriter, r *http.Request) {
 		req := request{}
@@ -149,15 +450,20 @@ func (h *Handler) Suspend() http.HandlerFunc {
 		// respond to the client with the error message and a 400 status code.
 		err := h.decode(r, &req)
 		if err != nil {
-			h.respondWithError(w, err.Error(), http.StatusBadRequest)
+			h.respondWithError(w, r, "invalid_request_body", err.Error(), http.StatusBadRequest)
 			return
 		}
 
+		p, _ := PrincipalFromContext(r.Context())
 		err = h.svc.Suspend(r.Context(), services.SuspendStudentsParams{
 			Student: req.Student,
+			Teacher: req.Teacher,
+			Reason:  req.Reason,
+			Until:   req.Until,
+			By:      p.Email,
 		})
 		if err != nil {
-			h.respondWithError(w, err.Error(), http.StatusBadRequest)
+			h.respondWithServiceError(w, r, err)
 			return
 		}
 
@@ -165,21 +471,130 @@ func (h *Handler) Suspend() http.HandlerFunc {
 	}
 }
 
+// Unsuspend handles "POST /api/unsuspend"
+// Lifts the suspensions of a student. With a teacher only the suspensions from the teacher's class
+// are lifted; teachers may only lift those of their own class.
+// ---
+// Responses:
+//
+//	204:
+//	400:
+//	401:
+//	404:
+//	409:
+//	422:
+//	500:
+func (h *Handler) Unsuspend() http.HandlerFunc {
+	type request struct {
+		Student string `json:"student"`
+		Teacher string `json:"teacher"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := request{}
+		// Try to decode the request body into the struct. If there is an error,
+		// respond to the client with the error message and a 400 status code.
+		err := h.decode(r, &req)
+		if err != nil {
+			h.respondWithError(w, r, "invalid_request_body", err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		p, _ := PrincipalFromContext(r.Context())
+		err = h.svc.Unsuspend(r.Context(), services.UnsuspendStudentParams{
+			Student: req.Student,
+			Teacher: req.Teacher,
+			By:      p.Email,
+		})
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		h.response(w, "", http.StatusNoContent)
+	}
+}
+
+// GetSuspensions handles "GET /api/students/{email}/suspensions"
+// Retrieves the suspension history of a student, latest first.
+// ---
+// Responses:
+//
+//	200:
+//	401:
+//	403:
+//	404:
+//	500:
+func (h *Handler) GetSuspensions() http.HandlerFunc {
+	type suspension struct {
+		Teacher     *string    `json:"teacher,omitempty"`
+		Reason      string     `json:"reason"`
+		SuspendedBy string     `json:"suspended_by,omitempty"`
+		SuspendedOn time.Time  `json:"suspended_on"`
+		EndsOn      *time.Time `json:"ends_on,omitempty"`
+		LiftedOn    *time.Time `json:"lifted_on,omitempty"`
+		LiftedBy    *string    `json:"lifted_by,omitempty"`
+		Active      bool       `json:"active"`
+	}
+	type response struct {
+		Suspensions []suspension `json:"suspensions"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := h.svc.GetSuspensions(r.Context(), mux.Vars(r)["email"])
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		now := time.Now()
+		suspensions := make([]suspension, 0, len(res))
+		for _, s := range res {
+			suspensions = append(suspensions, suspension{
+				Teacher:     s.TeacherID,
+				Reason:      s.Reason,
+				SuspendedBy: s.SuspendedBy,
+				SuspendedOn: s.SuspendedOn,
+				EndsOn:      s.EndsOn,
+				LiftedOn:    s.LiftedOn,
+				LiftedBy:    s.LiftedBy,
+				Active:      s.Active(now),
+			})
+		}
+		h.response(w, response{
+			Suspensions: suspensions,
+		}, http.StatusOK)
+	}
+}
+
 // RetrieveNotifications handles "GET /api/retrievenotifications"
-// Retrieves list of students who can receive a given notification.
+// Retrieves list of students who can receive a given notification, and the mentioned addresses
+// that do not belong to any student. A notification sent from a template of the teacher also
+// returns the message rendered for each recipient. With a class only the students of that class
+// of the teacher receive the notification, besides the mentioned students. A notification with a
+// send_at date is scheduled instead, its recipients are resolved when it is sent.
 // ---
 // Responses:
 //
 //	200:
+//	202:
 //	400:
 //	401:
+//	404:
+//	422:
+//	500:
 func (h *Handler) RetrieveNotifications() http.HandlerFunc {
 	type request struct {
-		Teacher      string `json:"teacher"`
-		Notification string `json:"notification"`
+		Teacher      string            `json:"teacher"`
+		Notification string            `json:"notification"`
+		TemplateID   int               `json:"template_id"`
+		Variables    map[string]string `json:"variables"`
+		SendAt       string            `json:"send_at"`
+		Class        int               `json:"class"`
 	}
 	type response struct {
-		Recipients []string `json:"recipients"`
+		Recipients      []string          `json:"recipients"`
+		UnknownMentions []string          `json:"unknown_mentions"`
+		Messages        map[string]string `json:"messages,omitempty"`
 	}
 	return func(w http.ResponseWriter, r *http.Request) {
 		req := request{}
@@ -187,25 +602,137 @@ func (h *Handler) RetrieveNotifications() http.HandlerFunc {
 		// respond to the client with the error message and a 400 status code.
 		err := h.decode(r, &req)
 		if err != nil {
-			h.respondWithError(w, err.Error(), http.StatusBadRequest)
+			h.respondWithError(w, r, "invalid_request_body", err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		res, err := h.svc.SendNotifications(r.Context(), services.SendNotificationsParams{
+		params := services.SendNotificationsParams{
 			Teacher:       req.Teacher,
 			Notifications: req.Notification,
-		})
+			TemplateID:    req.TemplateID,
+			Variables:     req.Variables,
+			SendAt:        req.SendAt,
+			Class:         req.Class,
+		}
+		if req.SendAt != "" {
+			sched, err := h.svc.ScheduleNotification(r.Context(), params)
+			if err != nil {
+				h.respondWithServiceError(w, r, err)
+				return
+			}
+			h.response(w, newScheduledNotificationResponse(sched), http.StatusAccepted)
+			return
+		}
+
+		res, err := h.svc.SendNotifications(r.Context(), params)
 		if err != nil {
-			h.respondWithError(w, err.Error(), http.StatusBadRequest)
+			h.respondWithServiceError(w, r, err)
 			return
 		}
 
 		h.response(w, response{
-			Recipients: res,
+			Recipients:      res.Recipients,
+			UnknownMentions: res.UnknownMentions,
+			Messages:        res.Messages,
 		}, http.StatusOK)
 	}
 }
 
+// notificationResponse is a notification of a history
+type notificationResponse struct {
+	ID         int       `json:"id"`
+	Teacher    string    `json:"teacher"`
+	Message    string    `json:"message"`
+	CreatedOn  time.Time `json:"created_on"`
+	Recipients []string  `json:"recipients,omitempty"`
+}
+
+// notificationsResponse is a page of a notification history
+type notificationsResponse struct {
+	Notifications []notificationResponse `json:"notifications"`
+	NextCursor    string                 `json:"next_cursor,omitempty"`
+}
+
+// newNotificationsResponse converts a page of notifications, leaving out the recipients unless withRecipients is set
+func newNotificationsResponse(p services.NotificationPage, withRecipients bool) notificationsResponse {
+	notifications := make([]notificationResponse, 0, len(p.Notifications))
+	for _, n := range p.Notifications {
+		resp := notificationResponse{
+			ID:        n.ID,
+			Teacher:   n.TeacherID,
+			Message:   n.Message,
+			CreatedOn: n.CreatedOn,
+		}
+		if withRecipients {
+			resp.Recipients = n.Recipients
+		}
+		notifications = append(notifications, resp)
+	}
+	return notificationsResponse{
+		Notifications: notifications,
+		NextCursor:    p.NextCursor,
+	}
+}
+
+// ListNotifications handles "GET /api/notifications"
+// Lists the notifications sent by a teacher with their recipients, latest first, a page at a time.
+// Pass the next_cursor of a page as cursor to get the next one.
+// ---
+// Responses:
+//
+//	200:
+//	401:
+//	403:
+//	404:
+//	422:
+//	500:
+func (h *Handler) ListNotifications() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		res, err := h.svc.ListNotifications(r.Context(), services.ListNotificationsParams{
+			Teacher: q.Get("teacher"),
+			Cursor:  q.Get("cursor"),
+			Limit:   q.Get("limit"),
+		})
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		h.response(w, newNotificationsResponse(res, true), http.StatusOK)
+	}
+}
+
+// GetStudentNotifications handles "GET /api/students/{email}/notifications"
+// Lists the notifications sent to a student, latest first, a page at a time. Pass the
+// next_cursor of a page as cursor to get the next one.
+// ---
+// Responses:
+//
+//	200:
+//	401:
+//	403:
+//	404:
+//	422:
+//	500:
+func (h *Handler) GetStudentNotifications() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		res, err := h.svc.ListNotifications(r.Context(), services.ListNotificationsParams{
+			Student: mux.Vars(r)["email"],
+			Cursor:  q.Get("cursor"),
+			Limit:   q.Get("limit"),
+		})
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		// the other recipients are not the student's business
+		h.response(w, newNotificationsResponse(res, false), http.StatusOK)
+	}
+}
+
 // CreateStudent handles "GET /api/student"
 // Adds a student.
 // ---
@@ -214,6 +741,9 @@ func (h *Handler) RetrieveNotifications() http.HandlerFunc {
 //	204:
 //	400:
 //	401:
+//	409:
+//	422:
+//	500:
 func (h *Handler) CreateStudent() http.HandlerFunc {
 	type request struct {
 		Email string `json:"email"`
@@ -226,7 +756,7 @@ func (h *Handler) CreateStudent() http.HandlerFunc {
 		// respond to the client with the error message and a 400 status code.
 		err := h.decode(r, &req)
 		if err != nil {
-			h.respondWithError(w, err.Error(), http.StatusBadRequest)
+			h.respondWithError(w, r, "invalid_request_body", err.Error(), http.StatusBadRequest)
 			return
 		}
 
@@ -235,7 +765,7 @@ func (h *Handler) CreateStudent() http.HandlerFunc {
 			Name:  req.Name,
 		})
 		if err != nil {
-			h.respondWithError(w, err.Error(), http.StatusBadRequest)
+			h.respondWithServiceError(w, r, err)
 			return
 		}
 
@@ -248,9 +778,12 @@ func (h *Handler) CreateStudent() http.HandlerFunc {
 // ---
 // Responses:
 //
-//	200:
+//	204:
 //	400:
 //	401:
+//	409:
+//	422:
+//	500:
 func (h *Handler) CreateTeacher() http.HandlerFunc {
 	type request struct {
 		Email string `json:"email"`
@@ -263,7 +796,7 @@ func (h *Handler) CreateTeacher() http.HandlerFunc {
 		// respond to the client with the error message and a 400 status code.
 		err := h.decode(r, &req)
 		if err != nil {
-			h.respondWithError(w, err.Error(), http.StatusBadRequest)
+			h.respondWithError(w, r, "invalid_request_body", err.Error(), http.StatusBadRequest)
 			return
 		}
 
@@ -272,10 +805,343 @@ func (h *Handler) CreateTeacher() http.HandlerFunc {
 			Name:  req.Name,
 		})
 		if err != nil {
-			h.respondWithError(w, err.Error(), http.StatusBadRequest)
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		h.response(w, "", http.StatusNoContent)
+	}
+}
+
+// studentResponse is the representation of a student returned by the student endpoints
+type studentResponse struct {
+	Email     string     `json:"email"`
+	Name      string     `json:"name"`
+	CreatedOn time.Time  `json:"created_on"`
+	UpdatedOn *time.Time `json:"updated_on,omitempty"`
+}
+
+func newStudentResponse(s models.Student) studentResponse {
+	return studentResponse{
+		Email:     s.Email,
+		Name:      s.Name,
+		CreatedOn: s.CreatedOn,
+		UpdatedOn: s.UpdatedOn,
+	}
+}
+
+// GetStudent handles "GET /api/students/{email}"
+// Retrieves a student.
+// ---
+// Responses:
+//
+//	200:
+//	401:
+//	403:
+//	404:
+//	500:
+func (h *Handler) GetStudent() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := h.svc.GetStudent(r.Context(), mux.Vars(r)["email"])
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		h.response(w, newStudentResponse(res), http.StatusOK)
+	}
+}
+
+// UpdateStudent handles "PATCH /api/students/{email}"
+// Changes the name or email of a student. The registrations follow an email change.
+// ---
+// Responses:
+//
+//	200:
+//	400:
+//	401:
+//	403:
+//	404:
+//	409:
+//	422:
+//	500:
+func (h *Handler) UpdateStudent() http.HandlerFunc {
+	type request struct {
+		Email string  `json:"email"`
+		Name  *string `json:"name"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := request{}
+		// Try to decode the request body into the struct. If there is an error,
+		// respond to the client with the error message and a 400 status code.
+		err := h.decode(r, &req)
+		if err != nil {
+			h.respondWithError(w, r, "invalid_request_body", err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		res, err := h.svc.UpdateStudent(r.Context(), mux.Vars(r)["email"], services.UpdateStudentParams{
+			Email: req.Email,
+			Name:  req.Name,
+		})
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		h.response(w, newStudentResponse(res), http.StatusOK)
+	}
+}
+
+// DeleteStudent handles "DELETE /api/students/{email}"
+// Soft deletes a student, leaving it out of every read until it is restored.
+// ---
+// Responses:
+//
+//	204:
+//	401:
+//	403:
+//	404:
+//	500:
+func (h *Handler) DeleteStudent() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := h.svc.DeleteStudent(r.Context(), mux.Vars(r)["email"])
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
 			return
 		}
 
 		h.response(w, "", http.StatusNoContent)
 	}
 }
+
+// RestoreStudent handles "POST /api/students/{email}/restore"
+// Restores a soft deleted student.
+// ---
+// Responses:
+//
+//	204:
+//	401:
+//	403:
+//	404:
+//	409:
+//	500:
+func (h *Handler) RestoreStudent() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := h.svc.RestoreStudent(r.Context(), mux.Vars(r)["email"])
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		h.response(w, "", http.StatusNoContent)
+	}
+}
+
+// teacherResponse is the representation of a teacher returned by the teacher endpoints
+type teacherResponse struct {
+	Email             string     `json:"email"`
+	Name              string     `json:"name"`
+	CreatedOn         time.Time  `json:"created_on"`
+	UpdatedOn         *time.Time `json:"updated_on,omitempty"`
+	ActiveStudents    int        `json:"active_students"`
+	SuspendedStudents int        `json:"suspended_students"`
+}
+
+func newTeacherResponse(t services.TeacherProfile) teacherResponse {
+	return teacherResponse{
+		Email:             t.Email,
+		Name:              t.Name,
+		CreatedOn:         t.CreatedOn,
+		UpdatedOn:         t.UpdatedOn,
+		ActiveStudents:    t.ActiveStudents,
+		SuspendedStudents: t.SuspendedStudents,
+	}
+}
+
+// GetTeacher handles "GET /api/teachers/{email}"
+// Retrieves a teacher with the number of their active and suspended students.
+// ---
+// Responses:
+//
+//	200:
+//	401:
+//	403:
+//	404:
+//	500:
+func (h *Handler) GetTeacher() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := h.svc.GetTeacherProfile(r.Context(), mux.Vars(r)["email"])
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		h.response(w, newTeacherResponse(res), http.StatusOK)
+	}
+}
+
+// UpdateTeacher handles "PATCH /api/teachers/{email}"
+// Changes the name or email of a teacher. The registrations follow an email change.
+// ---
+// Responses:
+//
+//	200:
+//	400:
+//	401:
+//	403:
+//	404:
+//	409:
+//	422:
+//	500:
+func (h *Handler) UpdateTeacher() http.HandlerFunc {
+	type request struct {
+		Email string  `json:"email"`
+		Name  *string `json:"name"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := request{}
+		// Try to decode the request body into the struct. If there is an error,
+		// respond to the client with the error message and a 400 status code.
+		err := h.decode(r, &req)
+		if err != nil {
+			h.respondWithError(w, r, "invalid_request_body", err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		res, err := h.svc.UpdateTeacher(r.Context(), mux.Vars(r)["email"], services.UpdateTeacherParams{
+			Email: req.Email,
+			Name:  req.Name,
+		})
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		h.response(w, newTeacherResponse(res), http.StatusOK)
+	}
+}
+
+// DeleteTeacher handles "DELETE /api/teachers/{email}"
+// Soft deletes a teacher, which hides them from common students and notifications.
+// ---
+// Responses:
+//
+//	204:
+//	401:
+//	403:
+//	404:
+//	500:
+func (h *Handler) DeleteTeacher() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := h.svc.DeleteTeacher(r.Context(), mux.Vars(r)["email"])
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		h.response(w, "", http.StatusNoContent)
+	}
+}
+
+// totalCountHeader holds the number of records matching the filters of a listing
+const totalCountHeader = "X-Total-Count"
+
+// listParams reads the filters shared by the listings from the query string
+func listParams(r *http.Request) services.ListParams {
+	q := r.URL.Query()
+	return services.ListParams{
+		CreatedAfter: q.Get("created_after"),
+		Query:        q.Get("q"),
+		Sort:         q.Get("sort"),
+		Cursor:       q.Get("cursor"),
+		Limit:        q.Get("limit"),
+	}
+}
+
+// ListStudents handles "GET /api/students"
+// Lists the students a page at a time. Pass the next_cursor of a page as cursor to get the next one.
+// ---
+// Responses:
+//
+//	200:
+//	401:
+//	403:
+//	422:
+//	500:
+func (h *Handler) ListStudents() http.HandlerFunc {
+	type response struct {
+		Students   []studentResponse `json:"students"`
+		NextCursor string            `json:"next_cursor,omitempty"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := h.svc.ListStudents(r.Context(), services.ListStudentsParams{
+			ListParams: listParams(r),
+			Teacher:    r.URL.Query().Get("teacher"),
+			Suspended:  r.URL.Query().Get("suspended"),
+		})
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		students := make([]studentResponse, 0, len(res.Students))
+		for _, s := range res.Students {
+			students = append(students, newStudentResponse(s))
+		}
+		w.Header().Set(totalCountHeader, strconv.Itoa(res.Total))
+		h.response(w, response{
+			Students:   students,
+			NextCursor: res.NextCursor,
+		}, http.StatusOK)
+	}
+}
+
+// ListTeachers handles "GET /api/teachers"
+// Lists the teachers a page at a time. Pass the next_cursor of a page as cursor to get the next one.
+// ---
+// Responses:
+//
+//	200:
+//	401:
+//	403:
+//	422:
+//	500:
+func (h *Handler) ListTeachers() http.HandlerFunc {
+	type teacher struct {
+		Email     string     `json:"email"`
+		Name      string     `json:"name"`
+		CreatedOn time.Time  `json:"created_on"`
+		UpdatedOn *time.Time `json:"updated_on,omitempty"`
+	}
+	type response struct {
+		Teachers   []teacher `json:"teachers"`
+		NextCursor string    `json:"next_cursor,omitempty"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := h.svc.ListTeachers(r.Context(), listParams(r))
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		teachers := make([]teacher, 0, len(res.Teachers))
+		for _, t := range res.Teachers {
+			teachers = append(teachers, teacher{
+				Email:     t.Email,
+				Name:      t.Name,
+				CreatedOn: t.CreatedOn,
+				UpdatedOn: t.UpdatedOn,
+			})
+		}
+		w.Header().Set(totalCountHeader, strconv.Itoa(res.Total))
+		h.response(w, response{
+			Teachers:   teachers,
+			NextCursor: res.NextCursor,
+		}, http.StatusOK)
+	}
+}
@@ -0,0 +1,816 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/golang-jwt/jwt"
+	"github.com/gorilla/mux"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/whittier16/student-reg-svc/internal/app/config"
+	"github.com/whittier16/student-reg-svc/internal/app/repository/memory"
+	"github.com/whittier16/student-reg-svc/internal/app/server"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/cache"
+	"github.com/whittier16/student-reg-svc/internal/pkg/handlers"
+	"github.com/whittier16/student-reg-svc/internal/pkg/logger"
+	"github.com/whittier16/student-reg-svc/internal/pkg/services"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+var _ = Describe("Handler", func() {
+	var (
+		router       *mux.Router
+		cfg          *config.MainConfig
+		token        string
+		refreshToken string
+	)
+
+	refreshTokenOf := func(rec *httptest.ResponseRecorder) string {
+		body := map[string]interface{}{}
+		Expect(json.Unmarshal(rec.Body.Bytes(), &body)).Should(Succeed())
+		return body["refresh_token"].(string)
+	}
+
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	BeforeEach(func() {
+		cfg = &config.MainConfig{
+			JWT: config.JWTConfig{Secret: "testSecret", RefreshSecret: "testRefreshSecret"},
+		}
+	})
+
+	JustBeforeEach(func() {
+		store := memory.New()
+		rr := memory.NewRegisterRepository(store)
+		svc := services.NewService(
+			memory.NewStudentRepository(store),
+			memory.NewTeacherRepository(store),
+			rr,
+			memory.NewSuspensionRepository(store),
+			memory.NewNotificationRepository(store),
+			memory.NewTemplateRepository(store),
+			memory.NewScheduleRepository(store),
+			memory.NewPreferenceRepository(store),
+			memory.NewClassRepository(store),
+			memory.NewTermRepository(store),
+			memory.NewUserRepository(store),
+			memory.NewUnitOfWork(store),
+		)
+		log := logger.NewLogger()
+		log.SetOutput(io.Discard)
+		router = mux.NewRouter()
+		handlers.RegisterRoutes(router, log, svc, cache.NewMemory(), cfg)
+
+		Expect(svc.EnsureAdmin(context.Background(), "admin@gmail.com", "password123")).Should(Succeed())
+		token = ""
+		rec := do(http.MethodPost, "/auth/login", `{"email": "admin@gmail.com", "password": "password123"}`)
+		Expect(rec.Code).Should(Equal(http.StatusOK))
+		token = rec.Header().Get("Token")
+		refreshToken = refreshTokenOf(rec)
+
+		Expect(do(http.MethodPost, "/api/teachers", `{"email": "teacher1@gmail.com"}`).Code).Should(Equal(http.StatusNoContent))
+		Expect(do(http.MethodPost, "/api/students", `{"email": "student1@gmail.com"}`).Code).Should(Equal(http.StatusNoContent))
+		Expect(rr.Register(context.Background(), "teacher1@gmail.com", "", []string{"student1@gmail.com"})).Should(Succeed())
+	})
+
+	It("CORS should answer the preflight of write routes and expose the total count", func() {
+		req := httptest.NewRequest(http.MethodOptions, "/api/students/student1@gmail.com", nil)
+		req.Header.Set("Origin", "http://localhost:3000")
+		req.Header.Set("Access-Control-Request-Method", http.MethodPatch)
+		req.Header.Set("Access-Control-Request-Headers", "Authorization, Content-Type")
+		rec := httptest.NewRecorder()
+		server.CORS(router).ServeHTTP(rec, req)
+		Expect(rec.Code).Should(BeNumerically("<", 300))
+		Expect(rec.Header().Get("Access-Control-Allow-Methods")).Should(Equal(http.MethodPatch))
+		Expect(rec.Header().Get("Access-Control-Allow-Origin")).ShouldNot(BeEmpty())
+
+		req = httptest.NewRequest(http.MethodGet, "/api/students", nil)
+		req.Header.Set("Origin", "http://localhost:3000")
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec = httptest.NewRecorder()
+		server.CORS(router).ServeHTTP(rec, req)
+		Expect(rec.Code).Should(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Access-Control-Expose-Headers")).Should(ContainSubstring("X-Total-Count"))
+	})
+	It("should reject requests without a token", func() {
+		token = ""
+		rec := do(http.MethodGet, "/api/commonstudents?teacher=teacher1%40gmail.com", "")
+		Expect(rec.Code).Should(Equal(http.StatusUnauthorized))
+	})
+	It("GetCommonStudents should list the registered students", func() {
+		rec := do(http.MethodGet, "/api/commonstudents?teacher=teacher1%40gmail.com", "")
+		Expect(rec.Code).Should(Equal(http.StatusOK))
+		Expect(rec.Body.String()).Should(MatchJSON(`{"students": ["student1@gmail.com"]}`))
+	})
+	It("RetrieveNotifications should leave out suspended students", func() {
+		Expect(do(http.MethodPost, "/api/suspend", `{"student": "student1@gmail.com", "reason": "misconduct"}`).Code).Should(Equal(http.StatusNoContent))
+
+		rec := do(http.MethodPost, "/api/retrievefornotifications", `{"teacher": "teacher1@gmail.com", "notification": "Hello"}`)
+		Expect(rec.Code).Should(Equal(http.StatusOK))
+		Expect(rec.Body.String()).Should(MatchJSON(`{"recipients": [], "unknown_mentions": []}`))
+	})
+	It("RetrieveNotifications should report mentions of unknown students", func() {
+		rec := do(http.MethodPost, "/api/retrievefornotifications", `{"teacher": "teacher1@gmail.com", "notification": "Hi @Student1@gmail.com, @nobody@gmail.com!"}`)
+		Expect(rec.Code).Should(Equal(http.StatusOK))
+		Expect(rec.Body.String()).Should(MatchJSON(`{"recipients": ["student1@gmail.com"], "unknown_mentions": ["nobody@gmail.com"]}`))
+	})
+	It("RetrieveNotifications should be listed in the notification histories", func() {
+		Expect(do(http.MethodPost, "/api/retrievefornotifications", `{"teacher": "teacher1@gmail.com", "notification": "Hello"}`).Code).Should(Equal(http.StatusOK))
+
+		rec := do(http.MethodGet, "/api/notifications?teacher=teacher1%40gmail.com", "")
+		Expect(rec.Code).Should(Equal(http.StatusOK))
+		Expect(rec.Body.String()).Should(ContainSubstring(`"teacher":"teacher1@gmail.com","message":"Hello"`))
+		Expect(rec.Body.String()).Should(ContainSubstring(`"recipients":["student1@gmail.com"]`))
+
+		rec = do(http.MethodGet, "/api/students/student1@gmail.com/notifications", "")
+		Expect(rec.Code).Should(Equal(http.StatusOK))
+		Expect(rec.Body.String()).Should(ContainSubstring(`"message":"Hello"`))
+		Expect(rec.Body.String()).ShouldNot(ContainSubstring(`"recipients"`))
+	})
+	DescribeTable("should map service errors to status codes",
+		func(method, target, body string, status int, code string) {
+			rec := do(method, target, body)
+			Expect(rec.Code).Should(Equal(status))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"code":"` + code + `"`))
+		},
+		Entry("unknown teacher", http.MethodPost, "/api/register",
+			`{"teacher": "nobody@gmail.com", "students": ["student1@gmail.com"]}`, http.StatusNotFound, "teacher_not_found"),
+		Entry("existing registration", http.MethodPost, "/api/register",
+			`{"teacher": "teacher1@gmail.com", "students": ["student1@gmail.com"]}`, http.StatusConflict, "registration_already_exists"),
+		Entry("existing student", http.MethodPost, "/api/students",
+			`{"email": "student1@gmail.com"}`, http.StatusConflict, "student_already_exists"),
+		Entry("invalid email", http.MethodPost, "/api/students",
+			`{"email": "student1"}`, http.StatusUnprocessableEntity, "validation_failed"),
+		Entry("malformed body", http.MethodPost, "/api/students",
+			`{"email":`, http.StatusBadRequest, "invalid_request_body"),
+	)
+	It("should respond with problem details naming the invalid student email", func() {
+		rec := do(http.MethodPost, "/api/register", `{"teacher": "teacher1@gmail.com", "students": ["student1@gmail.com", "student2"]}`)
+		Expect(rec.Code).Should(Equal(http.StatusUnprocessableEntity))
+		Expect(rec.Header().Get("Content-Type")).Should(Equal("application/problem+json"))
+		Expect(rec.Body.String()).Should(MatchJSON(`{
+			"type": "/problems/validation_failed",
+			"title": "Unprocessable Entity",
+			"status": 422,
+			"detail": "students[1]: must be a valid email address",
+			"instance": "/api/register",
+			"code": "validation_failed",
+			"errors": [{"field": "students[1]", "reason": "must be a valid email address"}]
+		}`))
+	})
+	It("Login should reject a wrong password", func() {
+		token = ""
+		rec := do(http.MethodPost, "/auth/login", `{"email": "admin@gmail.com", "password": "wrong-password"}`)
+		Expect(rec.Code).Should(Equal(http.StatusUnauthorized))
+		Expect(rec.Body.String()).Should(ContainSubstring(`"code":"invalid_credentials"`))
+	})
+	It("CreateUser should only be allowed to administrators", func() {
+		rec := do(http.MethodPost, "/api/users", `{"email": "teacher1@gmail.com", "password": "password123", "role": "teacher"}`)
+		Expect(rec.Code).Should(Equal(http.StatusCreated))
+		Expect(rec.Body.String()).Should(ContainSubstring(`"role":"teacher"`))
+
+		rec = do(http.MethodPost, "/auth/login", `{"email": "teacher1@gmail.com", "password": "password123"}`)
+		Expect(rec.Code).Should(Equal(http.StatusOK))
+		token = rec.Header().Get("Token")
+
+		rec = do(http.MethodPost, "/api/users", `{"email": "teacher2@gmail.com", "password": "password123", "role": "admin"}`)
+		Expect(rec.Code).Should(Equal(http.StatusForbidden))
+	})
+	Describe("authorization", func() {
+		loginAs := func(email, role string) {
+			rec := do(http.MethodPost, "/api/users", `{"email": "`+email+`", "password": "password123", "role": "`+role+`"}`)
+			Expect(rec.Code).Should(Equal(http.StatusCreated))
+			rec = do(http.MethodPost, "/auth/login", `{"email": "`+email+`", "password": "password123"}`)
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+			token = rec.Header().Get("Token")
+		}
+
+		JustBeforeEach(func() {
+			Expect(do(http.MethodPost, "/api/teachers", `{"email": "teacher2@gmail.com"}`).Code).Should(Equal(http.StatusNoContent))
+			Expect(do(http.MethodPost, "/api/students", `{"email": "student2@gmail.com"}`).Code).Should(Equal(http.StatusNoContent))
+		})
+
+		It("should let a teacher register students to themselves only", func() {
+			loginAs("teacher1@gmail.com", "teacher")
+
+			rec := do(http.MethodPost, "/api/register", `{"teacher": "teacher2@gmail.com", "students": ["student2@gmail.com"]}`)
+			Expect(rec.Code).Should(Equal(http.StatusForbidden))
+			Expect(rec.Header().Get("Content-Type")).Should(Equal("application/problem+json"))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"code":"forbidden"`))
+
+			rec = do(http.MethodPost, "/api/register", `{"teacher": "teacher1@gmail.com", "TEACHER": "teacher2@gmail.com", "students": ["student2@gmail.com"]}`)
+			Expect(rec.Code).Should(Equal(http.StatusForbidden))
+
+			rec = do(http.MethodPost, "/api/register", `{"teacher": "teacher1@gmail.com", "students": ["student2@gmail.com"]}`)
+			Expect(rec.Code).Should(Equal(http.StatusNoContent))
+		})
+		It("should let a teacher send notifications as themselves only", func() {
+			loginAs("teacher1@gmail.com", "teacher")
+
+			rec := do(http.MethodPost, "/api/retrievefornotifications", `{"teacher": "teacher2@gmail.com", "notification": "Hello"}`)
+			Expect(rec.Code).Should(Equal(http.StatusForbidden))
+			rec = do(http.MethodPost, "/api/retrievefornotifications", `{"teacher": "teacher1@gmail.com", "notification": "Hello"}`)
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+		})
+		It("should let a teacher suspend from their own class only", func() {
+			loginAs("teacher1@gmail.com", "teacher")
+			rec := do(http.MethodPost, "/api/suspend", `{"student": "student1@gmail.com", "reason": "misconduct"}`)
+			Expect(rec.Code).Should(Equal(http.StatusForbidden))
+			rec = do(http.MethodPost, "/api/suspend", `{"student": "student1@gmail.com", "teacher": "teacher2@gmail.com", "reason": "misconduct"}`)
+			Expect(rec.Code).Should(Equal(http.StatusForbidden))
+			rec = do(http.MethodPost, "/api/suspend", `{"student": "student1@gmail.com", "teacher": "teacher1@gmail.com", "reason": "misconduct"}`)
+			Expect(rec.Code).Should(Equal(http.StatusNoContent))
+
+			Expect(do(http.MethodPost, "/api/unsuspend", `{"student": "student1@gmail.com"}`).Code).Should(Equal(http.StatusForbidden))
+			Expect(do(http.MethodPost, "/api/unsuspend", `{"student": "student1@gmail.com", "teacher": "teacher1@gmail.com"}`).Code).Should(Equal(http.StatusNoContent))
+		})
+		It("should keep teachers from changing students", func() {
+			loginAs("teacher1@gmail.com", "teacher")
+			Expect(do(http.MethodPatch, "/api/students/student1@gmail.com", `{"email": "teacher1@gmail.com"}`).Code).Should(Equal(http.StatusForbidden))
+			Expect(do(http.MethodPatch, "/api/students/student1@gmail.com", `{"name": "Renamed"}`).Code).Should(Equal(http.StatusForbidden))
+		})
+		It("should let a teacher browse only their own notifications", func() {
+			loginAs("teacher1@gmail.com", "teacher")
+			Expect(do(http.MethodGet, "/api/notifications?teacher=teacher2%40gmail.com", "").Code).Should(Equal(http.StatusForbidden))
+			Expect(do(http.MethodGet, "/api/notifications", "").Code).Should(Equal(http.StatusForbidden))
+			Expect(do(http.MethodGet, "/api/notifications?teacher=teacher1%40gmail.com", "").Code).Should(Equal(http.StatusOK))
+		})
+		It("should let a student read only their own record", func() {
+			loginAs("student1@gmail.com", "student")
+			Expect(do(http.MethodGet, "/api/students/student1@gmail.com", "").Code).Should(Equal(http.StatusOK))
+			Expect(do(http.MethodGet, "/api/students/student2@gmail.com", "").Code).Should(Equal(http.StatusForbidden))
+			Expect(do(http.MethodPatch, "/api/students/student1@gmail.com", `{"name": "Me"}`).Code).Should(Equal(http.StatusForbidden))
+		})
+		It("should let a renamed student log in and act as themselves under the new email", func() {
+			admin := token
+			loginAs("student1@gmail.com", "student")
+			token = admin
+			Expect(do(http.MethodPatch, "/api/students/student1@gmail.com", `{"email": "renamed@gmail.com"}`).Code).Should(Equal(http.StatusOK))
+
+			token = ""
+			Expect(do(http.MethodPost, "/auth/login", `{"email": "student1@gmail.com", "password": "password123"}`).Code).Should(Equal(http.StatusUnauthorized))
+			rec := do(http.MethodPost, "/auth/login", `{"email": "renamed@gmail.com", "password": "password123"}`)
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+			token = rec.Header().Get("Token")
+			Expect(do(http.MethodGet, "/api/students/renamed@gmail.com/preferences", "").Code).Should(Equal(http.StatusOK))
+			Expect(do(http.MethodGet, "/api/students/renamed@gmail.com", "").Code).Should(Equal(http.StatusOK))
+		})
+		It("should keep students out of the teacher endpoints", func() {
+			loginAs("student1@gmail.com", "student")
+			rec := do(http.MethodGet, "/api/commonstudents?teacher=teacher1%40gmail.com", "")
+			Expect(rec.Code).Should(Equal(http.StatusForbidden))
+		})
+	})
+	Describe("students", func() {
+		It("UpdateStudent should rename the student and keep its registrations", func() {
+			rec := do(http.MethodPatch, "/api/students/student1@gmail.com", `{"email": "renamed@gmail.com", "name": "Renamed"}`)
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"email":"renamed@gmail.com","name":"Renamed"`))
+
+			Expect(do(http.MethodGet, "/api/students/student1@gmail.com", "").Code).Should(Equal(http.StatusNotFound))
+			rec = do(http.MethodGet, "/api/commonstudents?teacher=teacher1%40gmail.com", "")
+			Expect(rec.Body.String()).Should(ContainSubstring("renamed@gmail.com"))
+		})
+		It("UpdateStudent should reject an empty or invalid change", func() {
+			Expect(do(http.MethodPatch, "/api/students/student1@gmail.com", `{}`).Code).Should(Equal(http.StatusUnprocessableEntity))
+			Expect(do(http.MethodPatch, "/api/students/student1@gmail.com", `{"email": "nope"}`).Code).Should(Equal(http.StatusUnprocessableEntity))
+			Expect(do(http.MethodPatch, "/api/students/nobody@gmail.com", `{"name": "x"}`).Code).Should(Equal(http.StatusNotFound))
+		})
+		It("ListStudents should return the total count and a cursor to the next page", func() {
+			Expect(do(http.MethodPost, "/api/students", `{"email": "student2@gmail.com", "name": "Second"}`).Code).Should(Equal(http.StatusNoContent))
+
+			rec := do(http.MethodGet, "/api/students?limit=1&sort=-email", "")
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+			Expect(rec.Header().Get("X-Total-Count")).Should(Equal("2"))
+			body := map[string]interface{}{}
+			Expect(json.Unmarshal(rec.Body.Bytes(), &body)).Should(Succeed())
+			Expect(rec.Body.String()).Should(ContainSubstring("student2@gmail.com"))
+
+			rec = do(http.MethodGet, "/api/students?limit=1&sort=-email&cursor="+body["next_cursor"].(string), "")
+			Expect(rec.Body.String()).Should(ContainSubstring("student1@gmail.com"))
+			Expect(rec.Body.String()).ShouldNot(ContainSubstring("next_cursor"))
+
+			rec = do(http.MethodGet, "/api/students?q=sec", "")
+			Expect(rec.Header().Get("X-Total-Count")).Should(Equal("1"))
+			Expect(do(http.MethodGet, "/api/students?suspended=maybe", "").Code).Should(Equal(http.StatusUnprocessableEntity))
+		})
+		It("DeleteStudent should hide the student until it is restored", func() {
+			Expect(do(http.MethodDelete, "/api/students/student1@gmail.com", "").Code).Should(Equal(http.StatusNoContent))
+			Expect(do(http.MethodGet, "/api/students/student1@gmail.com", "").Code).Should(Equal(http.StatusNotFound))
+			rec := do(http.MethodGet, "/api/commonstudents?teacher=teacher1%40gmail.com", "")
+			Expect(rec.Body.String()).ShouldNot(ContainSubstring("student1@gmail.com"))
+
+			Expect(do(http.MethodPost, "/api/students/student1@gmail.com/restore", "").Code).Should(Equal(http.StatusNoContent))
+			rec = do(http.MethodPost, "/api/students/student1@gmail.com/restore", "")
+			Expect(rec.Code).Should(Equal(http.StatusConflict))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"code":"student_not_deleted"`))
+			Expect(do(http.MethodGet, "/api/students/student1@gmail.com", "").Code).Should(Equal(http.StatusOK))
+		})
+	})
+	It("Unsuspend should reinstate the student and keep the history", func() {
+		Expect(do(http.MethodPost, "/api/suspend", `{"student": "student1@gmail.com"}`).Code).Should(Equal(http.StatusUnprocessableEntity))
+		Expect(do(http.MethodPost, "/api/unsuspend", `{"student": "student1@gmail.com"}`).Code).Should(Equal(http.StatusConflict))
+		Expect(do(http.MethodPost, "/api/suspend", `{"student": "student1@gmail.com", "reason": "misconduct"}`).Code).Should(Equal(http.StatusNoContent))
+		Expect(do(http.MethodPost, "/api/suspend", `{"student": "student1@gmail.com", "reason": "again"}`).Code).Should(Equal(http.StatusConflict))
+		Expect(do(http.MethodPost, "/api/unsuspend", `{"student": "student1@gmail.com"}`).Code).Should(Equal(http.StatusNoContent))
+
+		rec := do(http.MethodGet, "/api/commonstudents?teacher=teacher1%40gmail.com", "")
+		Expect(rec.Body.String()).Should(ContainSubstring("student1@gmail.com"))
+
+		rec = do(http.MethodGet, "/api/students/student1@gmail.com/suspensions", "")
+		Expect(rec.Code).Should(Equal(http.StatusOK))
+		Expect(rec.Body.String()).Should(ContainSubstring(`"reason":"misconduct","suspended_by":"admin@gmail.com"`))
+		Expect(rec.Body.String()).Should(ContainSubstring(`"lifted_by":"admin@gmail.com","active":false`))
+	})
+	It("Suspend with a teacher should only keep the student out of that teacher's class", func() {
+		Expect(do(http.MethodPost, "/api/teachers", `{"email": "teacher2@gmail.com"}`).Code).Should(Equal(http.StatusNoContent))
+		Expect(do(http.MethodPost, "/api/register", `{"teacher": "teacher2@gmail.com", "students": ["student1@gmail.com"]}`).Code).Should(Equal(http.StatusNoContent))
+		rec := do(http.MethodPost, "/api/suspend", `{"student": "student1@gmail.com", "teacher": "nobody@gmail.com", "reason": "late"}`)
+		Expect(rec.Code).Should(Equal(http.StatusNotFound))
+		Expect(rec.Body.String()).Should(ContainSubstring(`"code":"teacher_not_found"`))
+		Expect(do(http.MethodPost, "/api/suspend", `{"student": "student1@gmail.com", "teacher": "teacher1@gmail.com", "reason": "late"}`).Code).Should(Equal(http.StatusNoContent))
+
+		rec = do(http.MethodGet, "/api/commonstudents?teacher=teacher1%40gmail.com", "")
+		Expect(rec.Body.String()).ShouldNot(ContainSubstring("student1@gmail.com"))
+		rec = do(http.MethodGet, "/api/commonstudents?teacher=teacher2%40gmail.com", "")
+		Expect(rec.Body.String()).Should(MatchJSON(`{"students": ["student1@gmail.com"]}`))
+		rec = do(http.MethodPost, "/api/retrievefornotifications", `{"teacher": "teacher1@gmail.com", "notification": "Hello @student1@gmail.com"}`)
+		Expect(rec.Body.String()).Should(MatchJSON(`{"recipients": [], "unknown_mentions": []}`))
+		rec = do(http.MethodPost, "/api/retrievefornotifications", `{"teacher": "teacher2@gmail.com", "notification": "Hello"}`)
+		Expect(rec.Body.String()).Should(MatchJSON(`{"recipients": ["student1@gmail.com"], "unknown_mentions": []}`))
+
+		rec = do(http.MethodGet, "/api/students/student1@gmail.com/suspensions", "")
+		Expect(rec.Body.String()).Should(ContainSubstring(`"teacher":"teacher1@gmail.com","reason":"late"`))
+
+		// a global suspension is lifted by an administrator without a teacher
+		Expect(do(http.MethodPost, "/api/suspend", `{"student": "student1@gmail.com", "reason": "misconduct"}`).Code).Should(Equal(http.StatusNoContent))
+		Expect(do(http.MethodPost, "/api/unsuspend", `{"student": "student1@gmail.com", "teacher": "teacher1@gmail.com"}`).Code).Should(Equal(http.StatusNoContent))
+		rec = do(http.MethodPost, "/api/unsuspend", `{"student": "student1@gmail.com", "teacher": "teacher1@gmail.com"}`)
+		Expect(rec.Code).Should(Equal(http.StatusConflict))
+		Expect(rec.Body.String()).Should(ContainSubstring(`"code":"student_suspended_globally"`))
+		Expect(do(http.MethodPost, "/api/unsuspend", `{"student": "student1@gmail.com"}`).Code).Should(Equal(http.StatusNoContent))
+		rec = do(http.MethodGet, "/api/commonstudents?teacher=teacher1%40gmail.com", "")
+		Expect(rec.Body.String()).Should(MatchJSON(`{"students": ["student1@gmail.com"]}`))
+	})
+	It("Suspend should reject an end date in the past", func() {
+		rec := do(http.MethodPost, "/api/suspend", `{"student": "student1@gmail.com", "reason": "misconduct", "until": "2000-01-01T00:00:00Z"}`)
+		Expect(rec.Code).Should(Equal(http.StatusUnprocessableEntity))
+		Expect(rec.Body.String()).Should(ContainSubstring(`"field":"until"`))
+	})
+	It("Register in partial mode should answer 207 with the outcome for each student", func() {
+		rec := do(http.MethodPost, "/api/register", `{"teacher": "teacher1@gmail.com", "students": ["student1@gmail.com", "nobody@gmail.com"], "mode": "partial"}`)
+		Expect(rec.Code).Should(Equal(http.StatusMultiStatus))
+		Expect(rec.Body.String()).Should(MatchJSON(`{"results": [
+			{"student": "student1@gmail.com", "status": "already_registered"},
+			{"student": "nobody@gmail.com", "status": "not_found"}
+		]}`))
+
+		rec = do(http.MethodPost, "/api/register", `{"teacher": "teacher1@gmail.com", "students": ["student1@gmail.com"], "mode": "upsert"}`)
+		Expect(rec.Code).Should(Equal(http.StatusOK))
+		rec = do(http.MethodPost, "/api/register", `{"teacher": "teacher1@gmail.com", "students": ["student1@gmail.com"], "mode": "all"}`)
+		Expect(rec.Code).Should(Equal(http.StatusUnprocessableEntity))
+	})
+	It("Register with create_missing should list the created students", func() {
+		rec := do(http.MethodPost, "/api/register", `{"teacher": "teacher1@gmail.com", "students": ["student2@gmail.com"], "create_missing": true}`)
+		Expect(rec.Code).Should(Equal(http.StatusOK))
+		Expect(rec.Body.String()).Should(MatchJSON(`{
+			"results": [{"student": "student2@gmail.com", "status": "registered"}],
+			"created": ["student2@gmail.com"]
+		}`))
+	})
+	It("Unregister should report the students that were unregistered", func() {
+		body := `{"teacher": "teacher1@gmail.com", "students": ["student1@gmail.com"]}`
+		rec := do(http.MethodDelete, "/api/register", body)
+		Expect(rec.Code).Should(Equal(http.StatusOK))
+		Expect(rec.Body.String()).Should(ContainSubstring(`"unregistered":["student1@gmail.com"]`))
+
+		rec = do(http.MethodDelete, "/api/register", body)
+		Expect(rec.Code).Should(Equal(http.StatusOK))
+		Expect(rec.Body.String()).Should(ContainSubstring(`"unregistered":[]`))
+
+		rec = do(http.MethodGet, "/api/commonstudents?teacher=teacher1%40gmail.com", "")
+		Expect(rec.Body.String()).ShouldNot(ContainSubstring("student1@gmail.com"))
+		Expect(do(http.MethodPost, "/api/register", body).Code).Should(Equal(http.StatusNoContent))
+	})
+	Describe("teachers", func() {
+		It("GetTeacher should count the active and suspended students", func() {
+			Expect(do(http.MethodPost, "/api/students", `{"email": "student2@gmail.com"}`).Code).Should(Equal(http.StatusNoContent))
+			Expect(do(http.MethodPost, "/api/register", `{"teacher": "teacher1@gmail.com", "students": ["student2@gmail.com"]}`).Code).Should(Equal(http.StatusNoContent))
+			Expect(do(http.MethodPost, "/api/suspend", `{"student": "student2@gmail.com", "reason": "misconduct"}`).Code).Should(Equal(http.StatusNoContent))
+
+			rec := do(http.MethodGet, "/api/teachers/teacher1@gmail.com", "")
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"active_students":1,"suspended_students":1`))
+		})
+		It("UpdateTeacher should move the registrations to the new email", func() {
+			rec := do(http.MethodPatch, "/api/teachers/teacher1@gmail.com", `{"email": "renamed@gmail.com"}`)
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"active_students":1`))
+
+			rec = do(http.MethodGet, "/api/commonstudents?teacher=renamed%40gmail.com", "")
+			Expect(rec.Body.String()).Should(ContainSubstring("student1@gmail.com"))
+		})
+		It("ListTeachers should list the teachers", func() {
+			rec := do(http.MethodGet, "/api/teachers?created_after=2000-01-01T00:00:00Z", "")
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+			Expect(rec.Header().Get("X-Total-Count")).Should(Equal("1"))
+			Expect(rec.Body.String()).Should(ContainSubstring("teacher1@gmail.com"))
+		})
+		It("DeleteTeacher should hide the teacher from common students and notifications", func() {
+			Expect(do(http.MethodDelete, "/api/teachers/teacher1@gmail.com", "").Code).Should(Equal(http.StatusNoContent))
+			Expect(do(http.MethodDelete, "/api/teachers/teacher1@gmail.com", "").Code).Should(Equal(http.StatusNotFound))
+			Expect(do(http.MethodGet, "/api/teachers/teacher1@gmail.com", "").Code).Should(Equal(http.StatusNotFound))
+
+			rec := do(http.MethodGet, "/api/commonstudents?teacher=teacher1%40gmail.com", "")
+			Expect(rec.Body.String()).ShouldNot(ContainSubstring("student1@gmail.com"))
+			rec = do(http.MethodPost, "/api/retrievefornotifications", `{"teacher": "teacher1@gmail.com", "notification": "Hello"}`)
+			Expect(rec.Code).Should(Equal(http.StatusNotFound))
+		})
+	})
+	Describe("templates", func() {
+		It("should create, change and delete the templates of a teacher", func() {
+			rec := do(http.MethodPost, "/api/teachers/teacher1@gmail.com/templates", `{"name": "welcome", "body": "Welcome {{.StudentName}}"}`)
+			Expect(rec.Code).Should(Equal(http.StatusCreated))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"teacher":"teacher1@gmail.com","name":"welcome","body":"Welcome {{.StudentName}}"`))
+			created := map[string]interface{}{}
+			Expect(json.Unmarshal(rec.Body.Bytes(), &created)).Should(Succeed())
+			target := fmt.Sprintf("/api/teachers/teacher1@gmail.com/templates/%v", created["id"])
+
+			rec = do(http.MethodPost, "/api/teachers/teacher1@gmail.com/templates", `{"name": "welcome", "body": "Hi"}`)
+			Expect(rec.Code).Should(Equal(http.StatusConflict))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"code":"template_already_exists"`))
+
+			rec = do(http.MethodPatch, target, `{"body": "Welcome back {{.StudentName}}"}`)
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"body":"Welcome back {{.StudentName}}"`))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"updated_on"`))
+
+			rec = do(http.MethodGet, "/api/teachers/teacher1@gmail.com/templates", "")
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"name":"welcome"`))
+
+			Expect(do(http.MethodDelete, target, "").Code).Should(Equal(http.StatusNoContent))
+			Expect(do(http.MethodGet, target, "").Code).Should(Equal(http.StatusNotFound))
+		})
+		It("should reject templates that are not only text and variables", func() {
+			rec := do(http.MethodPost, "/api/teachers/teacher1@gmail.com/templates", `{"name": "loop", "body": "{{range .StudentName}}x{{end}}"}`)
+			Expect(rec.Code).Should(Equal(http.StatusUnprocessableEntity))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"code":"invalid_template"`))
+
+			rec = do(http.MethodPost, "/api/teachers/teacher1@gmail.com/templates", `{"name": "call", "body": "{{printf \"%999999d\" 1}}"}`)
+			Expect(rec.Code).Should(Equal(http.StatusUnprocessableEntity))
+		})
+		It("RetrieveNotifications should render a template for each recipient", func() {
+			Expect(do(http.MethodPatch, "/api/students/student1@gmail.com", `{"name": "Ann"}`).Code).Should(Equal(http.StatusOK))
+			Expect(do(http.MethodPost, "/api/students", `{"email": "student2@gmail.com", "name": "Bob"}`).Code).Should(Equal(http.StatusNoContent))
+			rec := do(http.MethodPost, "/api/teachers/teacher1@gmail.com/templates", `{"name": "exam", "body": "Hi {{.StudentName}}, the {{.Subject}} exam is on Monday. cc {{.Cc}}"}`)
+			Expect(rec.Code).Should(Equal(http.StatusCreated))
+			created := map[string]interface{}{}
+			Expect(json.Unmarshal(rec.Body.Bytes(), &created)).Should(Succeed())
+
+			body := fmt.Sprintf(`{"teacher": "teacher1@gmail.com", "template_id": %v, "variables": {"Subject": "math", "Cc": "@student2@gmail.com"}}`, created["id"])
+			rec = do(http.MethodPost, "/api/retrievefornotifications", body)
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+			Expect(rec.Body.String()).Should(MatchJSON(`{
+				"recipients": ["student1@gmail.com", "student2@gmail.com"],
+				"unknown_mentions": [],
+				"messages": {
+					"student1@gmail.com": "Hi Ann, the math exam is on Monday. cc @student2@gmail.com",
+					"student2@gmail.com": "Hi Bob, the math exam is on Monday. cc @student2@gmail.com"
+				}
+			}`))
+
+			rec = do(http.MethodGet, "/api/students/student2@gmail.com/notifications", "")
+			Expect(rec.Body.String()).Should(ContainSubstring(`"message":"Hi Bob, the math exam is on Monday. cc @student2@gmail.com"`))
+
+			rec = do(http.MethodPost, "/api/retrievefornotifications", fmt.Sprintf(`{"teacher": "teacher1@gmail.com", "template_id": %v}`, created["id"]))
+			Expect(rec.Code).Should(Equal(http.StatusUnprocessableEntity))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"code":"invalid_variables"`))
+		})
+		It("should let a teacher manage only their own templates", func() {
+			Expect(do(http.MethodPost, "/api/teachers", `{"email": "teacher2@gmail.com"}`).Code).Should(Equal(http.StatusNoContent))
+			rec := do(http.MethodPost, "/api/teachers/teacher2@gmail.com/templates", `{"name": "welcome", "body": "Hi"}`)
+			Expect(rec.Code).Should(Equal(http.StatusCreated))
+			created := map[string]interface{}{}
+			Expect(json.Unmarshal(rec.Body.Bytes(), &created)).Should(Succeed())
+
+			Expect(do(http.MethodPost, "/api/users", `{"email": "teacher1@gmail.com", "password": "password123", "role": "teacher"}`).Code).Should(Equal(http.StatusCreated))
+			rec = do(http.MethodPost, "/auth/login", `{"email": "teacher1@gmail.com", "password": "password123"}`)
+			token = rec.Header().Get("Token")
+
+			Expect(do(http.MethodGet, "/api/teachers/teacher2@gmail.com/templates", "").Code).Should(Equal(http.StatusForbidden))
+			Expect(do(http.MethodGet, fmt.Sprintf("/api/teachers/teacher1@gmail.com/templates/%v", created["id"]), "").Code).Should(Equal(http.StatusNotFound))
+			rec = do(http.MethodPost, "/api/retrievefornotifications", fmt.Sprintf(`{"teacher": "teacher1@gmail.com", "template_id": %v}`, created["id"]))
+			Expect(rec.Code).Should(Equal(http.StatusNotFound))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"code":"template_not_found"`))
+		})
+	})
+	Describe("preferences", func() {
+		It("should replace the preferences of a student", func() {
+			rec := do(http.MethodGet, "/api/students/student1@gmail.com/preferences", "")
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+			Expect(rec.Body.String()).Should(MatchJSON(`{
+				"student": "student1@gmail.com",
+				"unsubscribed": false,
+				"broadcast_opt_out": false,
+				"digest": "immediate",
+				"timezone": "UTC"
+			}`))
+
+			rec = do(http.MethodPut, "/api/students/student1@gmail.com/preferences",
+				`{"broadcast_opt_out": true, "digest": "daily", "quiet_start": "22:00", "quiet_end": "07:00", "timezone": "Asia/Singapore"}`)
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"broadcast_opt_out":true,"digest":"daily","quiet_start":"22:00","quiet_end":"07:00","timezone":"Asia/Singapore"`))
+
+			rec = do(http.MethodPost, "/api/retrievefornotifications", `{"teacher": "teacher1@gmail.com", "notification": "Hello students"}`)
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+			Expect(rec.Body.String()).ShouldNot(ContainSubstring("student1@gmail.com"))
+			rec = do(http.MethodPost, "/api/retrievefornotifications", `{"teacher": "teacher1@gmail.com", "notification": "Hello @student1@gmail.com"}`)
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+			Expect(rec.Body.String()).Should(ContainSubstring("student1@gmail.com"))
+		})
+		It("should reject invalid preferences", func() {
+			rec := do(http.MethodPut, "/api/students/student1@gmail.com/preferences", `{"digest": "hourly", "quiet_start": "22:00", "timezone": "Mars/Olympus"}`)
+			Expect(rec.Code).Should(Equal(http.StatusUnprocessableEntity))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"field":"digest"`))
+
+			rec = do(http.MethodPut, "/api/students/student1@gmail.com/preferences", `{"quiet_start": "22:00", "timezone": "Mars/Olympus"}`)
+			Expect(rec.Code).Should(Equal(http.StatusUnprocessableEntity))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"field":"quiet_end"`))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"field":"timezone"`))
+		})
+		It("should let a student manage only their own preferences", func() {
+			Expect(do(http.MethodPost, "/api/users", `{"email": "student1@gmail.com", "password": "password123", "role": "student"}`).Code).Should(Equal(http.StatusCreated))
+			rec := do(http.MethodPost, "/auth/login", `{"email": "student1@gmail.com", "password": "password123"}`)
+			token = rec.Header().Get("Token")
+
+			Expect(do(http.MethodPut, "/api/students/student1@gmail.com/preferences", `{"unsubscribed": true}`).Code).Should(Equal(http.StatusOK))
+			Expect(do(http.MethodGet, "/api/students/student2@gmail.com/preferences", "").Code).Should(Equal(http.StatusForbidden))
+		})
+	})
+	Describe("classes", func() {
+		It("should create classes, enroll students and target them", func() {
+			Expect(do(http.MethodPost, "/api/students", `{"email": "student2@gmail.com"}`).Code).Should(Equal(http.StatusNoContent))
+
+			rec := do(http.MethodPost, "/api/teachers/teacher1@gmail.com/classes", `{"code": "MATH101", "title": "Algebra"}`)
+			Expect(rec.Code).Should(Equal(http.StatusCreated))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"teacher":"teacher1@gmail.com","code":"MATH101","title":"Algebra"`))
+			created := map[string]interface{}{}
+			Expect(json.Unmarshal(rec.Body.Bytes(), &created)).Should(Succeed())
+			target := fmt.Sprintf("/api/teachers/teacher1@gmail.com/classes/%v", created["id"])
+			Expect(do(http.MethodPost, "/api/teachers/teacher1@gmail.com/classes", `{"code": "MATH101"}`).Code).Should(Equal(http.StatusConflict))
+
+			Expect(do(http.MethodPost, target+"/students", `{"students": ["student2@gmail.com"]}`).Code).Should(Equal(http.StatusNoContent))
+			rec = do(http.MethodGet, target, "")
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"students":["student2@gmail.com"]`))
+
+			rec = do(http.MethodGet, "/api/teachers/teacher1@gmail.com/classes", "")
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"code":"default"`))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"code":"MATH101"`))
+
+			rec = do(http.MethodGet, "/api/commonstudents?teacher=teacher1%40gmail.com", "")
+			Expect(rec.Body.String()).Should(MatchJSON(`{"students": ["student1@gmail.com", "student2@gmail.com"]}`))
+			rec = do(http.MethodGet, fmt.Sprintf("/api/commonstudents?class=%v", created["id"]), "")
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+			Expect(rec.Body.String()).Should(MatchJSON(`{"students": ["student2@gmail.com"]}`))
+
+			rec = do(http.MethodPost, "/api/retrievefornotifications", fmt.Sprintf(`{"teacher": "teacher1@gmail.com", "notification": "Hello", "class": %v}`, created["id"]))
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+			Expect(rec.Body.String()).Should(MatchJSON(`{"recipients": ["student2@gmail.com"], "unknown_mentions": []}`))
+
+			rec = do(http.MethodDelete, target+"/students", `{"students": ["student1@gmail.com", "student2@gmail.com"]}`)
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+			Expect(rec.Body.String()).Should(MatchJSON(`{"unenrolled": ["student2@gmail.com"]}`))
+		})
+		It("GetCommonStudents should reject class ids that are not integers", func() {
+			rec := do(http.MethodGet, "/api/commonstudents?class=1&class=abc", "")
+			Expect(rec.Code).Should(Equal(http.StatusUnprocessableEntity))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"field":"class[1]"`))
+			Expect(rec.Body.String()).Should(ContainSubstring("must be an integer"))
+		})
+		It("should reject classes of other teachers", func() {
+			Expect(do(http.MethodPost, "/api/teachers", `{"email": "teacher2@gmail.com"}`).Code).Should(Equal(http.StatusNoContent))
+			rec := do(http.MethodPost, "/api/teachers/teacher2@gmail.com/classes", `{"code": "ART"}`)
+			Expect(rec.Code).Should(Equal(http.StatusCreated))
+			created := map[string]interface{}{}
+			Expect(json.Unmarshal(rec.Body.Bytes(), &created)).Should(Succeed())
+
+			rec = do(http.MethodPost, fmt.Sprintf("/api/teachers/teacher1@gmail.com/classes/%v/students", created["id"]), `{"students": ["student1@gmail.com"]}`)
+			Expect(rec.Code).Should(Equal(http.StatusNotFound))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"code":"class_not_found"`))
+
+			rec = do(http.MethodPost, "/api/retrievefornotifications", fmt.Sprintf(`{"teacher": "teacher1@gmail.com", "notification": "Hello", "class": %v}`, created["id"]))
+			Expect(rec.Code).Should(Equal(http.StatusNotFound))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"code":"class_not_found"`))
+		})
+	})
+	Describe("terms", func() {
+		It("should create terms and roll registrations over to them", func() {
+			rec := do(http.MethodPost, "/api/terms", `{"code": "2020-1", "name": "Spring 2020", "starts_on": "2020-01-06", "ends_on": "2020-05-29"}`)
+			Expect(rec.Code).Should(Equal(http.StatusCreated))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"code":"2020-1","name":"Spring 2020","starts_on":"2020-01-06","ends_on":"2020-05-29"`))
+			Expect(do(http.MethodPost, "/api/terms", `{"code": "2020-1", "starts_on": "2020-01-06", "ends_on": "2020-05-29"}`).Code).Should(Equal(http.StatusConflict))
+			rec = do(http.MethodPost, "/api/terms", `{"code": "2020-2", "starts_on": "2020-09-01", "ends_on": "2020-06-01"}`)
+			Expect(rec.Code).Should(Equal(http.StatusUnprocessableEntity))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"field":"ends_on"`))
+
+			// no term includes today so the classes without a term stay active
+			rec = do(http.MethodGet, "/api/terms", "")
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"active":""`))
+
+			rec = do(http.MethodPost, "/api/terms/rollover", `{"to": "2020-1", "mode": "copy"}`)
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+			Expect(rec.Body.String()).Should(MatchJSON(`{"from": "", "to": "2020-1", "mode": "copy", "archived": 0}`))
+			rec = do(http.MethodGet, "/api/commonstudents?teacher=teacher1%40gmail.com&term=2020-1", "")
+			Expect(rec.Body.String()).Should(MatchJSON(`{"students": ["student1@gmail.com"]}`))
+
+			rec = do(http.MethodPost, "/api/terms/rollover", `{"mode": "archive"}`)
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"archived":1`))
+			rec = do(http.MethodGet, "/api/commonstudents?teacher=teacher1%40gmail.com", "")
+			Expect(rec.Body.String()).Should(MatchJSON(`{"students": []}`))
+
+			rec = do(http.MethodPost, "/api/terms/rollover", `{"to": "2021-1", "mode": "copy"}`)
+			Expect(rec.Code).Should(Equal(http.StatusNotFound))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"code":"term_not_found"`))
+			Expect(do(http.MethodPost, "/api/terms/rollover", `{"mode": "move"}`).Code).Should(Equal(http.StatusUnprocessableEntity))
+		})
+	})
+	Describe("scheduled notifications", func() {
+		It("should schedule, list and cancel the notifications of a teacher", func() {
+			sendAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+			rec := do(http.MethodPost, "/api/retrievefornotifications", `{"teacher": "teacher1@gmail.com", "notification": "Hello students", "send_at": "`+sendAt+`"}`)
+			Expect(rec.Code).Should(Equal(http.StatusAccepted))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"teacher":"teacher1@gmail.com","message":"Hello students","send_at":"` + sendAt + `","status":"pending"`))
+			created := map[string]interface{}{}
+			Expect(json.Unmarshal(rec.Body.Bytes(), &created)).Should(Succeed())
+			target := fmt.Sprintf("/api/teachers/teacher1@gmail.com/scheduled-notifications/%v", created["id"])
+
+			rec = do(http.MethodGet, "/api/teachers/teacher1@gmail.com/scheduled-notifications", "")
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"message":"Hello students"`))
+
+			Expect(do(http.MethodDelete, target, "").Code).Should(Equal(http.StatusNoContent))
+			rec = do(http.MethodDelete, target, "")
+			Expect(rec.Code).Should(Equal(http.StatusConflict))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"code":"scheduled_notification_not_pending"`))
+
+			rec = do(http.MethodGet, "/api/teachers/teacher1@gmail.com/scheduled-notifications", "")
+			Expect(rec.Body.String()).Should(MatchJSON(`{"scheduled_notifications": []}`))
+		})
+		It("should reject a send date in the past", func() {
+			sendAt := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+			rec := do(http.MethodPost, "/api/retrievefornotifications", `{"teacher": "teacher1@gmail.com", "notification": "Hello", "send_at": "`+sendAt+`"}`)
+			Expect(rec.Code).Should(Equal(http.StatusUnprocessableEntity))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"field":"send_at"`))
+
+			rec = do(http.MethodPost, "/api/retrievefornotifications", `{"teacher": "teacher1@gmail.com", "notification": "Hello", "send_at": "tomorrow"}`)
+			Expect(rec.Code).Should(Equal(http.StatusUnprocessableEntity))
+		})
+	})
+	Describe("tokens", func() {
+		refresh := func(rt string) *httptest.ResponseRecorder {
+			token = ""
+			return do(http.MethodPost, "/auth/refresh", `{"refresh_token": "`+rt+`"}`)
+		}
+
+		It("Refresh should rotate the refresh token", func() {
+			rec := refresh(refreshToken)
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+			token = rec.Header().Get("Token")
+			Expect(do(http.MethodGet, "/api/commonstudents?teacher=teacher1%40gmail.com", "").Code).Should(Equal(http.StatusOK))
+
+			next := refreshTokenOf(rec)
+			Expect(next).ShouldNot(Equal(refreshToken))
+			Expect(refresh(next).Code).Should(Equal(http.StatusOK))
+		})
+		It("Refresh should revoke the whole family when a refresh token is reused", func() {
+			rec := refresh(refreshToken)
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+			access, next := rec.Header().Get("Token"), refreshTokenOf(rec)
+
+			rec = refresh(refreshToken)
+			Expect(rec.Code).Should(Equal(http.StatusUnauthorized))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"code":"refresh_token_reused"`))
+
+			Expect(refresh(next).Code).Should(Equal(http.StatusUnauthorized))
+			token = access
+			Expect(do(http.MethodGet, "/api/commonstudents?teacher=teacher1%40gmail.com", "").Code).Should(Equal(http.StatusUnauthorized))
+		})
+		It("Refresh should not accept access tokens", func() {
+			Expect(refresh(token).Code).Should(Equal(http.StatusUnauthorized))
+		})
+		It("Logout should revoke the access and refresh tokens", func() {
+			Expect(do(http.MethodPost, "/auth/logout", "").Code).Should(Equal(http.StatusNoContent))
+			rec := do(http.MethodGet, "/api/commonstudents?teacher=teacher1%40gmail.com", "")
+			Expect(rec.Code).Should(Equal(http.StatusUnauthorized))
+			Expect(rec.Body.String()).Should(ContainSubstring(`"code":"token_revoked"`))
+			Expect(refresh(refreshToken).Code).Should(Equal(http.StatusUnauthorized))
+		})
+	})
+	Describe("access tokens", func() {
+		sign := func(kid, secret string, claims jwt.MapClaims) string {
+			t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+			if kid != "" {
+				t.Header["kid"] = kid
+			}
+			s, err := t.SignedString([]byte(secret))
+			Expect(err).ShouldNot(HaveOccurred())
+			return s
+		}
+		adminClaims := func() jwt.MapClaims {
+			return jwt.MapClaims{
+				"sub":   "1",
+				"email": "admin@gmail.com",
+				"role":  "admin",
+				"exp":   time.Now().Add(time.Minute).Unix(),
+			}
+		}
+		get := func() int {
+			return do(http.MethodGet, "/api/commonstudents?teacher=teacher1%40gmail.com", "").Code
+		}
+
+		It("should still accept the Token header", func() {
+			req := httptest.NewRequest(http.MethodGet, "/api/commonstudents?teacher=teacher1%40gmail.com", nil)
+			req.Header.Set("Token", token)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			Expect(rec.Code).Should(Equal(http.StatusOK))
+		})
+		It("should accept tokens without jti and fam claims", func() {
+			token = sign("", "testSecret", adminClaims())
+			Expect(get()).Should(Equal(http.StatusOK))
+		})
+		It("should reject expired tokens and tokens without expiry", func() {
+			claims := adminClaims()
+			claims["exp"] = time.Now().Add(-time.Minute).Unix()
+			token = sign("", "testSecret", claims)
+			Expect(get()).Should(Equal(http.StatusUnauthorized))
+
+			delete(claims, "exp")
+			token = sign("", "testSecret", claims)
+			Expect(get()).Should(Equal(http.StatusUnauthorized))
+		})
+		It("should reject tokens that are not valid yet", func() {
+			claims := adminClaims()
+			claims["nbf"] = time.Now().Add(time.Minute).Unix()
+			token = sign("", "testSecret", claims)
+			Expect(get()).Should(Equal(http.StatusUnauthorized))
+		})
+		It("should reject tokens signed with another secret or unknown kid", func() {
+			token = sign("", "otherSecret", adminClaims())
+			Expect(get()).Should(Equal(http.StatusUnauthorized))
+			token = sign("unknown", "testSecret", adminClaims())
+			Expect(get()).Should(Equal(http.StatusUnauthorized))
+		})
+
+		Context("with issuer, audience and rotated keys", func() {
+			BeforeEach(func() {
+				cfg.JWT.Issuer = "student-reg-svc"
+				cfg.JWT.Audience = "api"
+				cfg.JWT.KeyID = "2023-10"
+				cfg.JWT.Keys = []config.JWTKeyConfig{
+					{ID: "2023-09", Secret: "previousSecret"},
+					{ID: "2023-10", Secret: "currentSecret"},
+				}
+			})
+
+			It("should issue tokens that pass the checks", func() {
+				Expect(get()).Should(Equal(http.StatusOK))
+			})
+			It("should accept tokens of the previous key", func() {
+				claims := adminClaims()
+				claims["iss"], claims["aud"] = "student-reg-svc", "api"
+				token = sign("2023-09", "previousSecret", claims)
+				Expect(get()).Should(Equal(http.StatusOK))
+			})
+			It("should reject a wrong issuer or audience", func() {
+				claims := adminClaims()
+				claims["iss"], claims["aud"] = "someone-else", "api"
+				token = sign("2023-10", "currentSecret", claims)
+				Expect(get()).Should(Equal(http.StatusUnauthorized))
+
+				claims["iss"], claims["aud"] = "student-reg-svc", "other"
+				token = sign("2023-10", "currentSecret", claims)
+				Expect(get()).Should(Equal(http.StatusUnauthorized))
+			})
+		})
+	})
+})
@@ -4,16 +4,87 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"github.com/golang-jwt/jwt"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/services"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 )
 
-// respondWithError response error to user
-func (h *Handler) respondWithError(w http.ResponseWriter, message string, status int) {
-	h.response(w, map[string]string{"message": message}, status)
+// errorStatus maps the kind of a service error to its HTTP status code
+var errorStatus = map[services.Kind]int{
+	services.KindNotFound:      http.StatusNotFound,
+	services.KindAlreadyExists: http.StatusConflict,
+	services.KindConflict:      http.StatusConflict,
+	services.KindSuspended:     http.StatusConflict,
+	services.KindValidation:    http.StatusUnprocessableEntity,
+	services.KindUnauthorized:  http.StatusUnauthorized,
+	services.KindInternal:      http.StatusInternalServerError,
+}
+
+// problem is an RFC 7807 problem details object. Code is a stable machine-readable
+// identifier of the error and Errors lists the offending fields of validation errors.
+type problem struct {
+	Type     string                `json:"type"`
+	Title    string                `json:"title"`
+	Status   int                   `json:"status"`
+	Detail   string                `json:"detail,omitempty"`
+	Instance string                `json:"instance,omitempty"`
+	Code     string                `json:"code"`
+	Errors   []services.FieldError `json:"errors,omitempty"`
+}
+
+// respondWithError response error to user as application/problem+json
+func (h *Handler) respondWithError(w http.ResponseWriter, r *http.Request, code string, message string, status int) {
+	h.respondWithProblem(w, problem{
+		Type:     "/problems/" + code,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   message,
+		Instance: r.URL.RequestURI(),
+		Code:     code,
+	})
+}
+
+// respondWithServiceError responds with the problem matching an error returned by the service
+func (h *Handler) respondWithServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	var svcErr *services.Error
+	if !errors.As(err, &svcErr) {
+		svcErr = services.Internal(err)
+	}
+	if svcErr.Kind == services.KindInternal {
+		// don't leak the details of unexpected errors to the client
+		h.logger.Errorf("internal error: %v", err)
+	}
+
+	status, ok := errorStatus[svcErr.Kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+	h.respondWithProblem(w, problem{
+		Type:     "/problems/" + svcErr.Code,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   svcErr.Error(),
+		Instance: r.URL.RequestURI(),
+		Code:     svcErr.Code,
+		Errors:   svcErr.Fields,
+	})
+}
+
+// respondWithProblem writes p with the application/problem+json content type
+func (h *Handler) respondWithProblem(w http.ResponseWriter, p problem) {
+	response, err := json.Marshal(p)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		io.WriteString(w, `{"error": "Internal server error"}`)
+		return
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	io.WriteString(w, string(response))
 }
 
 // respondWithJSON response to user
@@ -57,21 +128,64 @@ func (h *Handler) restoreRequestBody(r *http.Request, bodyBytes []byte) {
 	r.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
 }
 
-// generateJWT generates JWT tokens for authentication.
-func (h *Handler) generateJWT() (string, error) {
+// generateJWT generates JWT access tokens for authentication. The claims identify the user, carry their
+// role and the token family the token belongs to.
+func (h *Handler) generateJWT(user models.User, family string) (string, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
+
 	token := jwt.New(jwt.SigningMethodHS256)
 
 	claims := token.Claims.(jwt.MapClaims)
 
 	claims["authorized"] = true
-	claims["client"] = "client"
-	claims["exp"] = time.Now().Add(time.Minute * 30).Unix()
+	claims["sub"] = strconv.Itoa(user.ID)
+	claims["email"] = user.Email
+	claims["role"] = user.Role.String()
+	claims["jti"] = jti
+	claims["fam"] = family
+	claims["typ"] = tokenTypeAccess
+	claims["iat"] = time.Now().Unix()
+	claims["exp"] = time.Now().Add(h.accessTokenTTL()).Unix()
+	h.setIssuerClaims(claims)
 
-	tokenString, err := token.SignedString([]byte(h.cfg.JWT.Secret))
+	tokenString, err := h.keys.sign(token)
 	if err != nil {
-		fmt.Errorf("Something Went Wrong: %s", err.Error())
+		h.logger.Errorf("Something Went Wrong: %s", err.Error())
 		return "", err
 	}
 
 	return tokenString, nil
 }
+
+// principalFromClaims builds the Principal described by the claims set by generateJWT
+func principalFromClaims(claims map[string]interface{}) (Principal, error) {
+	sub, _ := claims["sub"].(string)
+	id, err := strconv.Atoi(sub)
+	if err != nil {
+		return Principal{}, errors.New("token has an invalid subject")
+	}
+	// tokens of external issuers may leave typ out, but refresh tokens are never accepted
+	if typ, _ := claims["typ"].(string); typ != "" && typ != tokenTypeAccess {
+		return Principal{}, errors.New("not an access token")
+	}
+	email, _ := claims["email"].(string)
+	name, _ := claims["role"].(string)
+	role := models.ParseRole(name)
+	if email == "" || role == 0 {
+		return Principal{}, errors.New("token is missing the user claims")
+	}
+	jti, _ := claims["jti"].(string)
+	family, _ := claims["fam"].(string)
+	exp, _ := claims["exp"].(float64)
+	return Principal{
+		ID:        id,
+		Email:     email,
+		Role:      role,
+		TokenID:   jti,
+		Family:    family,
+		ExpiresAt: time.Unix(int64(exp), 0),
+	}, nil
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt"
+	"github.com/sirupsen/logrus"
+	"github.com/whittier16/student-reg-svc/internal/app/config"
+	"strings"
+	"time"
+)
+
+// verificationKey is an access token key together with the only algorithm it may be used with,
+// which keeps tokens from switching e.g. an RS256 public key to be used as an HS256 secret.
+type verificationKey struct {
+	method jwt.SigningMethod
+	key    interface{}
+}
+
+// keySet holds the access token keys by kid. The key without kid is the configured Secret.
+type keySet struct {
+	signingID string
+	keys      map[string]verificationKey
+}
+
+// newKeySet builds the keys configured in cfg. Invalid keys are logged and left out, so
+// tokens referring to them are rejected.
+func newKeySet(cfg config.JWTConfig, log *logrus.Logger) keySet {
+	ks := keySet{
+		signingID: cfg.KeyID,
+		keys: map[string]verificationKey{
+			"": {method: jwt.SigningMethodHS256, key: []byte(cfg.Secret)},
+		},
+	}
+
+	for _, k := range cfg.Keys {
+		key, err := parseKey(k)
+		if err != nil {
+			log.Errorf("invalid JWT key %q: %v", k.ID, err)
+			continue
+		}
+		ks.keys[k.ID] = key
+	}
+
+	if signing, ok := ks.keys[ks.signingID]; !ok || signing.method != jwt.SigningMethodHS256 {
+		log.Errorf("JWT signing key %q is not a configured HS256 key, signing with the default secret", ks.signingID)
+		ks.signingID = ""
+	}
+	return ks
+}
+
+// parseKey converts a configured key to a verificationKey
+func parseKey(k config.JWTKeyConfig) (verificationKey, error) {
+	if k.ID == "" {
+		return verificationKey{}, errors.New("key id is required")
+	}
+
+	switch strings.ToUpper(k.Algorithm) {
+	case "", "HS256":
+		if k.Secret == "" {
+			return verificationKey{}, errors.New("secret is required")
+		}
+		return verificationKey{method: jwt.SigningMethodHS256, key: []byte(k.Secret)}, nil
+	case "RS256":
+		key, err := jwt.ParseRSAPublicKeyFromPEM([]byte(k.PublicKey))
+		if err != nil {
+			return verificationKey{}, err
+		}
+		return verificationKey{method: jwt.SigningMethodRS256, key: key}, nil
+	case "ES256":
+		key, err := jwt.ParseECPublicKeyFromPEM([]byte(k.PublicKey))
+		if err != nil {
+			return verificationKey{}, err
+		}
+		return verificationKey{method: jwt.SigningMethodES256, key: key}, nil
+	default:
+		return verificationKey{}, fmt.Errorf("unsupported algorithm %q", k.Algorithm)
+	}
+}
+
+// sign signs the access token with the current signing key and sets its kid header
+func (ks keySet) sign(token *jwt.Token) (string, error) {
+	if ks.signingID != "" {
+		token.Header["kid"] = ks.signingID
+	}
+	return token.SignedString(ks.keys[ks.signingID].key)
+}
+
+// keyFunc returns the key matching the kid header of the token
+func (ks keySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	k, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != k.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+	}
+	return k.key, nil
+}
+
+// verifyClaims checks the registered claims of a verified token. exp is required, nbf and iat
+// are checked by the parser and iss and aud are required when configured.
+func verifyClaims(claims jwt.MapClaims, cfg config.JWTConfig) error {
+	now := time.Now().Unix()
+	if !claims.VerifyExpiresAt(now, true) {
+		return errors.New("token is expired or has no expiry")
+	}
+	if cfg.Issuer != "" && !claims.VerifyIssuer(cfg.Issuer, true) {
+		return errors.New("token has an invalid issuer")
+	}
+	if cfg.Audience != "" && !claims.VerifyAudience(cfg.Audience, true) {
+		return errors.New("token has an invalid audience")
+	}
+	return nil
+}
@@ -2,8 +2,8 @@ package handlers
 
 import (
 	"fmt"
-	jwt "github.com/dgrijalva/jwt-go"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -49,8 +49,9 @@ func (h *Handler) CORSMiddleware() func(http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
 			w.Header().Set("Access-Control-Allow-Credentials", "true")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS, PUT, DELETE")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS, PUT, PATCH, DELETE")
 			w.Header().Set("Access-Control-Allow-Headers", "Origin, Accept, Content-Type, Authorization, Cache-Control")
+			w.Header().Set("Access-Control-Expose-Headers", totalCountHeader)
 			if r.Method == "OPTIONS" {
 				w.WriteHeader(http.StatusOK)
 				return
@@ -61,44 +62,56 @@ func (h *Handler) CORSMiddleware() func(http.Handler) http.Handler {
 	}
 }
 
-// AuthMiddleware authorizes requests using valid JWT token from the header
+// AuthMiddleware authorizes requests using valid JWT token from the "Authorization: Bearer" header.
+// The deprecated "Token" header is still accepted. The verified principal is put in the request context.
 func (h *Handler) AuthMiddleware() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
 			if r.URL.Path == "/healthz" ||
-				r.URL.Path == "/auth" {
+				r.URL.Path == "/auth/login" ||
+				r.URL.Path == "/auth/refresh" {
 				// Call the next handler don't log if it is internal request from health check and auth
 				next.ServeHTTP(w, r)
 				return
 			}
 
-			if r.Header["Token"] != nil {
-				requestBody, err := h.readRequestBody(r)
-				if err != nil {
-					h.response(w, err, http.StatusInternalServerError)
-					return
-				}
-				h.restoreRequestBody(r, requestBody)
-
-				token, err := jwt.Parse(r.Header["Token"][0], func(token *jwt.Token) (interface{}, error) {
-					if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-						return nil, fmt.Errorf("there was an error")
-					}
-					return []byte(h.cfg.JWT.Secret), nil
-				})
+			tokenString := bearerToken(r)
+			if tokenString == "" {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				h.respondWithError(w, r, "unauthorized", "Not Authorized", http.StatusUnauthorized)
+				return
+			}
 
-				if err != nil {
-					h.respondWithError(w, err.Error(), http.StatusUnauthorized)
-				}
+			p, err := h.parseAccessToken(tokenString)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				h.respondWithError(w, r, "unauthorized", err.Error(), http.StatusUnauthorized)
+				return
+			}
 
-				if token.Valid {
-					wrapped := wrapResponseWriter(w)
-					next.ServeHTTP(wrapped, r)
-				}
-			} else {
-				h.respondWithError(w, "Not Authorized", http.StatusUnauthorized)
+			revoked, err := h.isRevoked(r.Context(), p)
+			if err != nil {
+				h.respondWithServiceError(w, r, err)
+				return
 			}
+			if revoked {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				h.respondWithError(w, r, "token_revoked", "token was revoked", http.StatusUnauthorized)
+				return
+			}
+
+			wrapped := wrapResponseWriter(w)
+			next.ServeHTTP(wrapped, r.WithContext(withPrincipal(r.Context(), p)))
 		}
 		return http.HandlerFunc(fn)
 	}
 }
+
+// bearerToken returns the token of the Authorization header, falling back to the Token header
+func bearerToken(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if len(auth) > len("Bearer ") && strings.EqualFold(auth[:len("Bearer ")], "Bearer ") {
+		return strings.TrimSpace(auth[len("Bearer "):])
+	}
+	return r.Header.Get("Token")
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/services"
+	"net/http"
+	"time"
+)
+
+// preferenceResponse is the notification preferences of a student
+type preferenceResponse struct {
+	Student         string     `json:"student"`
+	Unsubscribed    bool       `json:"unsubscribed"`
+	BroadcastOptOut bool       `json:"broadcast_opt_out"`
+	Digest          string     `json:"digest"`
+	QuietStart      *string    `json:"quiet_start,omitempty"`
+	QuietEnd        *string    `json:"quiet_end,omitempty"`
+	Timezone        string     `json:"timezone"`
+	UpdatedOn       *time.Time `json:"updated_on,omitempty"`
+}
+
+func newPreferenceResponse(p models.Preference) preferenceResponse {
+	return preferenceResponse{
+		Student:         p.StudentID,
+		Unsubscribed:    p.Unsubscribed,
+		BroadcastOptOut: p.BroadcastOptOut,
+		Digest:          p.Digest,
+		QuietStart:      p.QuietStart,
+		QuietEnd:        p.QuietEnd,
+		Timezone:        p.Timezone,
+		UpdatedOn:       p.UpdatedOn,
+	}
+}
+
+// GetPreferences handles "GET /api/students/{email}/preferences"
+// Retrieves the notification preferences of a student, the defaults when the student never set any.
+// ---
+// Responses:
+//
+//	200:
+//	401:
+//	403:
+//	404:
+//	500:
+func (h *Handler) GetPreferences() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := h.svc.GetPreferences(r.Context(), mux.Vars(r)["email"])
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		h.response(w, newPreferenceResponse(res), http.StatusOK)
+	}
+}
+
+// UpdatePreferences handles "PUT /api/students/{email}/preferences"
+// Replaces the notification preferences of a student. An unsubscribed student receives no
+// notification; a student opting out of broadcasts only receives the notifications mentioning
+// them. The emails of a daily or weekly digest are bundled together, and no email is sent
+// during the quiet hours.
+// ---
+// Responses:
+//
+//	200:
+//	400:
+//	401:
+//	403:
+//	404:
+//	422:
+//	500:
+func (h *Handler) UpdatePreferences() http.HandlerFunc {
+	type request struct {
+		Unsubscribed    bool   `json:"unsubscribed"`
+		BroadcastOptOut bool   `json:"broadcast_opt_out"`
+		Digest          string `json:"digest"`
+		QuietStart      string `json:"quiet_start"`
+		QuietEnd        string `json:"quiet_end"`
+		Timezone        string `json:"timezone"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := request{}
+		// Try to decode the request body into the struct. If there is an error,
+		// respond to the client with the error message and a 400 status code.
+		err := h.decode(r, &req)
+		if err != nil {
+			h.respondWithError(w, r, "invalid_request_body", err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		res, err := h.svc.UpdatePreferences(r.Context(), mux.Vars(r)["email"], services.UpdatePreferencesParams{
+			Unsubscribed:    req.Unsubscribed,
+			BroadcastOptOut: req.BroadcastOptOut,
+			Digest:          req.Digest,
+			QuietStart:      req.QuietStart,
+			QuietEnd:        req.QuietEnd,
+			Timezone:        req.Timezone,
+		})
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		h.response(w, newPreferenceResponse(res), http.StatusOK)
+	}
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"context"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"time"
+)
+
+// Principal is the authenticated user making the request, as described by the JWT claims
+type Principal struct {
+	ID    int
+	Email string
+	Role  models.Role
+	// TokenID, Family and ExpiresAt describe the access token, they are used to revoke it
+	TokenID   string
+	Family    string
+	ExpiresAt time.Time
+}
+
+// principalKey is the context key under which the Principal is stored
+type principalKey struct{}
+
+// withPrincipal returns a copy of ctx carrying p
+func withPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the Principal stored by AuthMiddleware
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
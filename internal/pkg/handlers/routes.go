@@ -4,26 +4,86 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	"github.com/whittier16/student-reg-svc/internal/app/config"
-	"github.com/whittier16/student-reg-svc/internal/pkg/database/cache"
-	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/services"
 	"net/http"
 )
 
 // RegisterRoutes registers the different handlers' route definitions.
-func RegisterRoutes(r *mux.Router, log *logrus.Logger, db *db.MySQL, c *cache.Redis, cfg *config.MainConfig) {
-	h := New(log, db, cfg)
+func RegisterRoutes(r *mux.Router, log *logrus.Logger, svc services.Service, tokens TokenStore, cfg *config.MainConfig) {
+	h := New(log, svc, tokens, cfg)
 
 	// adding middlewares
 	r = addMiddlewares(r, h)
 
 	r.HandleFunc("/healthz", h.Health())
-	r.HandleFunc("/auth", h.Auth())
-	r.HandleFunc("/api/register", h.Register()).Methods(http.MethodPost)
-	r.HandleFunc("/api/commonstudents", h.GetCommonStudents()).Methods(http.MethodGet)
-	r.HandleFunc("/api/suspend", h.Suspend()).Methods(http.MethodPost)
-	r.HandleFunc("/api/retrievefornotifications", h.RetrieveNotifications()).Methods(http.MethodPost)
-	r.HandleFunc("/api/students", h.CreateStudent()).Methods(http.MethodPost)
-	r.HandleFunc("/api/teachers", h.CreateTeacher()).Methods(http.MethodPost)
+	r.HandleFunc("/auth/login", h.Login()).Methods(http.MethodPost)
+	r.HandleFunc("/auth/refresh", h.Refresh()).Methods(http.MethodPost)
+	r.HandleFunc("/auth/logout", h.Logout()).Methods(http.MethodPost)
+
+	// every /api route declares who may call it, administrators are always allowed
+	r.HandleFunc("/api/users", h.authorize(h.CreateUser(), allowRoles())).Methods(http.MethodPost)
+	r.HandleFunc("/api/register", h.authorize(h.Register(),
+		allowRoles(models.RoleTeacher), h.selfBodyField(models.RoleTeacher, "teacher"))).Methods(http.MethodPost)
+	r.HandleFunc("/api/register", h.authorize(h.Unregister(),
+		allowRoles(models.RoleTeacher), h.selfBodyField(models.RoleTeacher, "teacher"))).Methods(http.MethodDelete)
+	r.HandleFunc("/api/commonstudents", h.authorize(h.GetCommonStudents(),
+		allowRoles(models.RoleTeacher))).Methods(http.MethodGet)
+	r.HandleFunc("/api/suspend", h.authorize(h.Suspend(),
+		allowRoles(models.RoleTeacher), h.selfBodyField(models.RoleTeacher, "teacher"))).Methods(http.MethodPost)
+	r.HandleFunc("/api/unsuspend", h.authorize(h.Unsuspend(),
+		allowRoles(models.RoleTeacher), h.selfBodyField(models.RoleTeacher, "teacher"))).Methods(http.MethodPost)
+	r.HandleFunc("/api/retrievefornotifications", h.authorize(h.RetrieveNotifications(),
+		allowRoles(models.RoleTeacher), h.selfBodyField(models.RoleTeacher, "teacher"))).Methods(http.MethodPost)
+	r.HandleFunc("/api/notifications", h.authorize(h.ListNotifications(),
+		allowRoles(models.RoleTeacher), selfQueryParam(models.RoleTeacher, "teacher"))).Methods(http.MethodGet)
+	r.HandleFunc("/api/students", h.authorize(h.ListStudents(), allowRoles(models.RoleTeacher))).Methods(http.MethodGet)
+	r.HandleFunc("/api/students", h.authorize(h.CreateStudent(), allowRoles(models.RoleTeacher))).Methods(http.MethodPost)
+	r.HandleFunc("/api/students/{email}", h.authorize(h.GetStudent(),
+		allowRoles(models.RoleTeacher, models.RoleStudent), selfPathVar(models.RoleStudent, "email"))).Methods(http.MethodGet)
+	r.HandleFunc("/api/students/{email}", h.authorize(h.UpdateStudent(), allowRoles())).Methods(http.MethodPatch)
+	r.HandleFunc("/api/students/{email}", h.authorize(h.DeleteStudent(), allowRoles())).Methods(http.MethodDelete)
+	r.HandleFunc("/api/students/{email}/suspensions", h.authorize(h.GetSuspensions(),
+		allowRoles(models.RoleTeacher, models.RoleStudent), selfPathVar(models.RoleStudent, "email"))).Methods(http.MethodGet)
+	r.HandleFunc("/api/students/{email}/notifications", h.authorize(h.GetStudentNotifications(),
+		allowRoles(models.RoleTeacher, models.RoleStudent), selfPathVar(models.RoleStudent, "email"))).Methods(http.MethodGet)
+	r.HandleFunc("/api/students/{email}/preferences", h.authorize(h.GetPreferences(),
+		allowRoles(models.RoleStudent), selfPathVar(models.RoleStudent, "email"))).Methods(http.MethodGet)
+	r.HandleFunc("/api/students/{email}/preferences", h.authorize(h.UpdatePreferences(),
+		allowRoles(models.RoleStudent), selfPathVar(models.RoleStudent, "email"))).Methods(http.MethodPut)
+	r.HandleFunc("/api/students/{email}/restore", h.authorize(h.RestoreStudent(), allowRoles())).Methods(http.MethodPost)
+	r.HandleFunc("/api/teachers", h.authorize(h.ListTeachers(), allowRoles(models.RoleTeacher))).Methods(http.MethodGet)
+	r.HandleFunc("/api/terms", h.authorize(h.ListTerms(), allowRoles(models.RoleTeacher))).Methods(http.MethodGet)
+	r.HandleFunc("/api/terms", h.authorize(h.CreateTerm(), allowRoles())).Methods(http.MethodPost)
+	r.HandleFunc("/api/terms/rollover", h.authorize(h.RolloverTerm(), allowRoles())).Methods(http.MethodPost)
+	r.HandleFunc("/api/teachers", h.authorize(h.CreateTeacher(), allowRoles())).Methods(http.MethodPost)
+	r.HandleFunc("/api/teachers/{email}", h.authorize(h.GetTeacher(), allowRoles(models.RoleTeacher))).Methods(http.MethodGet)
+	r.HandleFunc("/api/teachers/{email}", h.authorize(h.UpdateTeacher(), allowRoles())).Methods(http.MethodPatch)
+	r.HandleFunc("/api/teachers/{email}", h.authorize(h.DeleteTeacher(), allowRoles())).Methods(http.MethodDelete)
+	r.HandleFunc("/api/teachers/{email}/templates", h.authorize(h.ListTemplates(),
+		allowRoles(models.RoleTeacher), selfPathVar(models.RoleTeacher, "email"))).Methods(http.MethodGet)
+	r.HandleFunc("/api/teachers/{email}/templates", h.authorize(h.CreateTemplate(),
+		allowRoles(models.RoleTeacher), selfPathVar(models.RoleTeacher, "email"))).Methods(http.MethodPost)
+	r.HandleFunc("/api/teachers/{email}/templates/{id:[0-9]+}", h.authorize(h.GetTemplate(),
+		allowRoles(models.RoleTeacher), selfPathVar(models.RoleTeacher, "email"))).Methods(http.MethodGet)
+	r.HandleFunc("/api/teachers/{email}/templates/{id:[0-9]+}", h.authorize(h.UpdateTemplate(),
+		allowRoles(models.RoleTeacher), selfPathVar(models.RoleTeacher, "email"))).Methods(http.MethodPatch)
+	r.HandleFunc("/api/teachers/{email}/templates/{id:[0-9]+}", h.authorize(h.DeleteTemplate(),
+		allowRoles(models.RoleTeacher), selfPathVar(models.RoleTeacher, "email"))).Methods(http.MethodDelete)
+	r.HandleFunc("/api/teachers/{email}/classes", h.authorize(h.ListClasses(),
+		allowRoles(models.RoleTeacher), selfPathVar(models.RoleTeacher, "email"))).Methods(http.MethodGet)
+	r.HandleFunc("/api/teachers/{email}/classes", h.authorize(h.CreateClass(),
+		allowRoles(models.RoleTeacher), selfPathVar(models.RoleTeacher, "email"))).Methods(http.MethodPost)
+	r.HandleFunc("/api/teachers/{email}/classes/{id:[0-9]+}", h.authorize(h.GetClass(),
+		allowRoles(models.RoleTeacher), selfPathVar(models.RoleTeacher, "email"))).Methods(http.MethodGet)
+	r.HandleFunc("/api/teachers/{email}/classes/{id:[0-9]+}/students", h.authorize(h.EnrollStudents(),
+		allowRoles(models.RoleTeacher), selfPathVar(models.RoleTeacher, "email"))).Methods(http.MethodPost)
+	r.HandleFunc("/api/teachers/{email}/classes/{id:[0-9]+}/students", h.authorize(h.UnenrollStudents(),
+		allowRoles(models.RoleTeacher), selfPathVar(models.RoleTeacher, "email"))).Methods(http.MethodDelete)
+	r.HandleFunc("/api/teachers/{email}/scheduled-notifications", h.authorize(h.ListScheduledNotifications(),
+		allowRoles(models.RoleTeacher), selfPathVar(models.RoleTeacher, "email"))).Methods(http.MethodGet)
+	r.HandleFunc("/api/teachers/{email}/scheduled-notifications/{id:[0-9]+}", h.authorize(h.CancelScheduledNotification(),
+		allowRoles(models.RoleTeacher), selfPathVar(models.RoleTeacher, "email"))).Methods(http.MethodDelete)
 }
 
 // addMiddlewares adds the necessary middlewares that is essential before/after the handler to be executed
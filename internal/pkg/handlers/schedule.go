@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// scheduledNotificationResponse is a notification queued to be sent later
+type scheduledNotificationResponse struct {
+	ID         int               `json:"id"`
+	Teacher    string            `json:"teacher"`
+	Message    string            `json:"message,omitempty"`
+	TemplateID *int              `json:"template_id,omitempty"`
+	Variables  map[string]string `json:"variables,omitempty"`
+	ClassID    *int              `json:"class_id,omitempty"`
+	SendAt     time.Time         `json:"send_at"`
+	Status     string            `json:"status"`
+	CreatedOn  time.Time         `json:"created_on"`
+}
+
+func newScheduledNotificationResponse(s models.ScheduledNotification) scheduledNotificationResponse {
+	return scheduledNotificationResponse{
+		ID:         s.ID,
+		Teacher:    s.TeacherID,
+		Message:    s.Message,
+		TemplateID: s.TemplateID,
+		Variables:  s.Variables,
+		ClassID:    s.ClassID,
+		SendAt:     s.SendAt,
+		Status:     s.Status,
+		CreatedOn:  s.CreatedOn,
+	}
+}
+
+// ListScheduledNotifications handles "GET /api/teachers/{email}/scheduled-notifications"
+// Lists the pending scheduled notifications of a teacher, the next one first.
+// ---
+// Responses:
+//
+//	200:
+//	401:
+//	403:
+//	404:
+//	500:
+func (h *Handler) ListScheduledNotifications() http.HandlerFunc {
+	type response struct {
+		ScheduledNotifications []scheduledNotificationResponse `json:"scheduled_notifications"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := h.svc.ListScheduledNotifications(r.Context(), mux.Vars(r)["email"])
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		schedules := make([]scheduledNotificationResponse, 0, len(res))
+		for _, s := range res {
+			schedules = append(schedules, newScheduledNotificationResponse(s))
+		}
+		h.response(w, response{ScheduledNotifications: schedules}, http.StatusOK)
+	}
+}
+
+// CancelScheduledNotification handles "DELETE /api/teachers/{email}/scheduled-notifications/{id}"
+// Cancels a pending scheduled notification of a teacher.
+// ---
+// Responses:
+//
+//	204:
+//	401:
+//	403:
+//	404:
+//	409:
+//	500:
+func (h *Handler) CancelScheduledNotification() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// the route restricts the id to digits
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+		err := h.svc.CancelScheduledNotification(r.Context(), mux.Vars(r)["email"], id)
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		h.response(w, "", http.StatusNoContent)
+	}
+}
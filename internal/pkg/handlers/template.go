@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/services"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// templateResponse is a notification template
+type templateResponse struct {
+	ID        int        `json:"id"`
+	Teacher   string     `json:"teacher"`
+	Name      string     `json:"name"`
+	Body      string     `json:"body"`
+	CreatedOn time.Time  `json:"created_on"`
+	UpdatedOn *time.Time `json:"updated_on,omitempty"`
+}
+
+func newTemplateResponse(t models.Template) templateResponse {
+	return templateResponse{
+		ID:        t.ID,
+		Teacher:   t.TeacherID,
+		Name:      t.Name,
+		Body:      t.Body,
+		CreatedOn: t.CreatedOn,
+		UpdatedOn: t.UpdatedOn,
+	}
+}
+
+// templateID reads the id route variable, which the route restricts to digits
+func templateID(r *http.Request) int {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	return id
+}
+
+// ListTemplates handles "GET /api/teachers/{email}/templates"
+// Lists the notification templates of a teacher ordered by name.
+// ---
+// Responses:
+//
+//	200:
+//	401:
+//	403:
+//	404:
+//	500:
+func (h *Handler) ListTemplates() http.HandlerFunc {
+	type response struct {
+		Templates []templateResponse `json:"templates"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := h.svc.ListTemplates(r.Context(), mux.Vars(r)["email"])
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		templates := make([]templateResponse, 0, len(res))
+		for _, t := range res {
+			templates = append(templates, newTemplateResponse(t))
+		}
+		h.response(w, response{Templates: templates}, http.StatusOK)
+	}
+}
+
+// CreateTemplate handles "POST /api/teachers/{email}/templates"
+// Adds a notification template to a teacher.
+// ---
+// Responses:
+//
+//	201:
+//	400:
+//	401:
+//	403:
+//	404:
+//	409:
+//	422:
+//	500:
+func (h *Handler) CreateTemplate() http.HandlerFunc {
+	type request struct {
+		Name string `json:"name"`
+		Body string `json:"body"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := request{}
+		// Try to decode the request body into the struct. If there is an error,
+		// respond to the client with the error message and a 400 status code.
+		err := h.decode(r, &req)
+		if err != nil {
+			h.respondWithError(w, r, "invalid_request_body", err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		res, err := h.svc.CreateTemplate(r.Context(), services.CreateTemplateParams{
+			Teacher: mux.Vars(r)["email"],
+			Name:    req.Name,
+			Body:    req.Body,
+		})
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		h.response(w, newTemplateResponse(res), http.StatusCreated)
+	}
+}
+
+// GetTemplate handles "GET /api/teachers/{email}/templates/{id}"
+// Retrieves a notification template of a teacher.
+// ---
+// Responses:
+//
+//	200:
+//	401:
+//	403:
+//	404:
+//	500:
+func (h *Handler) GetTemplate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := h.svc.GetTemplate(r.Context(), mux.Vars(r)["email"], templateID(r))
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		h.response(w, newTemplateResponse(res), http.StatusOK)
+	}
+}
+
+// UpdateTemplate handles "PATCH /api/teachers/{email}/templates/{id}"
+// Changes the name or body of a notification template of a teacher.
+// ---
+// Responses:
+//
+//	200:
+//	400:
+//	401:
+//	403:
+//	404:
+//	409:
+//	422:
+//	500:
+func (h *Handler) UpdateTemplate() http.HandlerFunc {
+	type request struct {
+		Name *string `json:"name"`
+		Body *string `json:"body"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := request{}
+		// Try to decode the request body into the struct. If there is an error,
+		// respond to the client with the error message and a 400 status code.
+		err := h.decode(r, &req)
+		if err != nil {
+			h.respondWithError(w, r, "invalid_request_body", err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		res, err := h.svc.UpdateTemplate(r.Context(), mux.Vars(r)["email"], templateID(r), services.UpdateTemplateParams{
+			Name: req.Name,
+			Body: req.Body,
+		})
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		h.response(w, newTemplateResponse(res), http.StatusOK)
+	}
+}
+
+// DeleteTemplate handles "DELETE /api/teachers/{email}/templates/{id}"
+// Removes a notification template of a teacher. The notifications sent from it are kept.
+// ---
+// Responses:
+//
+//	204:
+//	401:
+//	403:
+//	404:
+//	500:
+func (h *Handler) DeleteTemplate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := h.svc.DeleteTemplate(r.Context(), mux.Vars(r)["email"], templateID(r))
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		h.response(w, "", http.StatusNoContent)
+	}
+}
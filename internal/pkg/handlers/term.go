@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/services"
+	"net/http"
+	"time"
+)
+
+// termResponse is an academic term, its dates formatted as 2006-01-02
+type termResponse struct {
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	StartsOn  string    `json:"starts_on"`
+	EndsOn    string    `json:"ends_on"`
+	CreatedOn time.Time `json:"created_on"`
+}
+
+func newTermResponse(t models.Term) termResponse {
+	return termResponse{
+		Code:      t.Code,
+		Name:      t.Name,
+		StartsOn:  t.StartsOn.Format(models.TermDateLayout),
+		EndsOn:    t.EndsOn.Format(models.TermDateLayout),
+		CreatedOn: t.CreatedOn,
+	}
+}
+
+// ListTerms handles "GET /api/terms"
+// Lists the academic terms ordered by start date, and the code of the active term which is empty
+// when the classes without a term are active.
+// ---
+// Responses:
+//
+//	200:
+//	401:
+//	403:
+//	500:
+func (h *Handler) ListTerms() http.HandlerFunc {
+	type response struct {
+		Active string         `json:"active"`
+		Terms  []termResponse `json:"terms"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, active, err := h.svc.ListTerms(r.Context())
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		terms := make([]termResponse, 0, len(res))
+		for _, t := range res {
+			terms = append(terms, newTermResponse(t))
+		}
+		h.response(w, response{Active: active, Terms: terms}, http.StatusOK)
+	}
+}
+
+// CreateTerm handles "POST /api/terms"
+// Adds an academic term running from starts_on to ends_on, both included.
+// ---
+// Responses:
+//
+//	201:
+//	400:
+//	401:
+//	403:
+//	409:
+//	422:
+//	500:
+func (h *Handler) CreateTerm() http.HandlerFunc {
+	type request struct {
+		Code     string `json:"code"`
+		Name     string `json:"name"`
+		StartsOn string `json:"starts_on"`
+		EndsOn   string `json:"ends_on"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := request{}
+		// Try to decode the request body into the struct. If there is an error,
+		// respond to the client with the error message and a 400 status code.
+		err := h.decode(r, &req)
+		if err != nil {
+			h.respondWithError(w, r, "invalid_request_body", err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		res, err := h.svc.CreateTerm(r.Context(), services.CreateTermParams{
+			Code:     req.Code,
+			Name:     req.Name,
+			StartsOn: req.StartsOn,
+			EndsOn:   req.EndsOn,
+		})
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		h.response(w, newTermResponse(res), http.StatusCreated)
+	}
+}
+
+// RolloverTerm handles "POST /api/terms/rollover"
+// Copies the classes of a term and their students to another term, or archives the registrations
+// of a term after optionally copying them. An empty or missing term is the one of the classes
+// without a term.
+// ---
+// Responses:
+//
+//	200:
+//	400:
+//	401:
+//	403:
+//	404:
+//	422:
+//	500:
+func (h *Handler) RolloverTerm() http.HandlerFunc {
+	type request struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+		Mode string `json:"mode"`
+	}
+	type response struct {
+		From     string `json:"from"`
+		To       string `json:"to"`
+		Mode     string `json:"mode"`
+		Archived int    `json:"archived"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := request{}
+		// Try to decode the request body into the struct. If there is an error,
+		// respond to the client with the error message and a 400 status code.
+		err := h.decode(r, &req)
+		if err != nil {
+			h.respondWithError(w, r, "invalid_request_body", err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		res, err := h.svc.RolloverTerm(r.Context(), services.RolloverTermParams{
+			From: req.From,
+			To:   req.To,
+			Mode: req.Mode,
+		})
+		if err != nil {
+			h.respondWithServiceError(w, r, err)
+			return
+		}
+
+		h.response(w, response{
+			From:     res.From,
+			To:       res.To,
+			Mode:     res.Mode,
+			Archived: res.Archived,
+		}, http.StatusOK)
+	}
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"strconv"
+	"time"
+)
+
+const (
+	// tokenTypeAccess and tokenTypeRefresh are the values of the "typ" claim
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+
+	defaultAccessTokenExpireDuration  = 30 * time.Minute
+	defaultRefreshTokenExpireDuration = 7 * 24 * time.Hour
+)
+
+// TokenStore keeps track of issued refresh tokens and revoked tokens. Every login starts a
+// token family that all the tokens obtained by refreshing share, so a stolen refresh token
+// can be dealt with by revoking the whole family. It is implemented by cache.Redis.
+type TokenStore interface {
+	SaveRefreshToken(ctx context.Context, jti, family string, ttl time.Duration) error
+	UseRefreshToken(ctx context.Context, jti string) (family string, reused bool, err error)
+	RevokeFamily(ctx context.Context, family string, ttl time.Duration) error
+	IsFamilyRevoked(ctx context.Context, family string) (bool, error)
+	RevokeToken(ctx context.Context, jti string, ttl time.Duration) error
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// tokenPair is the response body of the login and refresh endpoints
+type tokenPair struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// accessTokenTTL returns the configured lifetime of access tokens
+func (h *Handler) accessTokenTTL() time.Duration {
+	if h.cfg.JWT.AccessTokenExpireDuration > 0 {
+		return time.Duration(h.cfg.JWT.AccessTokenExpireDuration) * time.Minute
+	}
+	return defaultAccessTokenExpireDuration
+}
+
+// refreshTokenTTL returns the configured lifetime of refresh tokens
+func (h *Handler) refreshTokenTTL() time.Duration {
+	if h.cfg.JWT.RefreshTokenExpireDuration > 0 {
+		return time.Duration(h.cfg.JWT.RefreshTokenExpireDuration) * time.Minute
+	}
+	return defaultRefreshTokenExpireDuration
+}
+
+// issueTokens generates an access and a refresh token of family for the user and records the refresh token
+func (h *Handler) issueTokens(ctx context.Context, user models.User, family string) (tokenPair, error) {
+	access, err := h.generateJWT(user, family)
+	if err != nil {
+		return tokenPair{}, err
+	}
+
+	jti, err := newTokenID()
+	if err != nil {
+		return tokenPair{}, err
+	}
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   strconv.Itoa(user.ID),
+		"email": user.Email,
+		"jti":   jti,
+		"fam":   family,
+		"typ":   tokenTypeRefresh,
+		"iat":   now.Unix(),
+		"exp":   now.Add(h.refreshTokenTTL()).Unix(),
+	})
+	h.setIssuerClaims(token.Claims.(jwt.MapClaims))
+	refresh, err := token.SignedString([]byte(h.cfg.JWT.RefreshSecret))
+	if err != nil {
+		return tokenPair{}, err
+	}
+	if err := h.tokens.SaveRefreshToken(ctx, jti, family, h.refreshTokenTTL()); err != nil {
+		return tokenPair{}, err
+	}
+
+	return tokenPair{
+		Token:        access,
+		RefreshToken: refresh,
+		ExpiresIn:    int(h.accessTokenTTL().Seconds()),
+	}, nil
+}
+
+// parseRefreshToken verifies the refresh token and returns its jti, family and user email
+func (h *Handler) parseRefreshToken(tokenString string) (jti, family, email string, err error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		return []byte(h.cfg.JWT.RefreshSecret), nil
+	})
+	if err != nil {
+		return "", "", "", err
+	}
+
+	claims, _ := token.Claims.(jwt.MapClaims)
+	if err := verifyClaims(claims, h.cfg.JWT); err != nil {
+		return "", "", "", err
+	}
+	if typ, _ := claims["typ"].(string); typ != tokenTypeRefresh {
+		return "", "", "", errors.New("not a refresh token")
+	}
+	jti, _ = claims["jti"].(string)
+	family, _ = claims["fam"].(string)
+	email, _ = claims["email"].(string)
+	if jti == "" || family == "" || email == "" {
+		return "", "", "", errors.New("refresh token is missing claims")
+	}
+	return jti, family, email, nil
+}
+
+// parseAccessToken verifies the access token and returns the principal it describes
+func (h *Handler) parseAccessToken(tokenString string) (Principal, error) {
+	token, err := jwt.Parse(tokenString, h.keys.keyFunc)
+	if err != nil {
+		return Principal{}, err
+	}
+
+	claims, _ := token.Claims.(jwt.MapClaims)
+	if err := verifyClaims(claims, h.cfg.JWT); err != nil {
+		return Principal{}, err
+	}
+	return principalFromClaims(claims)
+}
+
+// setIssuerClaims sets the configured iss and aud claims
+func (h *Handler) setIssuerClaims(claims jwt.MapClaims) {
+	if h.cfg.JWT.Issuer != "" {
+		claims["iss"] = h.cfg.JWT.Issuer
+	}
+	if h.cfg.JWT.Audience != "" {
+		claims["aud"] = h.cfg.JWT.Audience
+	}
+}
+
+// isRevoked reports whether the access token of p or its family was revoked. Tokens of
+// external issuers without jti or fam claims can't be revoked.
+func (h *Handler) isRevoked(ctx context.Context, p Principal) (bool, error) {
+	if p.TokenID != "" {
+		revoked, err := h.tokens.IsTokenRevoked(ctx, p.TokenID)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+	if p.Family == "" {
+		return false, nil
+	}
+	return h.tokens.IsFamilyRevoked(ctx, p.Family)
+}
+
+// newTokenID returns a random identifier for the jti and fam claims
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
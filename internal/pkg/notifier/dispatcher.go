@@ -0,0 +1,208 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"github.com/whittier16/student-reg-svc/internal/app/config"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"time"
+)
+
+// Dispatcher defaults, used when the config leaves them at zero
+const (
+	defaultInterval    = 10 * time.Second
+	defaultBatchSize   = 50
+	defaultMaxAttempts = 5
+	defaultBackoff     = 30 * time.Second
+	// maxBackoff caps the delay between two attempts of a delivery
+	maxBackoff = 6 * time.Hour
+	// claimLease is how long a claimed delivery is kept from the other dispatchers while it is
+	// sent. The delivery is attempted again after it if its dispatcher stopped before recording
+	// the outcome.
+	claimLease = 10 * time.Minute
+)
+
+// Dispatcher drains the notification outbox through a Notifier. A failed delivery is retried
+// with an exponential backoff until it has been attempted MaxAttempts times.
+type Dispatcher struct {
+	outbox      Outbox
+	notifier    Notifier
+	log         logrus.FieldLogger
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// NewDispatcher returns a Dispatcher delivering the deliveries of outbox through n
+func NewDispatcher(outbox Outbox, n Notifier, cfg config.DispatcherConfig, log logrus.FieldLogger) *Dispatcher {
+	d := &Dispatcher{
+		outbox:      outbox,
+		notifier:    n,
+		log:         log,
+		interval:    time.Duration(cfg.Interval) * time.Second,
+		batchSize:   cfg.BatchSize,
+		maxAttempts: cfg.MaxAttempts,
+		backoff:     time.Duration(cfg.Backoff) * time.Second,
+	}
+	if d.interval <= 0 {
+		d.interval = defaultInterval
+	}
+	if d.batchSize <= 0 {
+		d.batchSize = defaultBatchSize
+	}
+	if d.maxAttempts <= 0 {
+		d.maxAttempts = defaultMaxAttempts
+	}
+	if d.backoff <= 0 {
+		d.backoff = defaultBackoff
+	}
+	return d
+}
+
+// Run drains the outbox every interval until ctx is done
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.Drain(ctx); err != nil && ctx.Err() == nil {
+			d.log.Errorf("[Dispatcher][Run] Problem to drain the outbox, err: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Drain attempts every delivery that is due, one batch at a time, and returns the number of
+// deliveries sent. Deliveries failing in this call are rescheduled and not attempted again.
+func (d *Dispatcher) Drain(ctx context.Context) (int, error) {
+	sent := 0
+	for ctx.Err() == nil {
+		now := time.Now()
+		deliveries, err := d.outbox.Due(ctx, now, d.batchSize)
+		if err != nil {
+			return sent, err
+		}
+		bundles, err := d.collect(ctx, deliveries, now)
+		if err != nil {
+			return sent, err
+		}
+
+		for _, bundle := range bundles {
+			ok, err := d.deliver(ctx, bundle)
+			if err != nil {
+				return sent, err
+			}
+			if ok {
+				sent += len(bundle)
+			}
+		}
+		if len(deliveries) < d.batchSize {
+			break
+		}
+	}
+	return sent, nil
+}
+
+// claim claims the due deliveries and returns the ones no other dispatcher claimed first
+func (d *Dispatcher) claim(ctx context.Context, deliveries []models.Delivery, now time.Time) ([]models.Delivery, error) {
+	claimed := make([]models.Delivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		err := d.outbox.Claim(ctx, delivery.ID, now, now.Add(claimLease))
+		if errors.As(err, &db.ErrObjectNotFound{}) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		claimed = append(claimed, delivery)
+	}
+	return claimed, nil
+}
+
+// deliver sends a bundle of deliveries to the same student as one message and records the
+// outcome of each. It reports whether the bundle was sent.
+func (d *Dispatcher) deliver(ctx context.Context, bundle []models.Delivery) (bool, error) {
+	m := NewMessage(bundle[0])
+	if len(bundle) > 1 {
+		m = NewDigest(bundle)
+	}
+	err := d.notifier.Send(ctx, m)
+	if err == nil {
+		for _, delivery := range bundle {
+			if err := d.outbox.MarkSent(ctx, delivery.ID); err != nil {
+				return true, err
+			}
+		}
+		return true, nil
+	}
+	if ctx.Err() != nil {
+		// the attempt was interrupted by the shutdown, keep it for the next run
+		return false, ctx.Err()
+	}
+
+	for _, delivery := range bundle {
+		var next *time.Time
+		attempts := delivery.Attempts + 1
+		if attempts < d.maxAttempts {
+			t := time.Now().Add(d.delay(attempts))
+			next = &t
+		}
+		d.log.Warnf("[Dispatcher][deliver] Problem to deliver notification %d to %s, attempt %d, err: %v",
+			delivery.NotificationID, delivery.StudentID, attempts, err)
+		if err := d.outbox.MarkFailed(ctx, delivery.ID, err.Error(), next); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// collect claims the due deliveries and groups them into the messages they are sent as, in the
+// order the students first appear. A student receiving digests gets every delivery due to them
+// in one digest, including the ones past the batch. Every other delivery is sent on its own.
+func (d *Dispatcher) collect(ctx context.Context, deliveries []models.Delivery, now time.Time) ([][]models.Delivery, error) {
+	bundles := [][]models.Delivery{}
+	digests := map[string]bool{}
+	for _, delivery := range deliveries {
+		bundle := []models.Delivery{delivery}
+		if delivery.Digest != "" && delivery.Digest != models.DigestImmediate {
+			if digests[delivery.StudentID] {
+				continue
+			}
+			digests[delivery.StudentID] = true
+
+			var err error
+			bundle, err = d.outbox.DueByStudent(ctx, delivery.StudentID, now)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		claimed, err := d.claim(ctx, bundle, now)
+		if err != nil {
+			return nil, err
+		}
+		if len(claimed) > 0 {
+			bundles = append(bundles, claimed)
+		}
+	}
+	return bundles, nil
+}
+
+// delay returns the delay before the next attempt of a delivery attempted the given times
+func (d *Dispatcher) delay(attempts int) time.Duration {
+	delay := d.backoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay
+}
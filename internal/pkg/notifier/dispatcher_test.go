@@ -0,0 +1,284 @@
+package notifier_test
+
+import (
+	"context"
+	"errors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"github.com/whittier16/student-reg-svc/internal/app/config"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/app/repository/memory"
+	"github.com/whittier16/student-reg-svc/internal/pkg/notifier"
+	"io"
+	"sync"
+	"time"
+)
+
+// fakeNotifier records the messages it sends and fails for the recipients in fail
+type fakeNotifier struct {
+	mu   sync.Mutex
+	sent []notifier.Message
+	fail map[string]bool
+}
+
+func (n *fakeNotifier) Send(ctx context.Context, m notifier.Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, to := range m.To {
+		if n.fail[to] {
+			return errors.New("mailbox unavailable")
+		}
+	}
+	n.sent = append(n.sent, m)
+	return nil
+}
+
+func (n *fakeNotifier) messages() []notifier.Message {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]notifier.Message{}, n.sent...)
+}
+
+// racingOutbox runs onDue once right after reading the due deliveries, before they are claimed
+type racingOutbox struct {
+	*memory.OutboxRepository
+	onDue func()
+}
+
+func (o *racingOutbox) Due(ctx context.Context, now time.Time, limit int) ([]models.Delivery, error) {
+	deliveries, err := o.OutboxRepository.Due(ctx, now, limit)
+	if f := o.onDue; f != nil {
+		o.onDue = nil
+		f()
+	}
+	return deliveries, err
+}
+
+var _ = Describe("Dispatcher", func() {
+	var (
+		ctx          context.Context
+		store        *memory.Store
+		outbox       *memory.OutboxRepository
+		n            *fakeNotifier
+		notification *models.Notification
+		log          *logrus.Logger
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		store = memory.New()
+		outbox = memory.NewOutboxRepository(store)
+		n = &fakeNotifier{fail: map[string]bool{}}
+		log = logrus.New()
+		log.SetOutput(io.Discard)
+
+		Expect(memory.NewTeacherRepository(store).Create(ctx, &models.Teacher{Email: "teacher1@gmail.com"})).Should(Succeed())
+		for _, email := range []string{"student1@gmail.com", "student2@gmail.com"} {
+			Expect(memory.NewStudentRepository(store).Create(ctx, &models.Student{Email: email})).Should(Succeed())
+		}
+		notification = &models.Notification{
+			TeacherID:  "teacher1@gmail.com",
+			Message:    "Hello students",
+			Recipients: []string{"student1@gmail.com", "student2@gmail.com"},
+		}
+		Expect(memory.NewNotificationRepository(store).Create(ctx, notification)).Should(Succeed())
+	})
+
+	deliveries := func() map[string]models.Delivery {
+		res, err := outbox.FindByNotification(ctx, notification.ID)
+		Expect(err).Should(BeNil())
+		byStudent := map[string]models.Delivery{}
+		for _, d := range res {
+			byStudent[d.StudentID] = d
+		}
+		return byStudent
+	}
+
+	It("Drain should send every pending delivery once", func() {
+		d := notifier.NewDispatcher(outbox, n, config.DispatcherConfig{BatchSize: 1}, log)
+		sent, err := d.Drain(ctx)
+		Expect(err).Should(BeNil())
+		Expect(sent).Should(Equal(2))
+		Expect(n.messages()).Should(ConsistOf(
+			notifier.Message{To: []string{"student1@gmail.com"}, Subject: "Notification from teacher1@gmail.com", Body: "Hello students"},
+			notifier.Message{To: []string{"student2@gmail.com"}, Subject: "Notification from teacher1@gmail.com", Body: "Hello students"},
+		))
+		for _, delivery := range deliveries() {
+			Expect(delivery.Status).Should(Equal(models.DeliverySent))
+			Expect(delivery.Attempts).Should(Equal(1))
+			Expect(delivery.SentOn).ShouldNot(BeNil())
+		}
+
+		sent, err = d.Drain(ctx)
+		Expect(err).Should(BeNil())
+		Expect(sent).Should(Equal(0))
+		Expect(n.messages()).Should(HaveLen(2))
+	})
+	It("Drain should not send the deliveries another dispatcher claimed", func() {
+		other := notifier.NewDispatcher(outbox, n, config.DispatcherConfig{}, log)
+		racing := &racingOutbox{OutboxRepository: outbox, onDue: func() {
+			sent, err := other.Drain(ctx)
+			Expect(err).Should(BeNil())
+			Expect(sent).Should(Equal(2))
+		}}
+
+		sent, err := notifier.NewDispatcher(racing, n, config.DispatcherConfig{}, log).Drain(ctx)
+		Expect(err).Should(BeNil())
+		Expect(sent).Should(BeZero())
+		Expect(n.messages()).Should(HaveLen(2))
+	})
+	It("Drain should keep the outcome of the deliveries when a unit of work running meanwhile fails", func() {
+		started, release := make(chan struct{}), make(chan struct{})
+		failed := make(chan error, 1)
+		go func() {
+			failed <- memory.NewUnitOfWork(store).Do(ctx, func(ctx context.Context) error {
+				close(started)
+				<-release
+				return errors.New("boom")
+			})
+		}()
+		<-started
+
+		drained := make(chan error, 1)
+		go func() {
+			_, err := notifier.NewDispatcher(outbox, n, config.DispatcherConfig{}, log).Drain(ctx)
+			drained <- err
+		}()
+		Consistently(drained, 100*time.Millisecond).ShouldNot(Receive())
+		close(release)
+		Expect(<-failed).Should(MatchError("boom"))
+		Eventually(drained).Should(Receive(BeNil()))
+
+		for _, d := range deliveries() {
+			Expect(d.Status).Should(Equal(models.DeliverySent))
+		}
+		Expect(n.messages()).Should(HaveLen(2))
+	})
+	It("a claimed delivery should be due again once its lease expires", func() {
+		now := time.Now()
+		claimed := deliveries()["student1@gmail.com"]
+		Expect(outbox.Claim(ctx, claimed.ID, now, now.Add(time.Minute))).Should(Succeed())
+		Expect(outbox.Claim(ctx, claimed.ID, now, now.Add(time.Minute))).ShouldNot(Succeed())
+
+		due, err := outbox.Due(ctx, now, 10)
+		Expect(err).Should(BeNil())
+		Expect(due).Should(HaveLen(1))
+		due, err = outbox.Due(ctx, now.Add(2*time.Minute), 10)
+		Expect(err).Should(BeNil())
+		Expect(due).Should(HaveLen(2))
+	})
+	It("Drain should bundle the deliveries of a student receiving digests", func() {
+		Expect(memory.NewPreferenceRepository(store).Save(ctx, &models.Preference{
+			StudentID: "student1@gmail.com",
+			Digest:    models.DigestDaily,
+			Timezone:  "UTC",
+		})).Should(Succeed())
+		Expect(memory.NewNotificationRepository(store).Create(ctx, &models.Notification{
+			TeacherID:  "teacher1@gmail.com",
+			Message:    "Exam on Monday",
+			Recipients: []string{"student1@gmail.com", "student2@gmail.com"},
+		})).Should(Succeed())
+
+		d := notifier.NewDispatcher(outbox, n, config.DispatcherConfig{}, log)
+		sent, err := d.Drain(ctx)
+		Expect(err).Should(BeNil())
+		Expect(sent).Should(Equal(4))
+		Expect(n.messages()).Should(ConsistOf(
+			notifier.Message{
+				To:      []string{"student1@gmail.com"},
+				Subject: "2 notifications",
+				Body:    "From teacher1@gmail.com:\nHello students\n\nFrom teacher1@gmail.com:\nExam on Monday",
+			},
+			notifier.Message{To: []string{"student2@gmail.com"}, Subject: "Notification from teacher1@gmail.com", Body: "Hello students"},
+			notifier.Message{To: []string{"student2@gmail.com"}, Subject: "Notification from teacher1@gmail.com", Body: "Exam on Monday"},
+		))
+	})
+	It("Drain should send a single digest to a student with more deliveries due than the batch size", func() {
+		Expect(memory.NewPreferenceRepository(store).Save(ctx, &models.Preference{
+			StudentID: "student1@gmail.com",
+			Digest:    models.DigestWeekly,
+			Timezone:  "UTC",
+		})).Should(Succeed())
+		for _, message := range []string{"Exam on Monday", "Trip on Friday"} {
+			Expect(memory.NewNotificationRepository(store).Create(ctx, &models.Notification{
+				TeacherID:  "teacher1@gmail.com",
+				Message:    message,
+				Recipients: []string{"student1@gmail.com", "student2@gmail.com"},
+			})).Should(Succeed())
+		}
+
+		d := notifier.NewDispatcher(outbox, n, config.DispatcherConfig{BatchSize: 2}, log)
+		sent, err := d.Drain(ctx)
+		Expect(err).Should(BeNil())
+		Expect(sent).Should(Equal(6))
+
+		digests := 0
+		for _, m := range n.messages() {
+			if m.To[0] == "student1@gmail.com" {
+				digests++
+				Expect(m.Subject).Should(Equal("3 notifications"))
+			}
+		}
+		Expect(digests).Should(Equal(1))
+		Expect(n.messages()).Should(HaveLen(4))
+	})
+	It("Drain should retry a failed delivery with an exponential backoff", func() {
+		n.fail["student2@gmail.com"] = true
+		d := notifier.NewDispatcher(outbox, n, config.DispatcherConfig{Backoff: 60}, log)
+
+		start := time.Now()
+		sent, err := d.Drain(ctx)
+		Expect(err).Should(BeNil())
+		Expect(sent).Should(Equal(1))
+
+		failed := deliveries()["student2@gmail.com"]
+		Expect(failed.Status).Should(Equal(models.DeliveryPending))
+		Expect(failed.Attempts).Should(Equal(1))
+		Expect(*failed.LastError).Should(Equal("mailbox unavailable"))
+		Expect(failed.NextAttemptOn).Should(BeTemporally("~", start.Add(time.Minute), time.Second))
+
+		// the retry is not due yet
+		sent, err = d.Drain(ctx)
+		Expect(err).Should(BeNil())
+		Expect(sent).Should(Equal(0))
+		Expect(deliveries()["student2@gmail.com"].Attempts).Should(Equal(1))
+
+		// the second retry waits twice as long
+		Expect(outbox.MarkFailed(ctx, failed.ID, "mailbox unavailable", &start)).Should(Succeed())
+		_, err = d.Drain(ctx)
+		Expect(err).Should(BeNil())
+		failed = deliveries()["student2@gmail.com"]
+		Expect(failed.Attempts).Should(Equal(3))
+		Expect(failed.NextAttemptOn).Should(BeTemporally("~", start.Add(4*time.Minute), time.Second))
+	})
+	It("Drain should give up a delivery after the maximum attempts", func() {
+		n.fail["student2@gmail.com"] = true
+		d := notifier.NewDispatcher(outbox, n, config.DispatcherConfig{MaxAttempts: 2}, log)
+
+		_, err := d.Drain(ctx)
+		Expect(err).Should(BeNil())
+		now := time.Now()
+		Expect(outbox.MarkFailed(ctx, deliveries()["student2@gmail.com"].ID, "mailbox unavailable", &now)).Should(Succeed())
+		_, err = d.Drain(ctx)
+		Expect(err).Should(BeNil())
+
+		failed := deliveries()["student2@gmail.com"]
+		Expect(failed.Status).Should(Equal(models.DeliveryFailed))
+		Expect(failed.Attempts).Should(Equal(3))
+		Expect(deliveries()["student1@gmail.com"].Status).Should(Equal(models.DeliverySent))
+	})
+	It("Run should drain the outbox until it is stopped", func() {
+		d := notifier.NewDispatcher(outbox, n, config.DispatcherConfig{}, log)
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			d.Run(runCtx)
+		}()
+
+		Eventually(n.messages).Should(HaveLen(2))
+		cancel()
+		Eventually(done).Should(BeClosed())
+	})
+})
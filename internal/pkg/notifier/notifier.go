@@ -0,0 +1,58 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"strings"
+	"time"
+)
+
+// Message is an email delivered to its recipients
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Notifier delivers messages to their recipients
+type Notifier interface {
+	Send(ctx context.Context, m Message) error
+}
+
+// Outbox holds the deliveries of the notifications waiting to be sent
+type Outbox interface {
+	Due(ctx context.Context, now time.Time, limit int) ([]models.Delivery, error)
+	// DueByStudent returns every delivery to the student due at now, however many there are
+	DueByStudent(ctx context.Context, studentEmail string, now time.Time) ([]models.Delivery, error)
+	// Claim keeps the delivery due at now from the other dispatchers until the given time. It
+	// returns db.ErrObjectNotFound if the delivery is no longer due.
+	Claim(ctx context.Context, id int, now, until time.Time) error
+	MarkSent(ctx context.Context, id int) error
+	MarkFailed(ctx context.Context, id int, reason string, next *time.Time) error
+}
+
+// NewMessage returns the message delivering the notification of d to its student
+func NewMessage(d models.Delivery) Message {
+	return Message{
+		To:      []string{d.StudentID},
+		Subject: "Notification from " + d.TeacherID,
+		Body:    d.Message,
+	}
+}
+
+// NewDigest returns the message bundling the notifications of deliveries, which all go to the same student
+func NewDigest(deliveries []models.Delivery) Message {
+	var body strings.Builder
+	for i, d := range deliveries {
+		if i > 0 {
+			body.WriteString("\n\n")
+		}
+		fmt.Fprintf(&body, "From %s:\n%s", d.TeacherID, d.Message)
+	}
+	return Message{
+		To:      []string{deliveries[0].StudentID},
+		Subject: fmt.Sprintf("%d notifications", len(deliveries)),
+		Body:    body.String(),
+	}
+}
@@ -0,0 +1,76 @@
+package notifier
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"github.com/whittier16/student-reg-svc/internal/app/config"
+	"time"
+)
+
+// Scheduler defaults, used when the config leaves them at zero
+const (
+	defaultScheduleInterval  = 15 * time.Second
+	defaultScheduleBatchSize = 50
+)
+
+// Sender sends the scheduled notifications whose send date passed at now, see
+// services.Service.SendDueNotifications
+type Sender interface {
+	SendDueNotifications(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+// Scheduler sends the scheduled notifications once they are due
+type Scheduler struct {
+	sender    Sender
+	log       logrus.FieldLogger
+	interval  time.Duration
+	batchSize int
+}
+
+// NewScheduler returns a Scheduler sending the due notifications through sender
+func NewScheduler(sender Sender, cfg config.SchedulerConfig, log logrus.FieldLogger) *Scheduler {
+	s := &Scheduler{
+		sender:    sender,
+		log:       log,
+		interval:  time.Duration(cfg.Interval) * time.Second,
+		batchSize: cfg.BatchSize,
+	}
+	if s.interval <= 0 {
+		s.interval = defaultScheduleInterval
+	}
+	if s.batchSize <= 0 {
+		s.batchSize = defaultScheduleBatchSize
+	}
+	return s
+}
+
+// Run sends the due notifications every interval until ctx is done
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.SendDue(ctx); err != nil && ctx.Err() == nil {
+			s.log.Errorf("[Scheduler][Run] Problem to send the scheduled notifications, err: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SendDue sends the notifications that are due, one batch at a time
+func (s *Scheduler) SendDue(ctx context.Context) error {
+	for ctx.Err() == nil {
+		sent, err := s.sender.SendDueNotifications(ctx, time.Now(), s.batchSize)
+		if err != nil {
+			return err
+		}
+		if sent < s.batchSize {
+			return nil
+		}
+	}
+	return nil
+}
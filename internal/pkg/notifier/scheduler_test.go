@@ -0,0 +1,67 @@
+package notifier_test
+
+import (
+	"context"
+	"errors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"github.com/whittier16/student-reg-svc/internal/app/config"
+	"github.com/whittier16/student-reg-svc/internal/pkg/notifier"
+	"io"
+	"time"
+)
+
+// fakeSender pretends pending notifications are due and records the batches it was asked for
+type fakeSender struct {
+	pending int
+	calls   []int
+	err     error
+}
+
+func (s *fakeSender) SendDueNotifications(ctx context.Context, now time.Time, limit int) (int, error) {
+	s.calls = append(s.calls, limit)
+	if s.err != nil {
+		return 0, s.err
+	}
+	sent := limit
+	if s.pending < sent {
+		sent = s.pending
+	}
+	s.pending -= sent
+	return sent, nil
+}
+
+var _ = Describe("Scheduler", func() {
+	var log *logrus.Logger
+
+	BeforeEach(func() {
+		log = logrus.New()
+		log.SetOutput(io.Discard)
+	})
+
+	It("should send batches until a batch is not full", func() {
+		sender := &fakeSender{pending: 5}
+		s := notifier.NewScheduler(sender, config.SchedulerConfig{BatchSize: 2}, log)
+
+		Expect(s.SendDue(context.Background())).Should(Succeed())
+		Expect(sender.pending).Should(BeZero())
+		Expect(sender.calls).Should(Equal([]int{2, 2, 2}))
+	})
+	It("should stop at the first error", func() {
+		sender := &fakeSender{pending: 5, err: errors.New("database is down")}
+		s := notifier.NewScheduler(sender, config.SchedulerConfig{}, log)
+
+		Expect(s.SendDue(context.Background())).Should(MatchError("database is down"))
+		Expect(sender.calls).Should(Equal([]int{50}))
+	})
+	It("should stop when the context is done", func() {
+		sender := &fakeSender{pending: 5}
+		s := notifier.NewScheduler(sender, config.SchedulerConfig{BatchSize: 2}, log)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		Expect(s.SendDue(ctx)).Should(Succeed())
+		Expect(sender.calls).Should(BeEmpty())
+	})
+})
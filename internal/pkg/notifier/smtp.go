@@ -0,0 +1,104 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"github.com/whittier16/student-reg-svc/internal/app/config"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTP delivers messages through a mail server
+type SMTP struct {
+	cfg config.SMTPConfig
+}
+
+// NewSMTP returns a Notifier sending messages through the mail server of cfg
+func NewSMTP(cfg config.SMTPConfig) *SMTP {
+	if cfg.Port == 0 {
+		cfg.Port = 25
+	}
+	return &SMTP{cfg: cfg}
+}
+
+// Send delivers the message over a new connection to the mail server. The connection is upgraded
+// with STARTTLS when the server supports it, and authenticated when a username is configured.
+func (s *SMTP) Send(ctx context.Context, m Message) error {
+	if len(m.To) == 0 {
+		return errors.New("message has no recipients")
+	}
+	for _, addr := range append([]string{s.cfg.From}, m.To...) {
+		if strings.ContainsAny(addr, "\r\n") {
+			return fmt.Errorf("invalid address %q", addr)
+		}
+	}
+
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	// bound the whole conversation by the context
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	c, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err = c.StartTLS(&tls.Config{ServerName: s.cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if s.cfg.Username != "" {
+		if err = c.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err = c.Mail(s.cfg.From); err != nil {
+		return err
+	}
+	for _, to := range m.To {
+		if err = c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(s.format(m)); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// format returns the message as an RFC 5322 document with CRLF line endings
+func (s *SMTP) format(m Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(m.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", strings.NewReplacer("\r", "", "\n", " ").Replace(m.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	body := strings.ReplaceAll(strings.ReplaceAll(m.Body, "\r\n", "\n"), "\n", "\r\n")
+	b.WriteString(body)
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
@@ -0,0 +1,149 @@
+package notifier_test
+
+import (
+	"bufio"
+	"context"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/whittier16/student-reg-svc/internal/app/config"
+	"github.com/whittier16/student-reg-svc/internal/pkg/notifier"
+	"net"
+	"strings"
+	"time"
+)
+
+// mail is a message received by fakeSMTP
+type mail struct {
+	From string
+	To   []string
+	Data string
+}
+
+// fakeSMTP is a local SMTP server accepting every message, or rejecting the recipients in reject
+type fakeSMTP struct {
+	ln     net.Listener
+	mails  chan mail
+	reject map[string]bool
+}
+
+func newFakeSMTP() *fakeSMTP {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).Should(BeNil())
+	s := &fakeSMTP{ln: ln, mails: make(chan mail, 10), reject: map[string]bool{}}
+	go s.serve()
+	return s
+}
+
+func (s *fakeSMTP) port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTP) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTP) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost fake SMTP")
+	var m mail
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			m = mail{From: strings.Trim(line[len("MAIL FROM:"):], "<>")}
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			to := strings.Trim(line[len("RCPT TO:"):], "<>")
+			if s.reject[to] {
+				reply("550 no such user")
+				continue
+			}
+			m.To = append(m.To, to)
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 end with <CRLF>.<CRLF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			m.Data = data.String()
+			s.mails <- m
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+var _ = Describe("SMTP", func() {
+	var (
+		server *fakeSMTP
+		smtp   *notifier.SMTP
+	)
+
+	BeforeEach(func() {
+		server = newFakeSMTP()
+		DeferCleanup(func() { _ = server.ln.Close() })
+		smtp = notifier.NewSMTP(config.SMTPConfig{Host: "127.0.0.1", Port: server.port(), From: "noreply@school.com"})
+	})
+
+	It("Send should deliver the message to the mail server", func() {
+		err := smtp.Send(context.Background(), notifier.Message{
+			To:      []string{"student1@gmail.com"},
+			Subject: "Notification from teacher1@gmail.com",
+			Body:    "Hello\nstudents",
+		})
+		Expect(err).Should(BeNil())
+
+		var m mail
+		Eventually(server.mails).Should(Receive(&m))
+		Expect(m.From).Should(Equal("noreply@school.com"))
+		Expect(m.To).Should(Equal([]string{"student1@gmail.com"}))
+		Expect(m.Data).Should(ContainSubstring("Subject: Notification from teacher1@gmail.com\r\n"))
+		Expect(m.Data).Should(ContainSubstring("\r\n\r\nHello\r\nstudents\r\n"))
+	})
+	It("Send should fail when the mail server rejects a recipient", func() {
+		server.reject["nobody@gmail.com"] = true
+		err := smtp.Send(context.Background(), notifier.Message{To: []string{"nobody@gmail.com"}, Body: "Hello"})
+		Expect(err).ShouldNot(BeNil())
+		Expect(err.Error()).Should(ContainSubstring("550"))
+	})
+	It("Send should fail when the mail server is down", func() {
+		Expect(server.ln.Close()).Should(Succeed())
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		err := smtp.Send(ctx, notifier.Message{To: []string{"student1@gmail.com"}, Body: "Hello"})
+		Expect(err).ShouldNot(BeNil())
+	})
+	It("Send should reject header injection in addresses", func() {
+		err := smtp.Send(context.Background(), notifier.Message{To: []string{"student1@gmail.com\r\nBcc: x@gmail.com"}})
+		Expect(err).ShouldNot(BeNil())
+		Expect(err.Error()).Should(ContainSubstring("invalid address"))
+	})
+})
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/opentracing/opentracing-go"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"strings"
+)
+
+// CreateClass adds a class to the teacher in a term, which must exist, or without a term. The
+// default class of a term, which holds the students registered to the teacher directly, is
+// created on the first registration and its code is reserved.
+func (s *Service) CreateClass(ctx context.Context, params CreateClassParams) (models.Class, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.CreateClass")
+	defer span.Finish()
+
+	if err := validate(params); err != nil {
+		return models.Class{}, err
+	}
+	if strings.EqualFold(params.Code, models.DefaultClassCode) {
+		return models.Class{}, Validation("validation_failed", errors.New("code: is reserved"),
+			FieldError{Field: "code", Reason: "is reserved"})
+	}
+
+	c := models.Class{TeacherID: params.Teacher, Code: params.Code, Title: params.Title, Term: params.Term}
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		_, err := s.tr.FindByEmail(ctx, params.Teacher)
+		if err != nil {
+			return translate(err, "teacher")
+		}
+		if params.Term != "" {
+			if _, err = s.tt.FindByCode(ctx, params.Term); err != nil {
+				return translate(err, "term")
+			}
+		}
+		return s.cl.Create(ctx, &c)
+	})
+	if err != nil {
+		return models.Class{}, translate(err, "class")
+	}
+	return c, nil
+}
+
+// ListClasses retrieves the classes of the teacher ordered by term and code
+func (s *Service) ListClasses(ctx context.Context, teacher string) ([]models.Class, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.ListClasses")
+	defer span.Finish()
+
+	_, err := s.tr.FindByEmail(ctx, teacher)
+	if err != nil {
+		return nil, translate(err, "teacher")
+	}
+
+	classes, err := s.cl.FindByTeacher(ctx, teacher)
+	if err != nil {
+		return nil, translate(err, "class")
+	}
+	return classes, nil
+}
+
+// GetClass retrieves a class of the teacher with the students enrolled to it
+func (s *Service) GetClass(ctx context.Context, teacher string, id int) (models.Class, []string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.GetClass")
+	defer span.Finish()
+
+	c, err := s.cl.FindByID(ctx, teacher, id)
+	if err != nil {
+		return models.Class{}, nil, translate(err, "class")
+	}
+
+	students, err := s.rr.FindByClasses(ctx, []int{c.ID})
+	if err != nil {
+		return models.Class{}, nil, translate(err, "registration")
+	}
+	return c, students, nil
+}
+
+// EnrollStudents enrolls the students to a class of the teacher. Either every student is
+// enrolled or none; students that are already enrolled are left as they are.
+func (s *Service) EnrollStudents(ctx context.Context, teacher string, id int, params EnrollStudentsParams) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.EnrollStudents")
+	defer span.Finish()
+
+	if err := validate(params); err != nil {
+		return err
+	}
+
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		_, err := s.cl.FindByID(ctx, teacher, id)
+		if err != nil {
+			return translate(err, "class")
+		}
+
+		students := unique(params.Students)
+		existing, err := s.sr.FindByEmailArr(ctx, students, true)
+		if err != nil {
+			return err
+		}
+		if missing := difference(students, existing); len(missing) > 0 {
+			return NotFound("student_not_found", "student not found: "+strings.Join(missing, ", "))
+		}
+		active, err := s.sr.FindNotSuspended(ctx, students, teacher)
+		if err != nil {
+			return err
+		}
+		if suspended := difference(students, active); len(suspended) > 0 {
+			return Suspended("student_suspended", "student is suspended: "+strings.Join(suspended, ", "))
+		}
+
+		return s.rr.Enroll(ctx, id, students)
+	})
+	return translate(err, "registration")
+}
+
+// UnenrollStudents removes the students from a class of the teacher. Students that are not
+// enrolled are skipped, so it returns the students that were actually unenrolled.
+func (s *Service) UnenrollStudents(ctx context.Context, teacher string, id int, params EnrollStudentsParams) ([]string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.UnenrollStudents")
+	defer span.Finish()
+
+	if err := validate(params); err != nil {
+		return []string{}, err
+	}
+
+	unenrolled := []string{}
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		_, err := s.cl.FindByID(ctx, teacher, id)
+		if err != nil {
+			return translate(err, "class")
+		}
+
+		res, err := s.rr.Unenroll(ctx, id, unique(params.Students))
+		if err != nil {
+			return err
+		}
+		unenrolled = append(unenrolled, res...)
+		return nil
+	})
+	if err != nil {
+		return []string{}, translate(err, "registration")
+	}
+	return unenrolled, nil
+}
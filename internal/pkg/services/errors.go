@@ -0,0 +1,110 @@
+package services
+
+import (
+	"errors"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+)
+
+// Kind classifies a service error so callers can react to it without parsing messages
+type Kind string
+
+const (
+	KindNotFound      Kind = "not_found"
+	KindAlreadyExists Kind = "already_exists"
+	KindValidation    Kind = "validation"
+	KindConflict      Kind = "conflict"
+	KindUnauthorized  Kind = "unauthorized"
+	KindSuspended     Kind = "suspended"
+	KindInternal      Kind = "internal"
+)
+
+// Error is returned by every Service method. Code is a stable machine-readable
+// identifier such as "student_not_found" and Message a human-readable description.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	// Fields lists the offending request fields of a validation error
+	Fields []FieldError
+	Err    error
+}
+
+// FieldError describes why the value of a single request field was rejected
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil && e.Message == "" {
+		return e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NotFound returns an error for a missing object
+func NotFound(code, message string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+// AlreadyExists returns an error for an object that cannot be created twice
+func AlreadyExists(code, message string) *Error {
+	return &Error{Kind: KindAlreadyExists, Code: code, Message: message}
+}
+
+// Validation returns an error for invalid input
+func Validation(code string, err error, fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: err.Error(), Fields: fields, Err: err}
+}
+
+// Conflict returns an error for a request that conflicts with the current state
+func Conflict(code, message string) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message}
+}
+
+// Suspended returns an error for an operation that is not allowed on a suspended student
+func Suspended(code, message string) *Error {
+	return &Error{Kind: KindSuspended, Code: code, Message: message}
+}
+
+// Unauthorized returns an error for a caller that could not be authenticated
+func Unauthorized(code, message string) *Error {
+	return &Error{Kind: KindUnauthorized, Code: code, Message: message}
+}
+
+// Internal wraps an unexpected error, such as a database outage
+func Internal(err error) *Error {
+	return &Error{Kind: KindInternal, Code: "internal_error", Message: "internal server error", Err: err}
+}
+
+// KindOf returns the kind of err, or KindInternal for errors not raised by the service
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return KindInternal
+}
+
+// translate converts a repository error into a service Error; entity prefixes the error code
+func translate(err error, entity string) error {
+	var e *Error
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &e):
+		return err
+	case errors.As(err, &db.ErrObjectNotFound{}):
+		return NotFound(entity+"_not_found", entity+" not found")
+	case errors.As(err, &db.ErrDuplicateObject{}):
+		return AlreadyExists(entity+"_already_exists", entity+" already exists")
+	case errors.As(err, &db.ErrReferenceNotFound{}):
+		return NotFound("reference_not_found", "referenced teacher or student not found")
+	default:
+		return Internal(err)
+	}
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/opentracing/opentracing-go"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultPageSize is the number of records of a page when the request leaves the limit out
+const defaultPageSize = 50
+
+// StudentPage is a page of a student listing. NextCursor is empty on the last page.
+type StudentPage struct {
+	Students   []models.Student
+	NextCursor string
+	Total      int
+}
+
+// TeacherPage is a page of a teacher listing. NextCursor is empty on the last page.
+type TeacherPage struct {
+	Teachers   []models.Teacher
+	NextCursor string
+	Total      int
+}
+
+// ListStudents retrieves a page of the students matching the filters and the number of all of them
+func (s *Service) ListStudents(ctx context.Context, params ListStudentsParams) (StudentPage, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.ListStudents")
+	defer span.Finish()
+
+	if err := validate(params); err != nil {
+		return StudentPage{}, err
+	}
+	f, err := listFilter(params.ListParams)
+	if err != nil {
+		return StudentPage{}, err
+	}
+	f.Teacher = params.Teacher
+	if params.Suspended != "" {
+		suspended := params.Suspended == "true"
+		f.Suspended = &suspended
+	}
+
+	total, err := s.sr.Count(ctx, f)
+	if err != nil {
+		return StudentPage{}, translate(err, "student")
+	}
+
+	// fetch one more record to know whether there is a next page
+	limit := f.Limit
+	f.Limit++
+	students, err := s.sr.List(ctx, f)
+	if err != nil {
+		return StudentPage{}, translate(err, "student")
+	}
+
+	p := StudentPage{Students: students, Total: total}
+	if len(students) > limit {
+		p.Students = students[:limit]
+		last := p.Students[limit-1]
+		p.NextCursor = encodeCursor(models.Cursor{Email: last.Email, CreatedOn: last.CreatedOn})
+	}
+	return p, nil
+}
+
+// ListTeachers retrieves a page of the teachers matching the filters and the number of all of them
+func (s *Service) ListTeachers(ctx context.Context, params ListParams) (TeacherPage, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.ListTeachers")
+	defer span.Finish()
+
+	if err := validate(params); err != nil {
+		return TeacherPage{}, err
+	}
+	f, err := listFilter(params)
+	if err != nil {
+		return TeacherPage{}, err
+	}
+
+	total, err := s.tr.Count(ctx, f)
+	if err != nil {
+		return TeacherPage{}, translate(err, "teacher")
+	}
+
+	// fetch one more record to know whether there is a next page
+	limit := f.Limit
+	f.Limit++
+	teachers, err := s.tr.List(ctx, f)
+	if err != nil {
+		return TeacherPage{}, translate(err, "teacher")
+	}
+
+	p := TeacherPage{Teachers: teachers, Total: total}
+	if len(teachers) > limit {
+		p.Teachers = teachers[:limit]
+		last := p.Teachers[limit-1]
+		p.NextCursor = encodeCursor(models.Cursor{Email: last.Email, CreatedOn: last.CreatedOn})
+	}
+	return p, nil
+}
+
+// listFilter converts validated params to a filter
+func listFilter(params ListParams) (models.ListFilter, error) {
+	f := models.ListFilter{
+		Query:  params.Query,
+		SortBy: strings.TrimPrefix(params.Sort, "-"),
+		Desc:   strings.HasPrefix(params.Sort, "-"),
+		Limit:  defaultPageSize,
+	}
+	if f.SortBy == "" {
+		f.SortBy = models.SortEmail
+	}
+	if params.Limit != "" {
+		f.Limit, _ = strconv.Atoi(params.Limit)
+	}
+	if params.CreatedAfter != "" {
+		t, _ := time.Parse(time.RFC3339, params.CreatedAfter)
+		f.CreatedAfter = &t
+	}
+	if params.Cursor != "" {
+		c, err := decodeCursor(params.Cursor)
+		if err != nil {
+			return f, Validation("invalid_cursor", err, FieldError{Field: "cursor", Reason: "is not a valid cursor"})
+		}
+		f.After = &c
+	}
+	return f, nil
+}
+
+// encodeCursor returns the opaque cursor pointing after c
+func encodeCursor(c models.Cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeCursor parses a cursor returned by encodeCursor
+func decodeCursor(s string) (models.Cursor, error) {
+	var c models.Cursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(b, &c)
+	}
+	if err == nil && c.Email == "" {
+		err = errors.New("cursor is missing the email")
+	}
+	if err != nil {
+		return c, errors.New("cursor is not valid")
+	}
+	return c, nil
+}
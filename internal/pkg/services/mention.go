@@ -0,0 +1,63 @@
+package services
+
+import (
+	"github.com/asaskevich/govalidator"
+	"strings"
+)
+
+// ParseMentions returns the email addresses mentioned in text, lowercased and deduplicated in
+// the order they first appear. A mention is an "@" directly followed by an email address. The
+// "@" must start the text or follow a character that cannot be part of an address, so a bare
+// "student@gmail.com" is not a mention. Punctuation trailing an address, such as the "!" in
+// "@student@gmail.com!", is not part of it.
+func ParseMentions(text string) []string {
+	mentions := []string{}
+	seen := map[string]bool{}
+	for i := 0; i < len(text); i++ {
+		if text[i] != '@' || (i > 0 && (isLocalChar(text[i-1]) || text[i-1] == '@')) {
+			continue
+		}
+
+		// the local part runs up to the "@" of the address
+		start := i + 1
+		at := start
+		for at < len(text) && isLocalChar(text[at]) {
+			at++
+		}
+		if at == start || at == len(text) || text[at] != '@' {
+			continue
+		}
+
+		// the domain ends at the first character that cannot be part of a host name
+		end := at + 1
+		for end < len(text) && isDomainChar(text[end]) {
+			end++
+		}
+		i = end - 1
+		for end > at+1 && (text[end-1] == '.' || text[end-1] == '-') {
+			end--
+		}
+
+		email := strings.ToLower(text[start:end])
+		if seen[email] || !govalidator.IsEmail(email) {
+			continue
+		}
+		seen[email] = true
+		mentions = append(mentions, email)
+	}
+	return mentions
+}
+
+// isLocalChar reports whether c may appear in the unquoted local part of an address (RFC 5322 atext and ".")
+func isLocalChar(c byte) bool {
+	return isAlphanumeric(c) || strings.IndexByte("!#$%&'*+-/=?^_`{|}~.", c) > -1
+}
+
+// isDomainChar reports whether c may appear in a host name
+func isDomainChar(c byte) bool {
+	return isAlphanumeric(c) || c == '-' || c == '.'
+}
+
+func isAlphanumeric(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
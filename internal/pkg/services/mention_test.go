@@ -0,0 +1,24 @@
+package services_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/whittier16/student-reg-svc/internal/pkg/services"
+)
+
+var _ = Describe("ParseMentions", func() {
+	DescribeTable("should extract the mentioned addresses",
+		func(text string, mentions []string) {
+			Expect(services.ParseMentions(text)).Should(Equal(mentions))
+		},
+		Entry("without mentions", "Hello students!", []string{}),
+		Entry("between words", "Hello @a@x.com and @b@y.com!", []string{"a@x.com", "b@y.com"}),
+		Entry("at the start and after newlines", "@a@x.com\n@b@y.com", []string{"a@x.com", "b@y.com"}),
+		Entry("after punctuation", "Hi,@a@x.com (@b@y.com)", []string{"a@x.com", "b@y.com"}),
+		Entry("with trailing punctuation", "See @a@x.com. Or @b@y.com-, @c@z.com's", []string{"a@x.com", "b@y.com", "c@z.com"}),
+		Entry("in any case, once", "@A@X.com @a@x.COM", []string{"a@x.com"}),
+		Entry("with tags and dots in the local part", "@first.last+tag@x.co.uk", []string{"first.last+tag@x.co.uk"}),
+		Entry("not bare addresses", "Mail a@x.com or foo@b@y.com", []string{}),
+		Entry("not invalid addresses", "@a@ @@b@y.com @c@-", []string{}),
+	)
+})
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/opentracing/opentracing-go"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"strconv"
+)
+
+// NotificationPage is a page of a notification history, latest first. NextCursor is empty on the last page.
+type NotificationPage struct {
+	Notifications []models.Notification
+	NextCursor    string
+}
+
+// notificationCursor points after a notification of a history
+type notificationCursor struct {
+	ID int `json:"i"`
+}
+
+// ListNotifications retrieves a page of the notifications sent by the teacher or to the student
+func (s *Service) ListNotifications(ctx context.Context, params ListNotificationsParams) (NotificationPage, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.ListNotifications")
+	defer span.Finish()
+
+	if err := validate(params); err != nil {
+		return NotificationPage{}, err
+	}
+	if params.Teacher == "" && params.Student == "" {
+		return NotificationPage{}, Validation("validation_failed", errors.New("teacher: is required"),
+			FieldError{Field: "teacher", Reason: reasons["required"]})
+	}
+
+	f := models.NotificationFilter{Teacher: params.Teacher, Student: params.Student, Limit: defaultPageSize}
+	if params.Limit != "" {
+		f.Limit, _ = strconv.Atoi(params.Limit)
+	}
+	if params.Cursor != "" {
+		c, err := decodeNotificationCursor(params.Cursor)
+		if err != nil {
+			return NotificationPage{}, Validation("invalid_cursor", err, FieldError{Field: "cursor", Reason: "is not a valid cursor"})
+		}
+		f.Before = c.ID
+	}
+
+	if params.Teacher != "" {
+		if _, err := s.tr.FindByEmail(ctx, params.Teacher); err != nil {
+			return NotificationPage{}, translate(err, "teacher")
+		}
+	}
+	if params.Student != "" {
+		if _, err := s.sr.FindByEmail(ctx, params.Student); err != nil {
+			return NotificationPage{}, translate(err, "student")
+		}
+	}
+
+	// fetch one more record to know whether there is a next page
+	limit := f.Limit
+	f.Limit++
+	notifications, err := s.nr.List(ctx, f)
+	if err != nil {
+		return NotificationPage{}, translate(err, "notification")
+	}
+
+	p := NotificationPage{Notifications: notifications}
+	if len(notifications) > limit {
+		p.Notifications = notifications[:limit]
+		p.NextCursor = encodeNotificationCursor(notificationCursor{ID: p.Notifications[limit-1].ID})
+	}
+	return p, nil
+}
+
+// encodeNotificationCursor returns the opaque cursor pointing after c
+func encodeNotificationCursor(c notificationCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeNotificationCursor parses a cursor returned by encodeNotificationCursor
+func decodeNotificationCursor(s string) (notificationCursor, error) {
+	var c notificationCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(b, &c)
+	}
+	if err == nil && c.ID <= 0 {
+		err = errors.New("cursor is missing the id")
+	}
+	if err != nil {
+		return c, errors.New("cursor is not valid")
+	}
+	return c, nil
+}
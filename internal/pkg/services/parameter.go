@@ -2,12 +2,28 @@ package services
 
 type SendNotificationsParams struct {
 	Teacher       string `json:"teacher" valid:"email,required"`
-	Notifications string `json:"notifications" valid:"required"`
+	Notifications string `json:"notifications" valid:"optional"`
+	// TemplateID sends a template of the teacher instead of Notifications, rendered with Variables
+	TemplateID int               `json:"template_id" valid:"optional"`
+	Variables  map[string]string `json:"variables" valid:"-"`
+	// SendAt is the date ScheduleNotification queues the notification for
+	SendAt string `json:"send_at" valid:"rfc3339,optional"`
+	// Class only sends the notification to the students of a class of the teacher instead of all of them
+	Class int `json:"class" valid:"optional"`
 }
 
 type RegisterStudentsParams struct {
 	TeacherEmail  string   `json:"teacher" valid:"email,required"`
 	StudentEmails []string `json:"students" valid:"email,required"`
+	Mode          string   `json:"mode" valid:"in(strict|upsert|partial),optional"`
+	// CreateMissing creates the students that do not exist, named after Names when given
+	CreateMissing bool              `json:"create_missing" valid:"optional"`
+	Names         map[string]string `json:"names" valid:"-"`
+}
+
+type UnregisterStudentsParams struct {
+	TeacherEmail  string   `json:"teacher" valid:"email,required"`
+	StudentEmails []string `json:"students" valid:"email,required"`
 }
 
 type CreateStudentParams struct {
@@ -15,15 +31,124 @@ type CreateStudentParams struct {
 	Name  string `json:"name"  valid:"optional"`
 }
 
+type UpdateStudentParams struct {
+	Email string  `json:"email" valid:"email,optional"`
+	Name  *string `json:"name"  valid:"optional"`
+}
+
 type CreateTeacherParams struct {
 	Email string `json:"email" valid:"email,required"`
 	Name  string `json:"name"  valid:"optional"`
 }
 
+type UpdateTeacherParams struct {
+	Email string  `json:"email" valid:"email,optional"`
+	Name  *string `json:"name"  valid:"optional"`
+}
+
 type SuspendStudentsParams struct {
 	Student string `json:"student" valid:"email,required"`
+	// Teacher scopes the suspension to the teacher's class; without it the suspension is global
+	Teacher string `json:"teacher" valid:"email,optional"`
+	Reason  string `json:"reason"  valid:"stringlength(1|255),required"`
+	Until   string `json:"until"   valid:"rfc3339,optional"`
+	// By is the email of the user suspending the student
+	By string `json:"-" valid:"-"`
+}
+
+type UnsuspendStudentParams struct {
+	Student string `json:"student" valid:"email,required"`
+	// Teacher only lifts the suspensions scoped to the teacher; without it every suspension is lifted
+	Teacher string `json:"teacher" valid:"email,optional"`
+	// By is the email of the user lifting the suspension
+	By string `json:"-" valid:"-"`
 }
 
 type GetCommonStudentsParams struct {
-	Teacher []string `json:"teacher" valid:"email,required"`
+	Teacher []string `json:"teacher" valid:"email,optional"`
+	// Class are ids of classes whose students are retrieved along with those of Teacher, parsed
+	// by GetCommonStudents
+	Class []string `json:"class" valid:"optional"`
+	// Term is the term of the registrations to Teacher, the active term when empty
+	Term string `json:"term" valid:"optional"`
+}
+
+type LoginParams struct {
+	Email    string `json:"email"    valid:"email,required"`
+	Password string `json:"password" valid:"required"`
+}
+
+type CreateUserParams struct {
+	Email    string `json:"email"    valid:"email,required"`
+	Password string `json:"password" valid:"stringlength(8|72),required"`
+	Username string `json:"username" valid:"optional"`
+	Fullname string `json:"fullname" valid:"optional"`
+	Role     string `json:"role"     valid:"in(admin|teacher|student),required"`
+}
+
+type ListParams struct {
+	CreatedAfter string `json:"created_after" valid:"rfc3339,optional"`
+	Query        string `json:"q"             valid:"optional"`
+	Sort         string `json:"sort"          valid:"in(email|-email|created_on|-created_on),optional"`
+	Cursor       string `json:"cursor"        valid:"optional"`
+	Limit        string `json:"limit"         valid:"int,range(1|500),optional"`
+}
+
+type ListStudentsParams struct {
+	ListParams
+	Teacher   string `json:"teacher"   valid:"email,optional"`
+	Suspended string `json:"suspended" valid:"in(true|false),optional"`
+}
+
+type ListNotificationsParams struct {
+	Teacher string `json:"teacher" valid:"email,optional"`
+	Student string `json:"student" valid:"email,optional"`
+	Cursor  string `json:"cursor"  valid:"optional"`
+	Limit   string `json:"limit"   valid:"int,range(1|500),optional"`
+}
+
+type CreateTemplateParams struct {
+	Teacher string `json:"teacher" valid:"email,required"`
+	Name    string `json:"name"    valid:"stringlength(1|100),required"`
+	Body    string `json:"body"    valid:"stringlength(1|5000),required"`
+}
+
+type UpdateTemplateParams struct {
+	Name *string `json:"name" valid:"stringlength(1|100),optional"`
+	Body *string `json:"body" valid:"stringlength(1|5000),optional"`
+}
+
+type UpdatePreferencesParams struct {
+	Unsubscribed    bool   `json:"unsubscribed"      valid:"optional"`
+	BroadcastOptOut bool   `json:"broadcast_opt_out" valid:"optional"`
+	Digest          string `json:"digest"            valid:"in(immediate|daily|weekly),optional"`
+	QuietStart      string `json:"quiet_start"       valid:"optional"`
+	QuietEnd        string `json:"quiet_end"         valid:"optional"`
+	Timezone        string `json:"timezone"          valid:"optional"`
+}
+
+type CreateClassParams struct {
+	Teacher string `json:"teacher" valid:"email,required"`
+	Code    string `json:"code"    valid:"stringlength(1|20),required"`
+	Title   string `json:"title"   valid:"stringlength(0|100),optional"`
+	Term    string `json:"term"    valid:"stringlength(0|20),optional"`
+}
+
+type EnrollStudentsParams struct {
+	Students []string `json:"students" valid:"email,required"`
+}
+
+type CreateTermParams struct {
+	Code     string `json:"code"      valid:"stringlength(1|20),required"`
+	Name     string `json:"name"      valid:"stringlength(0|100),optional"`
+	StartsOn string `json:"starts_on" valid:"required"`
+	EndsOn   string `json:"ends_on"   valid:"required"`
+}
+
+type RolloverTermParams struct {
+	// From is the term whose registrations roll over, empty for the classes without a term
+	From string `json:"from" valid:"optional"`
+	// To is the term the classes and registrations are copied to, required to copy
+	To   string `json:"to"   valid:"optional"`
+	Mode string `json:"mode" valid:"in(copy|archive),required"`
 }
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/opentracing/opentracing-go"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"strings"
+	"time"
+	// embed the time zone database so the time zones of students load in minimal images
+	_ "time/tzdata"
+)
+
+// Digests are sent at digestHour in the time zone of the student, every day or on digestWeekday
+const (
+	digestHour    = 17
+	digestWeekday = time.Friday
+)
+
+// quietHourLayout is the layout of the bounds of quiet hours
+const quietHourLayout = "15:04"
+
+// GetPreferences retrieves the notification preferences of a student, the defaults when the
+// student never set any
+func (s *Service) GetPreferences(ctx context.Context, email string) (models.Preference, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.GetPreferences")
+	defer span.Finish()
+
+	student, err := s.sr.FindByEmail(ctx, email)
+	if err != nil {
+		return models.Preference{}, translate(err, "student")
+	}
+
+	p, err := s.pr.FindByStudent(ctx, student.Email)
+	if errors.As(err, &db.ErrObjectNotFound{}) {
+		return models.DefaultPreference(student.Email), nil
+	}
+	if err != nil {
+		return models.Preference{}, translate(err, "preference")
+	}
+	return p, nil
+}
+
+// UpdatePreferences replaces the notification preferences of a student. Empty fields take their
+// default value.
+func (s *Service) UpdatePreferences(ctx context.Context, email string, params UpdatePreferencesParams) (models.Preference, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.UpdatePreferences")
+	defer span.Finish()
+
+	if err := validate(params); err != nil {
+		return models.Preference{}, err
+	}
+	if err := checkPreferences(params); err != nil {
+		return models.Preference{}, err
+	}
+
+	p := models.DefaultPreference(email)
+	p.Unsubscribed = params.Unsubscribed
+	p.BroadcastOptOut = params.BroadcastOptOut
+	if params.Digest != "" {
+		p.Digest = params.Digest
+	}
+	if params.QuietStart != "" {
+		p.QuietStart, p.QuietEnd = &params.QuietStart, &params.QuietEnd
+	}
+	if params.Timezone != "" {
+		p.Timezone = params.Timezone
+	}
+
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		student, err := s.sr.FindByEmail(ctx, email)
+		if err != nil {
+			return translate(err, "student")
+		}
+		p.StudentID = student.Email
+		return s.pr.Save(ctx, &p)
+	})
+	if err != nil {
+		return models.Preference{}, translate(err, "preference")
+	}
+	return p, nil
+}
+
+// applyPreferences leaves out the recipients whose preferences refuse the notification, a mentioned
+// recipient only when unsubscribed, and returns when the remaining recipients that do not want it
+// right away are emailed
+func (s *Service) applyPreferences(ctx context.Context, recipients, mentioned []string) ([]string, map[string]time.Time, error) {
+	preferences, err := s.pr.FindByStudents(ctx, recipients)
+	if err != nil {
+		return nil, nil, translate(err, "preference")
+	}
+	byStudent := make(map[string]models.Preference, len(preferences))
+	for _, p := range preferences {
+		byStudent[p.StudentID] = p
+	}
+	isMentioned := make(map[string]bool, len(mentioned))
+	for _, email := range mentioned {
+		isMentioned[email] = true
+	}
+
+	now := time.Now()
+	kept := make([]string, 0, len(recipients))
+	deliverAt := map[string]time.Time{}
+	for _, email := range recipients {
+		p, ok := byStudent[email]
+		if !ok {
+			kept = append(kept, email)
+			continue
+		}
+		if !p.Receives(isMentioned[email]) {
+			continue
+		}
+		kept = append(kept, email)
+		if t := DeliveryTime(p, now); t.After(now) {
+			deliverAt[email] = t
+		}
+	}
+	return kept, deliverAt, nil
+}
+
+// checkPreferences requires the quiet hours to be a pair of distinct "HH:MM" times and the time
+// zone to be known
+func checkPreferences(params UpdatePreferencesParams) error {
+	var fields []FieldError
+	for _, f := range []struct{ name, value string }{{"quiet_start", params.QuietStart}, {"quiet_end", params.QuietEnd}} {
+		if f.value == "" {
+			continue
+		}
+		if _, err := time.Parse(quietHourLayout, f.value); err != nil || len(f.value) != len(quietHourLayout) {
+			fields = append(fields, FieldError{Field: f.name, Reason: "must be a time such as 22:00"})
+		}
+	}
+	switch {
+	case params.QuietStart == "" && params.QuietEnd != "":
+		fields = append(fields, FieldError{Field: "quiet_start", Reason: "is required with quiet_end"})
+	case params.QuietStart != "" && params.QuietEnd == "":
+		fields = append(fields, FieldError{Field: "quiet_end", Reason: "is required with quiet_start"})
+	case params.QuietStart != "" && params.QuietStart == params.QuietEnd:
+		fields = append(fields, FieldError{Field: "quiet_end", Reason: "must differ from quiet_start"})
+	}
+	if params.Timezone != "" {
+		if _, err := time.LoadLocation(params.Timezone); err != nil || params.Timezone == "Local" {
+			fields = append(fields, FieldError{Field: "timezone", Reason: "is not a known time zone"})
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(fields))
+	for _, f := range fields {
+		msgs = append(msgs, f.Field+": "+f.Reason)
+	}
+	return Validation("validation_failed", errors.New(strings.Join(msgs, "; ")), fields...)
+}
+
+// DeliveryTime returns when a notification created at now is emailed to the student: at the next
+// digest for a daily or weekly digest, and never during the quiet hours of the student
+func DeliveryTime(p models.Preference, now time.Time) time.Time {
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	t := now.In(loc)
+
+	if p.Digest == models.DigestDaily || p.Digest == models.DigestWeekly {
+		digest := time.Date(t.Year(), t.Month(), t.Day(), digestHour, 0, 0, 0, loc)
+		if digest.Before(t) {
+			digest = digest.AddDate(0, 0, 1)
+		}
+		for p.Digest == models.DigestWeekly && digest.Weekday() != digestWeekday {
+			digest = digest.AddDate(0, 0, 1)
+		}
+		t = digest
+	}
+
+	if p.QuietStart == nil || p.QuietEnd == nil {
+		return t
+	}
+	start, err1 := time.Parse(quietHourLayout, *p.QuietStart)
+	end, err2 := time.Parse(quietHourLayout, *p.QuietEnd)
+	if err1 != nil || err2 != nil {
+		return t
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	from, to := start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+	quiet := from <= minute && minute < to
+	if from > to {
+		// the quiet hours wrap around midnight
+		quiet = minute >= from || minute < to
+	}
+	if !quiet {
+		return t
+	}
+	resume := time.Date(t.Year(), t.Month(), t.Day(), end.Hour(), end.Minute(), 0, 0, loc)
+	if resume.Before(t) {
+		resume = resume.AddDate(0, 0, 1)
+	}
+	return resume
+}
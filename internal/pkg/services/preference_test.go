@@ -0,0 +1,53 @@
+package services_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/services"
+	"time"
+)
+
+var _ = Describe("DeliveryTime", func() {
+	// a Wednesday
+	now := time.Date(2023, 11, 1, 10, 30, 0, 0, time.UTC)
+	hours := func(start, end string) (*string, *string) {
+		return &start, &end
+	}
+
+	DescribeTable("should follow the preferences of the student",
+		func(p models.Preference, expected time.Time) {
+			if p.Timezone == "" {
+				p.Timezone = "UTC"
+			}
+			Expect(services.DeliveryTime(p, now)).Should(BeTemporally("==", expected))
+		},
+		Entry("right away by default", models.Preference{Digest: models.DigestImmediate}, now),
+		Entry("at the daily digest", models.Preference{Digest: models.DigestDaily},
+			time.Date(2023, 11, 1, 17, 0, 0, 0, time.UTC)),
+		Entry("at the weekly digest", models.Preference{Digest: models.DigestWeekly},
+			time.Date(2023, 11, 3, 17, 0, 0, 0, time.UTC)),
+		Entry("at the digest of the time zone", models.Preference{Digest: models.DigestDaily, Timezone: "Asia/Singapore"},
+			time.Date(2023, 11, 2, 9, 0, 0, 0, time.UTC)),
+		Entry("outside of quiet hours", func() models.Preference {
+			p := models.Preference{Digest: models.DigestImmediate}
+			p.QuietStart, p.QuietEnd = hours("22:00", "07:00")
+			return p
+		}(), now),
+		Entry("when quiet hours end", func() models.Preference {
+			p := models.Preference{Digest: models.DigestImmediate}
+			p.QuietStart, p.QuietEnd = hours("09:00", "12:15")
+			return p
+		}(), time.Date(2023, 11, 1, 12, 15, 0, 0, time.UTC)),
+		Entry("when quiet hours wrapping around midnight end", func() models.Preference {
+			p := models.Preference{Digest: models.DigestImmediate, Timezone: "Asia/Singapore"}
+			p.QuietStart, p.QuietEnd = hours("18:00", "07:00")
+			return p
+		}(), time.Date(2023, 11, 1, 23, 0, 0, 0, time.UTC)),
+		Entry("when quiet hours overlapping the digest end", func() models.Preference {
+			p := models.Preference{Digest: models.DigestDaily}
+			p.QuietStart, p.QuietEnd = hours("16:00", "20:00")
+			return p
+		}(), time.Date(2023, 11, 1, 20, 0, 0, 0, time.UTC)),
+	)
+})
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// Variables set for every recipient of a template. They cannot be passed by the sender.
+const (
+	VarStudentName  = "StudentName"
+	VarStudentEmail = "StudentEmail"
+	VarTeacherName  = "TeacherName"
+	VarTeacherEmail = "TeacherEmail"
+)
+
+// maxRenderedLength bounds a rendered message to what the message columns can hold
+const maxRenderedLength = 65535
+
+// variableName matches the names of the variables a sender may pass
+var variableName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// errTooLong is returned when a rendered message exceeds maxRenderedLength
+var errTooLong = fmt.Errorf("rendered message is longer than %d bytes", maxRenderedLength)
+
+// ParseTemplate parses the body of a notification template. Only text, variables such as
+// {{.StudentName}} and {{if .Var}}...{{else}}...{{end}} blocks on variables are allowed, so
+// rendering a template cannot call functions or loop.
+func ParseTemplate(body string) (*template.Template, error) {
+	t, err := template.New("notification").Option("missingkey=error").Parse(body)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkNodes(t.Tree.Root); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// RenderTemplate renders the template with the variables. Using a variable that is not set is an error.
+func RenderTemplate(t *template.Template, vars map[string]string) (string, error) {
+	var b strings.Builder
+	if err := t.Execute(&limitedWriter{w: &b, n: maxRenderedLength}, vars); err != nil {
+		if errors.Is(err, errTooLong) {
+			return "", errTooLong
+		}
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// checkNodes reports the first node of the list that is neither text, a variable nor an if block on a variable
+func checkNodes(list *parse.ListNode) error {
+	if list == nil {
+		return nil
+	}
+	for _, node := range list.Nodes {
+		switch n := node.(type) {
+		case *parse.TextNode:
+		case *parse.ActionNode:
+			if err := checkPipe(n.Pipe); err != nil {
+				return err
+			}
+		case *parse.IfNode:
+			if err := checkPipe(n.Pipe); err != nil {
+				return err
+			}
+			if err := checkNodes(n.List); err != nil {
+				return err
+			}
+			if err := checkNodes(n.ElseList); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%q is not allowed in a template, only variables such as {{.%s}} and if blocks", node, VarStudentName)
+		}
+	}
+	return nil
+}
+
+// checkPipe requires the pipeline to be a single variable such as .StudentName
+func checkPipe(pipe *parse.PipeNode) error {
+	if len(pipe.Decl) == 0 && len(pipe.Cmds) == 1 && len(pipe.Cmds[0].Args) == 1 {
+		if f, ok := pipe.Cmds[0].Args[0].(*parse.FieldNode); ok && len(f.Ident) == 1 {
+			return nil
+		}
+	}
+	return fmt.Errorf("{{%s}} is not allowed in a template, only variables such as {{.%s}}", pipe, VarStudentName)
+}
+
+// checkVariables reports the variables a sender may not pass
+func checkVariables(vars map[string]string) error {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var fields []FieldError
+	for _, name := range names {
+		switch name {
+		case VarStudentName, VarStudentEmail, VarTeacherName, VarTeacherEmail:
+			fields = append(fields, FieldError{Field: "variables." + name, Reason: "is set for every recipient"})
+		default:
+			if !variableName.MatchString(name) {
+				fields = append(fields, FieldError{Field: "variables." + name, Reason: "must be a letter followed by letters, digits or _"})
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(fields))
+	for _, f := range fields {
+		msgs = append(msgs, f.Field+": "+f.Reason)
+	}
+	return Validation("validation_failed", errors.New(strings.Join(msgs, "; ")), fields...)
+}
+
+// limitedWriter fails once more than n bytes are written to w
+type limitedWriter struct {
+	w io.Writer
+	n int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > l.n {
+		return 0, errTooLong
+	}
+	l.n -= len(p)
+	return l.w.Write(p)
+}
@@ -0,0 +1,48 @@
+package services_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/whittier16/student-reg-svc/internal/pkg/services"
+	"strings"
+)
+
+var _ = Describe("ParseTemplate", func() {
+	DescribeTable("should render text, variables and if blocks",
+		func(body string, vars map[string]string, message string) {
+			t, err := services.ParseTemplate(body)
+			Expect(err).Should(BeNil())
+			Expect(services.RenderTemplate(t, vars)).Should(Equal(message))
+		},
+		Entry("text", "Hello students!", nil, "Hello students!"),
+		Entry("variables", "Hi {{.StudentName}}, {{ .Topic }}", map[string]string{"StudentName": "Ann", "Topic": "exam"}, "Hi Ann, exam"),
+		Entry("if blocks", "{{if .Room}}Room {{.Room}}{{else}}Online{{end}}", map[string]string{"Room": ""}, "Online"),
+		Entry("trimmed actions", "Hi {{- .StudentName -}} !", map[string]string{"StudentName": "Ann"}, "HiAnn!"),
+	)
+	DescribeTable("should reject anything else",
+		func(body string) {
+			_, err := services.ParseTemplate(body)
+			Expect(err).ShouldNot(BeNil())
+		},
+		Entry("syntax errors", "Hi {{.StudentName"),
+		Entry("functions", `{{printf "%s" .StudentName}}`),
+		Entry("pipelines", "{{.StudentName | len}}"),
+		Entry("ranges", "{{range .Items}}x{{end}}"),
+		Entry("nested fields", "{{.Student.Name}}"),
+		Entry("variable declarations", "{{$x := .StudentName}}"),
+		Entry("template calls", `{{define "x"}}y{{end}}{{template "x"}}`),
+		Entry("functions in if blocks", "{{if eq .A .B}}x{{end}}"),
+	)
+	It("RenderTemplate should fail on variables that are not set", func() {
+		t, err := services.ParseTemplate("Hi {{.Nickname}}")
+		Expect(err).Should(BeNil())
+		_, err = services.RenderTemplate(t, map[string]string{})
+		Expect(err).ShouldNot(BeNil())
+	})
+	It("RenderTemplate should fail on messages that are too long", func() {
+		t, err := services.ParseTemplate(strings.Repeat("{{.A}}", 100))
+		Expect(err).Should(BeNil())
+		_, err = services.RenderTemplate(t, map[string]string{"A": strings.Repeat("x", 1000)})
+		Expect(err).ShouldNot(BeNil())
+	})
+})
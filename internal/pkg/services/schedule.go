@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/opentracing/opentracing-go"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"time"
+)
+
+// ScheduleNotification queues a notification of a teacher to be sent at params.SendAt. Its
+// recipients are resolved when it is sent, see SendDueNotifications, so the students suspended
+// in the meantime are left out.
+func (s *Service) ScheduleNotification(ctx context.Context, params SendNotificationsParams) (models.ScheduledNotification, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.ScheduleNotification")
+	defer span.Finish()
+
+	if err := validate(params); err != nil {
+		return models.ScheduledNotification{}, err
+	}
+	if err := checkNotificationSource(params); err != nil {
+		return models.ScheduledNotification{}, err
+	}
+	if params.SendAt == "" {
+		return models.ScheduledNotification{}, Validation("validation_failed", errors.New("send_at: is required"),
+			FieldError{Field: "send_at", Reason: reasons["required"]})
+	}
+	sendAt, _ := time.Parse(time.RFC3339, params.SendAt)
+	if !sendAt.After(time.Now()) {
+		return models.ScheduledNotification{}, Validation("validation_failed", errors.New("send_at: must be in the future"),
+			FieldError{Field: "send_at", Reason: "must be in the future"})
+	}
+
+	sched := models.ScheduledNotification{
+		TeacherID: params.Teacher,
+		Message:   params.Notifications,
+		Variables: params.Variables,
+		SendAt:    sendAt,
+	}
+	if params.TemplateID != 0 {
+		sched.TemplateID = &params.TemplateID
+	}
+	if params.Class != 0 {
+		sched.ClassID = &params.Class
+	}
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		teacher, err := s.tr.FindByEmail(ctx, params.Teacher)
+		if err != nil {
+			return translate(err, "teacher")
+		}
+		if params.Class != 0 {
+			if _, err = s.cl.FindByID(ctx, params.Teacher, params.Class); err != nil {
+				return translate(err, "class")
+			}
+		}
+		// the template must exist and render with the variables now, rather than fail when it is due
+		if _, _, err = s.notificationMessage(ctx, teacher, params); err != nil {
+			return err
+		}
+		return s.sc.Create(ctx, &sched)
+	})
+	if err != nil {
+		return models.ScheduledNotification{}, translate(err, "scheduled_notification")
+	}
+	return sched, nil
+}
+
+// ListScheduledNotifications retrieves the pending scheduled notifications of the teacher, the next one first
+func (s *Service) ListScheduledNotifications(ctx context.Context, teacher string) ([]models.ScheduledNotification, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.ListScheduledNotifications")
+	defer span.Finish()
+
+	_, err := s.tr.FindByEmail(ctx, teacher)
+	if err != nil {
+		return nil, translate(err, "teacher")
+	}
+
+	schedules, err := s.sc.FindPending(ctx, teacher)
+	if err != nil {
+		return nil, translate(err, "scheduled_notification")
+	}
+	return schedules, nil
+}
+
+// CancelScheduledNotification cancels a pending scheduled notification of the teacher
+func (s *Service) CancelScheduledNotification(ctx context.Context, teacher string, id int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.CancelScheduledNotification")
+	defer span.Finish()
+
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		sched, err := s.sc.FindByID(ctx, teacher, id)
+		if err != nil {
+			return err
+		}
+		if sched.Status != models.SchedulePending {
+			return Conflict("scheduled_notification_not_pending", "scheduled notification is already "+sched.Status)
+		}
+		return s.sc.Cancel(ctx, teacher, id)
+	})
+	return translate(err, "scheduled_notification")
+}
+
+// SendDueNotifications sends up to limit scheduled notifications whose send date passed at now, like
+// SendNotifications, and returns how many were sent. A scheduled notification that can no longer be
+// sent, e.g. because its template or class was deleted, is marked failed. It stops at the first
+// unexpected error, leaving the remaining notifications pending.
+func (s *Service) SendDueNotifications(ctx context.Context, now time.Time, limit int) (int, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.SendDueNotifications")
+	defer span.Finish()
+
+	due, err := s.sc.Due(ctx, now, limit)
+	if err != nil {
+		return 0, translate(err, "scheduled_notification")
+	}
+
+	sent := 0
+	for _, sched := range due {
+		params := SendNotificationsParams{
+			Teacher:       sched.TeacherID,
+			Notifications: sched.Message,
+			Variables:     sched.Variables,
+		}
+		if sched.TemplateID != nil {
+			params.TemplateID = *sched.TemplateID
+		}
+		if sched.ClassID != nil {
+			params.Class = *sched.ClassID
+		}
+
+		claimed := true
+		err := s.uow.Do(ctx, func(ctx context.Context) error {
+			// another instance may be sending it, or it was cancelled since it was read
+			if err := s.sc.Claim(ctx, sched.ID); err != nil {
+				claimed = false
+				return err
+			}
+			res, err := s.send(ctx, params)
+			if err != nil {
+				return err
+			}
+			return s.sc.MarkSent(ctx, sched.ID, res.NotificationID)
+		})
+		if err == nil {
+			sent++
+			continue
+		}
+		if !claimed && errors.As(err, &db.ErrObjectNotFound{}) {
+			continue
+		}
+		if err = translate(err, "scheduled_notification"); KindOf(err) == KindInternal {
+			return sent, err
+		}
+
+		reason := err.Error()
+		err = s.uow.Do(ctx, func(ctx context.Context) error {
+			return s.sc.MarkFailed(ctx, sched.ID, reason)
+		})
+		if err != nil && !errors.As(err, &db.ErrObjectNotFound{}) {
+			return sent, translate(err, "scheduled_notification")
+		}
+	}
+	return sent, nil
+}
@@ -2,35 +2,49 @@ package services
 
 import (
 	"context"
-	"database/sql"
 	"errors"
-	"github.com/asaskevich/govalidator"
+	"fmt"
 	"github.com/opentracing/opentracing-go"
 	"github.com/whittier16/student-reg-svc/internal/app/models"
-	"github.com/whittier16/student-reg-svc/internal/app/repository"
-	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"sort"
+	"strconv"
 	"strings"
+	"text/template"
 )
 
-// TODO implement interface
-// Repo defines the DB level interaction of student registration
-// type Repo interface {
-//	Register(ctx context.Context, teacherEmail string, studentEmails []string) (err error)
-// }
-
 // Service uses repositories to provide an API for managing student registrations
 type Service struct {
-	sr repository.StudentRepository
-	tr repository.TeacherRepository
-	rr repository.RegisterRepository
+	sr  StudentStore
+	tr  TeacherStore
+	rr  RegistrationStore
+	hr  SuspensionStore
+	nr  NotificationStore
+	tm  TemplateStore
+	sc  ScheduleStore
+	pr  PreferenceStore
+	cl  ClassStore
+	tt  TermStore
+	ur  UserStore
+	uow UnitOfWork
+	// term is the code of the configured active term, see SetActiveTerm
+	term string
 }
 
 // NewService returns a new instance of Service
-func NewService(sr repository.StudentRepository, tr repository.TeacherRepository, rr repository.RegisterRepository) Service {
+func NewService(sr StudentStore, tr TeacherStore, rr RegistrationStore, hr SuspensionStore, nr NotificationStore, tm TemplateStore, sc ScheduleStore, pr PreferenceStore, cl ClassStore, tt TermStore, ur UserStore, uow UnitOfWork) Service {
 	return Service{
-		sr: sr,
-		tr: tr,
-		rr: rr,
+		sr:  sr,
+		tr:  tr,
+		rr:  rr,
+		hr:  hr,
+		nr:  nr,
+		tm:  tm,
+		sc:  sc,
+		pr:  pr,
+		cl:  cl,
+		tt:  tt,
+		ur:  ur,
+		uow: uow,
 	}
 }
 
@@ -39,34 +53,18 @@ func (s *Service) CreateStudent(ctx context.Context, params CreateStudentParams)
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.CreateStudent")
 	defer span.Finish()
 
-	if _, err := govalidator.ValidateStruct(params); err != nil {
-		return err
-	}
-
-	tx, err := s.sr.DB.BeginTx(ctx, nil)
-	if err != nil {
+	if err := validate(params); err != nil {
 		return err
 	}
 
-	// Defer a rollback in case anything fails.
-	defer func(tx *sql.Tx) {
-		err := tx.Rollback()
-		if err != nil {
-			return
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		entity := models.Student{
+			Name:  params.Name,
+			Email: params.Email,
 		}
-	}(tx)
-
-	entity := models.Student{
-		Name:  params.Name,
-		Email: params.Email,
-	}
-	err = s.sr.Create(ctx, &entity)
-	if err != nil {
-		return err
-	}
-
-	err = tx.Commit()
-	return err
+		return s.sr.Create(ctx, &entity)
+	})
+	return translate(err, "student")
 }
 
 // GetStudent sends the request straight to the repo and retrieves student record
@@ -75,190 +73,511 @@ func (s *Service) GetStudent(ctx context.Context, email string) (models.Student,
 	defer span.Finish()
 
 	student, err := s.sr.FindByEmail(ctx, email)
-	switch {
-	case err == nil:
-	case errors.As(err, &db.ErrObjectNotFound{}):
-		return models.Student{}, errors.New("student object not found")
-	default:
+	if err != nil {
+		return models.Student{}, translate(err, "student")
+	}
+
+	return student, nil
+}
+
+// UpdateStudent changes the name and email of a student. Empty fields are left unchanged. The user
+// account of the student follows an email change.
+func (s *Service) UpdateStudent(ctx context.Context, email string, params UpdateStudentParams) (models.Student, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.UpdateStudent")
+	defer span.Finish()
+
+	if err := validate(params); err != nil {
 		return models.Student{}, err
 	}
+	if params.Email == "" && params.Name == nil {
+		return models.Student{}, Validation("validation_failed", errors.New("email or name is required"),
+			FieldError{Field: "email", Reason: "is required"}, FieldError{Field: "name", Reason: "is required"})
+	}
+
+	var student models.Student
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		student, err = s.sr.FindByEmail(ctx, email)
+		if err != nil {
+			return translate(err, "student")
+		}
+
+		if params.Email != "" {
+			student.Email = params.Email
+		}
+		if params.Name != nil {
+			student.Name = *params.Name
+		}
+		if err := s.sr.Update(ctx, email, &student); err != nil {
+			return err
+		}
+		if err := s.renameUser(ctx, models.RoleStudent, email, student.Email); err != nil {
+			return err
+		}
 
+		student, err = s.sr.FindByEmail(ctx, student.Email)
+		return err
+	})
+	if err != nil {
+		return models.Student{}, translate(err, "student")
+	}
 	return student, nil
 }
 
+// DeleteStudent soft deletes a student
+func (s *Service) DeleteStudent(ctx context.Context, email string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.DeleteStudent")
+	defer span.Finish()
+
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		return s.sr.Delete(ctx, email)
+	})
+	return translate(err, "student")
+}
+
+// RestoreStudent undoes the soft delete of a student
+func (s *Service) RestoreStudent(ctx context.Context, email string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.RestoreStudent")
+	defer span.Finish()
+
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		if _, err := s.sr.FindByEmail(ctx, email); err == nil {
+			return Conflict("student_not_deleted", "student is not deleted")
+		}
+		return s.sr.Restore(ctx, email)
+	})
+	return translate(err, "student")
+}
+
 // GetTeacher sends the request straight to the repo and retrieves teacher record
 func (s *Service) GetTeacher(ctx context.Context, email string) (models.Teacher, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.GetTeacher")
 	defer span.Finish()
 
 	teacher, err := s.tr.FindByEmail(ctx, email)
-	switch {
-	case err == nil:
-	case errors.As(err, &db.ErrObjectNotFound{}):
-		return models.Teacher{}, errors.New("teacher object not found")
-	default:
-		return models.Teacher{}, err
+	if err != nil {
+		return models.Teacher{}, translate(err, "teacher")
 	}
 
 	return teacher, nil
 }
 
-// Register retrieves emails and passes of the created records to the repo
-func (s *Service) Register(ctx context.Context, params RegisterStudentsParams) error {
+// Register modes of RegisterStudentsParams
+const (
+	// RegisterStrict registers every student or none, failing on existing registrations
+	RegisterStrict = "strict"
+	// RegisterUpsert registers every student or none, skipping existing registrations
+	RegisterUpsert = "upsert"
+	// RegisterPartial registers the students it can and reports the others
+	RegisterPartial = "partial"
+)
+
+// Registration statuses of RegistrationResult
+const (
+	StatusRegistered        = "registered"
+	StatusAlreadyRegistered = "already_registered"
+	StatusNotFound          = "not_found"
+	StatusSuspended         = "suspended"
+)
+
+// RegistrationResult is the outcome of registering one student
+type RegistrationResult struct {
+	Student string
+	Status  string
+	// Created is set when the student was created by the registration
+	Created bool
+}
+
+// Register registers the students to the teacher in the active term and reports the outcome for
+// each student, in the order they were given. How unknown or suspended students and existing registrations are
+// handled depends on the mode, see RegisterStrict, RegisterUpsert and RegisterPartial. With
+// CreateMissing unknown students are created in the same transaction instead.
+func (s *Service) Register(ctx context.Context, params RegisterStudentsParams) ([]RegistrationResult, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.Register")
 	defer span.Finish()
 
-	if _, err := govalidator.ValidateStruct(params); err != nil {
-		return err
+	if err := validate(params); err != nil {
+		return nil, err
+	}
+	mode := params.Mode
+	if mode == "" {
+		mode = RegisterStrict
 	}
 
-	_, err := s.tr.FindByEmail(ctx, params.TeacherEmail)
+	var results []RegistrationResult
+	// verify the teacher and students and insert the registrations in one transaction
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		_, err := s.tr.FindByEmail(ctx, params.TeacherEmail)
+		if err != nil {
+			return translate(err, "teacher")
+		}
+		term, err := s.activeTerm(ctx)
+		if err != nil {
+			return err
+		}
+
+		existing, err := s.sr.FindByEmailArr(ctx, params.StudentEmails, true)
+		if err != nil {
+			return err
+		}
+		missing := difference(params.StudentEmails, existing)
+		created := map[string]bool{}
+		if params.CreateMissing {
+			for _, email := range missing {
+				err = s.sr.Create(ctx, &models.Student{Email: email, Name: params.Names[email]})
+				if err != nil {
+					return translate(err, "student")
+				}
+				created[email] = true
+			}
+			missing = nil
+		}
+		if len(missing) > 0 && mode != RegisterPartial {
+			return NotFound("student_not_found", "student not found: "+strings.Join(missing, ", "))
+		}
+
+		active, err := s.sr.FindNotSuspended(ctx, params.StudentEmails, params.TeacherEmail)
+		if err != nil {
+			return err
+		}
+		suspended := difference(existing, active)
+		if len(suspended) > 0 && mode != RegisterPartial {
+			return Suspended("student_suspended", "student is suspended: "+strings.Join(suspended, ", "))
+		}
+
+		if mode == RegisterStrict {
+			err = s.rr.Register(ctx, params.TeacherEmail, term, params.StudentEmails)
+			if err != nil {
+				return translate(err, "registration")
+			}
+			for _, email := range params.StudentEmails {
+				results = append(results, RegistrationResult{Student: email, Status: StatusRegistered, Created: created[email]})
+			}
+			return nil
+		}
+
+		registered, err := s.rr.FindRegistered(ctx, params.TeacherEmail, term, active)
+		if err != nil {
+			return err
+		}
+		err = s.rr.Upsert(ctx, params.TeacherEmail, term, difference(active, registered))
+		if err != nil {
+			return translate(err, "registration")
+		}
+
+		status := make(map[string]string, len(params.StudentEmails))
+		for _, email := range missing {
+			status[email] = StatusNotFound
+		}
+		for _, email := range suspended {
+			status[email] = StatusSuspended
+		}
+		for _, email := range registered {
+			status[email] = StatusAlreadyRegistered
+		}
+		for _, email := range unique(params.StudentEmails) {
+			if status[email] == "" {
+				status[email] = StatusRegistered
+			}
+			results = append(results, RegistrationResult{Student: email, Status: status[email], Created: created[email]})
+		}
+		return nil
+	})
 	if err != nil {
-		return err
+		return nil, translate(err, "registration")
 	}
+	return results, nil
+}
 
-	res, err := s.sr.FindByEmailArr(ctx, params.StudentEmails, false)
-	if len(res) < len(params.StudentEmails) {
-		return errors.New("student not found in database")
-	}
+// Unregister removes the registrations of the students to the teacher in the active term. Students
+// that are not registered are skipped, so it returns the students that were actually unregistered.
+func (s *Service) Unregister(ctx context.Context, params UnregisterStudentsParams) ([]string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.Unregister")
+	defer span.Finish()
 
-	tx, err := s.rr.DB.BeginTx(ctx, nil)
-	if err != nil {
-		return err
+	if err := validate(params); err != nil {
+		return []string{}, err
 	}
 
-	// Defer a rollback in case anything fails.
-	defer tx.Rollback()
+	unregistered := []string{}
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		_, err := s.tr.FindByEmail(ctx, params.TeacherEmail)
+		if err != nil {
+			return translate(err, "teacher")
+		}
+		term, err := s.activeTerm(ctx)
+		if err != nil {
+			return err
+		}
 
-	err = s.rr.Register(ctx, params.TeacherEmail, params.StudentEmails)
+		res, err := s.rr.Unregister(ctx, params.TeacherEmail, term, unique(params.StudentEmails))
+		if err != nil {
+			return err
+		}
+		unregistered = append(unregistered, res...)
+		return nil
+	})
 	if err != nil {
-		return err
+		return []string{}, translate(err, "registration")
 	}
-
-	err = tx.Commit()
-	return err
+	return unregistered, nil
 }
 
-// GetCommonStudents retrieves common students to the repo
+// GetCommonStudents retrieves the students registered to any of the given teachers in a term, the
+// active term by default, or enrolled to any of the given classes
 func (s *Service) GetCommonStudents(ctx context.Context, params GetCommonStudentsParams) ([]string, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.GetCommonStudents")
 	defer span.Finish()
 
-	if _, err := govalidator.ValidateStruct(params); err != nil {
+	if err := validate(params); err != nil {
 		return []string{}, err
 	}
-
-	cs, err := s.rr.FindByEmailArr(ctx, params.Teacher)
-	switch {
-	case err == nil:
-	case errors.As(err, &db.ErrObjectNotFound{}):
-		return []string{}, errors.New("teacher object not found")
-	default:
+	if len(params.Teacher) == 0 && len(params.Class) == 0 {
+		return []string{}, Validation("validation_failed", errors.New("teacher: is required"),
+			FieldError{Field: "teacher", Reason: reasons["required"]})
+	}
+	// govalidator skips the elements of slices, so the class ids are parsed here
+	ids := make([]int, 0, len(params.Class))
+	var fields []FieldError
+	for i, c := range params.Class {
+		id, err := strconv.Atoi(c)
+		if err != nil {
+			fields = append(fields, FieldError{Field: fmt.Sprintf("class[%d]", i), Reason: reasons["int"]})
+			continue
+		}
+		ids = append(ids, id)
+	}
+	if err := invalid(fields); err != nil {
 		return []string{}, err
 	}
+
+	term := params.Term
+	if term == "" && len(params.Teacher) > 0 {
+		var err error
+		if term, err = s.activeTerm(ctx); err != nil {
+			return []string{}, err
+		}
+	}
+	if len(params.Class) == 0 {
+		cs, err := s.rr.FindByEmailArr(ctx, params.Teacher, term)
+		if err != nil {
+			return []string{}, translate(err, "teacher")
+		}
+		// a term without registrations still lists an empty array of students
+		if cs == nil {
+			cs = []string{}
+		}
+		return cs, nil
+	}
+
+	cs, err := s.rr.FindByClasses(ctx, ids)
+	if err != nil {
+		return []string{}, translate(err, "class")
+	}
+	if len(params.Teacher) > 0 {
+		ts, err := s.rr.FindByEmailArr(ctx, params.Teacher, term)
+		if err != nil {
+			return []string{}, translate(err, "teacher")
+		}
+		cs = append(cs, ts...)
+	}
+	cs = unique(cs)
+	sort.Strings(cs)
 	return cs, nil
 }
 
-// SendNotifications retrieves emails for sending notification
-func (s *Service) SendNotifications(ctx context.Context, params SendNotificationsParams) ([]string, error) {
+// NotificationRecipients are the students who receive a notification
+type NotificationRecipients struct {
+	// NotificationID is the notification kept in the history
+	NotificationID int
+	// Recipients are the students registered to the teacher or mentioned in the notification
+	// that are not suspended from the teacher's class
+	Recipients []string
+	// UnknownMentions are the mentioned addresses that do not belong to any student
+	UnknownMentions []string
+	// Messages holds the message rendered for each recipient of a template, keyed by email
+	Messages map[string]string
+}
+
+// SendNotifications retrieves the students who receive the notification of a teacher: their
+// registered students and the students mentioned in the text, see ParseMentions. The
+// notification is kept in the history of the teacher and of every recipient.
+//
+// A notification sent from a template is rendered for every recipient. The mentions are
+// parsed from the template rendered without a student, which is also the message kept in
+// the history of the teacher.
+func (s *Service) SendNotifications(ctx context.Context, params SendNotificationsParams) (NotificationRecipients, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.SendNotifications")
 	defer span.Finish()
 
-	if _, err := govalidator.ValidateStruct(params); err != nil {
-		return []string{}, err
+	if err := validate(params); err != nil {
+		return NotificationRecipients{Recipients: []string{}, UnknownMentions: []string{}}, err
+	}
+	if err := checkNotificationSource(params); err != nil {
+		return NotificationRecipients{Recipients: []string{}, UnknownMentions: []string{}}, err
 	}
 
-	_, err := s.tr.FindByEmail(ctx, params.Teacher)
+	var res NotificationRecipients
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		res, err = s.send(ctx, params)
+		return err
+	})
 	if err != nil {
-		return []string{}, err
+		return NotificationRecipients{Recipients: []string{}, UnknownMentions: []string{}}, translate(err, "notification")
 	}
+	return res, nil
+}
 
-	teacherEmail := []string{}
-	teacherEmail = append(teacherEmail, params.Teacher)
-	resRegEmails, err := s.rr.FindByEmailArr(ctx, teacherEmail)
+// send resolves the recipients of a notification and stores it. It must run in a unit of work,
+// so the recipients are stored as they are computed.
+func (s *Service) send(ctx context.Context, params SendNotificationsParams) (NotificationRecipients, error) {
+	res := NotificationRecipients{Recipients: []string{}, UnknownMentions: []string{}}
+	teacher, err := s.tr.FindByEmail(ctx, params.Teacher)
 	if err != nil {
-		return []string{}, err
+		return res, translate(err, "teacher")
 	}
 
-	txtNotif := params.Notifications
-	txts := strings.Split(txtNotif, " @")
-	notifEmails := []string{}
-	for _, tx := range txts {
-		i := strings.Index(tx, "@")
-		if i > -1 {
-			notifEmails = append(notifEmails, tx)
-		}
+	message, tmpl, err := s.notificationMessage(ctx, teacher, params)
+	if err != nil {
+		return res, err
 	}
 
-	resNotifEmails, err := s.sr.FindByEmailArr(ctx, notifEmails, false)
+	var resRegEmails []string
+	if params.Class != 0 {
+		// only the students of the class, the mentioned students are added below
+		if _, err = s.cl.FindByID(ctx, params.Teacher, params.Class); err != nil {
+			return res, translate(err, "class")
+		}
+		resRegEmails, err = s.rr.FindByClasses(ctx, []int{params.Class})
+	} else {
+		var term string
+		if term, err = s.activeTerm(ctx); err != nil {
+			return res, err
+		}
+		resRegEmails, err = s.rr.FindByEmailArr(ctx, []string{params.Teacher}, term)
+	}
 	if err != nil {
-		return []string{}, err
+		return res, translate(err, "registration")
 	}
 
-	resRegEmails = append(resRegEmails, resNotifEmails...)
-	list := unique(resRegEmails)
-	return list, err
-}
-
-// CreateTeacher creates teacher record to the repo
-func (s *Service) CreateTeacher(ctx context.Context, params CreateTeacherParams) error {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.CreateTeacher")
-	defer span.Finish()
-
-	if _, err := govalidator.ValidateStruct(params); err != nil {
-		return err
+	mentions := ParseMentions(message)
+	existing, err := s.sr.FindByEmailArr(ctx, mentions, true)
+	if err != nil {
+		return res, translate(err, "student")
+	}
+	resNotifEmails, err := s.sr.FindNotSuspended(ctx, mentions, params.Teacher)
+	if err != nil {
+		return res, translate(err, "student")
 	}
 
-	tx, err := s.sr.DB.BeginTx(ctx, nil)
+	recipients, deliverAt, err := s.applyPreferences(ctx, unique(append(resRegEmails, resNotifEmails...)), resNotifEmails)
 	if err != nil {
-		return err
+		return res, err
 	}
 
-	// Defer a rollback in case anything fails.
-	defer func(tx *sql.Tx) {
-		err := tx.Rollback()
+	n := models.Notification{
+		TeacherID:  params.Teacher,
+		Message:    message,
+		Recipients: recipients,
+		DeliverAt:  deliverAt,
+	}
+	if tmpl != nil {
+		students, err := s.sr.FindByEmails(ctx, n.Recipients)
 		if err != nil {
-			return
+			return res, translate(err, "student")
+		}
+		n.Messages = make(map[string]string, len(students))
+		for _, student := range students {
+			n.Messages[student.Email], err = renderNotification(tmpl, teacher, student, params.Variables)
+			if err != nil {
+				return res, err
+			}
 		}
-	}(tx)
-
-	entity := models.Teacher{
-		Name:  params.Name,
-		Email: params.Email,
 	}
-	err = s.tr.Create(ctx, &entity)
+	err = s.nr.Create(ctx, &n)
 	if err != nil {
-		return err
+		return res, err
 	}
 
-	err = tx.Commit()
-	return err
+	res.NotificationID = n.ID
+	res.Recipients = n.Recipients
+	res.UnknownMentions = difference(mentions, existing)
+	res.Messages = n.Messages
+	return res, nil
 }
 
-// Suspend retrieves student record and suspends a student
-func (s *Service) Suspend(ctx context.Context, params SuspendStudentsParams) error {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.Suspend")
-	defer span.Finish()
+// notificationMessage returns the text of the notification or, for a template, the template and
+// its rendering without a student
+func (s *Service) notificationMessage(ctx context.Context, teacher models.Teacher, params SendNotificationsParams) (string, *template.Template, error) {
+	if params.TemplateID == 0 {
+		return params.Notifications, nil, nil
+	}
 
-	// find student object
-	_, err := s.sr.FindByEmail(ctx, params.Student)
+	t, err := s.tm.FindByID(ctx, params.Teacher, params.TemplateID)
 	if err != nil {
-		return err
+		return "", nil, translate(err, "template")
 	}
-
-	tx, err := s.sr.DB.BeginTx(ctx, nil)
+	tmpl, err := ParseTemplate(t.Body)
 	if err != nil {
-		return err
+		return "", nil, err
+	}
+	message, err := renderNotification(tmpl, teacher, models.Student{}, params.Variables)
+	if err != nil {
+		return "", nil, err
 	}
+	return message, tmpl, nil
+}
+
+// checkNotificationSource requires a notification to be either a text or a template
+func checkNotificationSource(params SendNotificationsParams) error {
+	switch {
+	case params.Notifications == "" && params.TemplateID == 0:
+		return Validation("validation_failed", errors.New("notifications: is required"),
+			FieldError{Field: "notifications", Reason: reasons["required"]})
+	case params.Notifications != "" && params.TemplateID != 0:
+		return Validation("validation_failed", errors.New("notifications: cannot be sent with a template"),
+			FieldError{Field: "notifications", Reason: "cannot be sent with a template"})
+	case params.TemplateID == 0 && len(params.Variables) > 0:
+		return Validation("validation_failed", errors.New("variables: are only used with a template"),
+			FieldError{Field: "variables", Reason: "are only used with a template"})
+	}
+	return checkVariables(params.Variables)
+}
 
-	// Defer a rollback in case anything fails.
-	defer tx.Rollback()
+// renderNotification renders the template for the student with the variables of the sender
+func renderNotification(t *template.Template, teacher models.Teacher, student models.Student, vars map[string]string) (string, error) {
+	data := make(map[string]string, len(vars)+4)
+	for k, v := range vars {
+		data[k] = v
+	}
+	data[VarTeacherName] = teacher.Name
+	data[VarTeacherEmail] = teacher.Email
+	data[VarStudentName] = student.Name
+	data[VarStudentEmail] = student.Email
 
-	err = s.rr.Suspend(ctx, params.Student)
+	message, err := RenderTemplate(t, data)
 	if err != nil {
+		return "", Validation("invalid_variables", err, FieldError{Field: "variables", Reason: "cannot render the template"})
+	}
+	return message, nil
+}
+
+// CreateTeacher creates teacher record to the repo
+func (s *Service) CreateTeacher(ctx context.Context, params CreateTeacherParams) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.CreateTeacher")
+	defer span.Finish()
+
+	if err := validate(params); err != nil {
 		return err
 	}
 
-	err = tx.Commit()
-	return err
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		entity := models.Teacher{
+			Name:  params.Name,
+			Email: params.Email,
+		}
+		return s.tr.Create(ctx, &entity)
+	})
+	return translate(err, "teacher")
 }
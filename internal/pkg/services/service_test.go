@@ -0,0 +1,528 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/app/repository/memory"
+	"github.com/whittier16/student-reg-svc/internal/pkg/services"
+	"strconv"
+	"time"
+)
+
+var _ = Describe("Service", func() {
+	var (
+		ctx context.Context
+		rr  *memory.RegisterRepository
+		svc services.Service
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		store := memory.New()
+		rr = memory.NewRegisterRepository(store)
+		svc = services.NewService(
+			memory.NewStudentRepository(store),
+			memory.NewTeacherRepository(store),
+			rr,
+			memory.NewSuspensionRepository(store),
+			memory.NewNotificationRepository(store),
+			memory.NewTemplateRepository(store),
+			memory.NewScheduleRepository(store),
+			memory.NewPreferenceRepository(store),
+			memory.NewClassRepository(store),
+			memory.NewTermRepository(store),
+			memory.NewUserRepository(store),
+			memory.NewUnitOfWork(store),
+		)
+
+		for _, email := range []string{"teacher1@gmail.com", "teacher2@gmail.com"} {
+			Expect(svc.CreateTeacher(ctx, services.CreateTeacherParams{Email: email})).Should(Succeed())
+		}
+		for _, email := range []string{"student1@gmail.com", "student2@gmail.com", "student3@gmail.com", "student4@gmail.com"} {
+			Expect(svc.CreateStudent(ctx, services.CreateStudentParams{Email: email})).Should(Succeed())
+		}
+		Expect(rr.Register(ctx, "teacher1@gmail.com", "", []string{"student1@gmail.com", "student2@gmail.com"})).Should(Succeed())
+		Expect(rr.Register(ctx, "teacher2@gmail.com", "", []string{"student1@gmail.com", "student3@gmail.com"})).Should(Succeed())
+	})
+
+	register := func(params services.RegisterStudentsParams) error {
+		_, err := svc.Register(ctx, params)
+		return err
+	}
+
+	It("Register in upsert mode should skip existing registrations", func() {
+		res, err := svc.Register(ctx, services.RegisterStudentsParams{
+			TeacherEmail:  "teacher1@gmail.com",
+			StudentEmails: []string{"student1@gmail.com", "student4@gmail.com", "student4@gmail.com"},
+			Mode:          services.RegisterUpsert,
+		})
+		Expect(err).Should(BeNil())
+		Expect(res).Should(Equal([]services.RegistrationResult{
+			{Student: "student1@gmail.com", Status: services.StatusAlreadyRegistered},
+			{Student: "student4@gmail.com", Status: services.StatusRegistered},
+		}))
+
+		err = register(services.RegisterStudentsParams{
+			TeacherEmail:  "teacher1@gmail.com",
+			StudentEmails: []string{"student3@gmail.com", "nobody@gmail.com"},
+			Mode:          services.RegisterUpsert,
+		})
+		Expect(services.KindOf(err)).Should(Equal(services.KindNotFound))
+	})
+	It("Register in partial mode should register what it can and report the rest", func() {
+		Expect(svc.Suspend(ctx, services.SuspendStudentsParams{Student: "student3@gmail.com", Reason: "misconduct"})).Should(Succeed())
+
+		res, err := svc.Register(ctx, services.RegisterStudentsParams{
+			TeacherEmail:  "teacher1@gmail.com",
+			StudentEmails: []string{"nobody@gmail.com", "student1@gmail.com", "student3@gmail.com", "student4@gmail.com"},
+			Mode:          services.RegisterPartial,
+		})
+		Expect(err).Should(BeNil())
+		Expect(res).Should(Equal([]services.RegistrationResult{
+			{Student: "nobody@gmail.com", Status: services.StatusNotFound},
+			{Student: "student1@gmail.com", Status: services.StatusAlreadyRegistered},
+			{Student: "student3@gmail.com", Status: services.StatusSuspended},
+			{Student: "student4@gmail.com", Status: services.StatusRegistered},
+		}))
+
+		students, err := svc.GetCommonStudents(ctx, services.GetCommonStudentsParams{Teacher: []string{"teacher1@gmail.com"}})
+		Expect(err).Should(BeNil())
+		Expect(students).Should(Equal([]string{"student1@gmail.com", "student2@gmail.com", "student4@gmail.com"}))
+	})
+	It("Register with create_missing should create the unknown students in the same transaction", func() {
+		res, err := svc.Register(ctx, services.RegisterStudentsParams{
+			TeacherEmail:  "teacher1@gmail.com",
+			StudentEmails: []string{"student4@gmail.com", "student5@gmail.com"},
+			CreateMissing: true,
+			Names:         map[string]string{"student5@gmail.com": "Student5"},
+		})
+		Expect(err).Should(BeNil())
+		Expect(res).Should(Equal([]services.RegistrationResult{
+			{Student: "student4@gmail.com", Status: services.StatusRegistered},
+			{Student: "student5@gmail.com", Status: services.StatusRegistered, Created: true},
+		}))
+		student, err := svc.GetStudent(ctx, "student5@gmail.com")
+		Expect(err).Should(BeNil())
+		Expect(student.Name).Should(Equal("Student5"))
+
+		// a failing registration rolls the created students back
+		err = register(services.RegisterStudentsParams{
+			TeacherEmail:  "teacher1@gmail.com",
+			StudentEmails: []string{"student1@gmail.com", "student6@gmail.com"},
+			CreateMissing: true,
+		})
+		Expect(services.KindOf(err)).Should(Equal(services.KindAlreadyExists))
+		_, err = svc.GetStudent(ctx, "student6@gmail.com")
+		Expect(services.KindOf(err)).Should(Equal(services.KindNotFound))
+	})
+	It("ListStudents should page through the students with a cursor", func() {
+		params := services.ListStudentsParams{ListParams: services.ListParams{Limit: "3"}}
+		page, err := svc.ListStudents(ctx, params)
+		Expect(err).Should(BeNil())
+		Expect(page.Total).Should(Equal(4))
+		Expect(page.Students).Should(HaveLen(3))
+		Expect(page.NextCursor).ShouldNot(BeEmpty())
+
+		params.Cursor = page.NextCursor
+		page, err = svc.ListStudents(ctx, params)
+		Expect(err).Should(BeNil())
+		Expect(page.Students).Should(HaveLen(1))
+		Expect(page.Students[0].Email).Should(Equal("student4@gmail.com"))
+		Expect(page.NextCursor).Should(BeEmpty())
+	})
+	It("ListStudents should filter by teacher and suspension", func() {
+		Expect(svc.Suspend(ctx, services.SuspendStudentsParams{Student: "student1@gmail.com", Reason: "misconduct"})).Should(Succeed())
+
+		page, err := svc.ListStudents(ctx, services.ListStudentsParams{Teacher: "teacher1@gmail.com", Suspended: "false"})
+		Expect(err).Should(BeNil())
+		Expect(page.Total).Should(Equal(1))
+		Expect(page.Students[0].Email).Should(Equal("student2@gmail.com"))
+	})
+	It("ListStudents should reject invalid filters and cursors", func() {
+		_, err := svc.ListStudents(ctx, services.ListStudentsParams{ListParams: services.ListParams{Sort: "name", Limit: "0"}})
+		Expect(services.KindOf(err)).Should(Equal(services.KindValidation))
+		_, err = svc.ListStudents(ctx, services.ListStudentsParams{ListParams: services.ListParams{Cursor: "garbage"}})
+		var e *services.Error
+		Expect(errors.As(err, &e)).Should(BeTrue())
+		Expect(e.Code).Should(Equal("invalid_cursor"))
+	})
+	It("GetStudent should return an error for unknown students", func() {
+		_, err := svc.GetStudent(ctx, "nobody@gmail.com")
+		Expect(err).ShouldNot(BeNil())
+	})
+	It("GetCommonStudents should return the students of every given teacher", func() {
+		res, err := svc.GetCommonStudents(ctx, services.GetCommonStudentsParams{
+			Teacher: []string{"teacher1@gmail.com", "teacher2@gmail.com"},
+		})
+		Expect(err).Should(BeNil())
+		Expect(res).Should(Equal([]string{"student1@gmail.com", "student2@gmail.com", "student3@gmail.com"}))
+	})
+	It("Suspend should exclude the student from the notification recipients", func() {
+		Expect(svc.Suspend(ctx, services.SuspendStudentsParams{Student: "student1@gmail.com", Reason: "misconduct"})).Should(Succeed())
+
+		res, err := svc.SendNotifications(ctx, services.SendNotificationsParams{
+			Teacher:       "teacher1@gmail.com",
+			Notifications: "Hello students! @student3@gmail.com",
+		})
+		Expect(err).Should(BeNil())
+		Expect(res.Recipients).Should(ConsistOf("student2@gmail.com", "student3@gmail.com"))
+		Expect(res.UnknownMentions).Should(BeEmpty())
+	})
+	It("SendNotifications should honor the preferences of the students", func() {
+		// student1 only receives mentions, student2 nothing at all
+		_, err := svc.UpdatePreferences(ctx, "student1@gmail.com", services.UpdatePreferencesParams{BroadcastOptOut: true})
+		Expect(err).Should(BeNil())
+		_, err = svc.UpdatePreferences(ctx, "student2@gmail.com", services.UpdatePreferencesParams{Unsubscribed: true})
+		Expect(err).Should(BeNil())
+
+		res, err := svc.SendNotifications(ctx, services.SendNotificationsParams{Teacher: "teacher1@gmail.com", Notifications: "Hello students"})
+		Expect(err).Should(BeNil())
+		Expect(res.Recipients).Should(BeEmpty())
+
+		res, err = svc.SendNotifications(ctx, services.SendNotificationsParams{
+			Teacher:       "teacher1@gmail.com",
+			Notifications: "Hello @student1@gmail.com @student2@gmail.com",
+		})
+		Expect(err).Should(BeNil())
+		Expect(res.Recipients).Should(Equal([]string{"student1@gmail.com"}))
+		Expect(res.UnknownMentions).Should(BeEmpty())
+
+		p, err := svc.GetPreferences(ctx, "student3@gmail.com")
+		Expect(err).Should(BeNil())
+		Expect(p).Should(Equal(models.DefaultPreference("student3@gmail.com")))
+		_, err = svc.GetPreferences(ctx, "nobody@gmail.com")
+		Expect(services.KindOf(err)).Should(Equal(services.KindNotFound))
+		_, err = svc.UpdatePreferences(ctx, "student3@gmail.com", services.UpdatePreferencesParams{
+			QuietStart: "25:00",
+			Timezone:   "Mars/Olympus",
+		})
+		Expect(services.KindOf(err)).Should(Equal(services.KindValidation))
+	})
+	It("SendNotifications should keep the notification in the history of the teacher and recipients", func() {
+		for _, text := range []string{"first", "second @student3@gmail.com", "third"} {
+			_, err := svc.SendNotifications(ctx, services.SendNotificationsParams{Teacher: "teacher1@gmail.com", Notifications: text})
+			Expect(err).Should(BeNil())
+		}
+
+		page, err := svc.ListNotifications(ctx, services.ListNotificationsParams{Teacher: "teacher1@gmail.com", Limit: "2"})
+		Expect(err).Should(BeNil())
+		Expect(page.Notifications).Should(HaveLen(2))
+		Expect(page.Notifications[0].Message).Should(Equal("third"))
+		Expect(page.Notifications[1].Recipients).Should(Equal([]string{"student1@gmail.com", "student2@gmail.com", "student3@gmail.com"}))
+		Expect(page.NextCursor).ShouldNot(BeEmpty())
+
+		page, err = svc.ListNotifications(ctx, services.ListNotificationsParams{Teacher: "teacher1@gmail.com", Cursor: page.NextCursor})
+		Expect(err).Should(BeNil())
+		Expect(page.Notifications).Should(HaveLen(1))
+		Expect(page.Notifications[0].Message).Should(Equal("first"))
+		Expect(page.NextCursor).Should(BeEmpty())
+
+		page, err = svc.ListNotifications(ctx, services.ListNotificationsParams{Student: "student3@gmail.com"})
+		Expect(err).Should(BeNil())
+		Expect(page.Notifications).Should(HaveLen(1))
+		Expect(page.Notifications[0].Message).Should(Equal("second @student3@gmail.com"))
+
+		_, err = svc.ListNotifications(ctx, services.ListNotificationsParams{})
+		Expect(services.KindOf(err)).Should(Equal(services.KindValidation))
+		_, err = svc.ListNotifications(ctx, services.ListNotificationsParams{Teacher: "teacher1@gmail.com", Cursor: "bogus"})
+		Expect(services.KindOf(err)).Should(Equal(services.KindValidation))
+	})
+	It("SendDueNotifications should resolve the recipients of a scheduled notification when it is sent", func() {
+		sendAt := time.Now().Add(time.Minute).Format(time.RFC3339)
+		sched, err := svc.ScheduleNotification(ctx, services.SendNotificationsParams{
+			Teacher:       "teacher1@gmail.com",
+			Notifications: "Hello students",
+			SendAt:        sendAt,
+		})
+		Expect(err).Should(BeNil())
+		Expect(sched.Status).Should(Equal(models.SchedulePending))
+
+		// not due yet
+		sent, err := svc.SendDueNotifications(ctx, time.Now(), 10)
+		Expect(err).Should(BeNil())
+		Expect(sent).Should(BeZero())
+
+		Expect(svc.Suspend(ctx, services.SuspendStudentsParams{Student: "student1@gmail.com", Reason: "misconduct"})).Should(Succeed())
+		sent, err = svc.SendDueNotifications(ctx, time.Now().Add(time.Hour), 10)
+		Expect(err).Should(BeNil())
+		Expect(sent).Should(Equal(1))
+
+		page, err := svc.ListNotifications(ctx, services.ListNotificationsParams{Teacher: "teacher1@gmail.com"})
+		Expect(err).Should(BeNil())
+		Expect(page.Notifications).Should(HaveLen(1))
+		Expect(page.Notifications[0].Recipients).Should(Equal([]string{"student2@gmail.com"}))
+
+		pending, err := svc.ListScheduledNotifications(ctx, "teacher1@gmail.com")
+		Expect(err).Should(BeNil())
+		Expect(pending).Should(BeEmpty())
+		err = svc.CancelScheduledNotification(ctx, "teacher1@gmail.com", sched.ID)
+		Expect(services.KindOf(err)).Should(Equal(services.KindConflict))
+	})
+	It("SendDueNotifications should send a scheduled notification once when instances overlap", func() {
+		_, err := svc.ScheduleNotification(ctx, services.SendNotificationsParams{
+			Teacher: "teacher1@gmail.com", Notifications: "Hello students", SendAt: time.Now().Add(time.Minute).Format(time.RFC3339),
+		})
+		Expect(err).Should(BeNil())
+
+		results := make(chan int, 2)
+		for i := 0; i < 2; i++ {
+			go func() {
+				defer GinkgoRecover()
+				sent, err := svc.SendDueNotifications(ctx, time.Now().Add(time.Hour), 10)
+				Expect(err).Should(BeNil())
+				results <- sent
+			}()
+		}
+		Expect(<-results + <-results).Should(Equal(1))
+
+		page, err := svc.ListNotifications(ctx, services.ListNotificationsParams{Teacher: "teacher1@gmail.com"})
+		Expect(err).Should(BeNil())
+		Expect(page.Notifications).Should(HaveLen(1))
+		pending, err := svc.ListScheduledNotifications(ctx, "teacher1@gmail.com")
+		Expect(err).Should(BeNil())
+		Expect(pending).Should(BeEmpty())
+	})
+	It("SendDueNotifications should skip cancelled notifications and fail the ones that cannot be sent", func() {
+		sendAt := time.Now().Add(time.Minute).Format(time.RFC3339)
+		t, err := svc.CreateTemplate(ctx, services.CreateTemplateParams{Teacher: "teacher1@gmail.com", Name: "hi", Body: "Hi {{.StudentName}}"})
+		Expect(err).Should(BeNil())
+		fromTemplate, err := svc.ScheduleNotification(ctx, services.SendNotificationsParams{
+			Teacher: "teacher1@gmail.com", TemplateID: t.ID, SendAt: sendAt,
+		})
+		Expect(err).Should(BeNil())
+		cancelled, err := svc.ScheduleNotification(ctx, services.SendNotificationsParams{
+			Teacher: "teacher1@gmail.com", Notifications: "cancelled", SendAt: sendAt,
+		})
+		Expect(err).Should(BeNil())
+
+		pending, err := svc.ListScheduledNotifications(ctx, "teacher1@gmail.com")
+		Expect(err).Should(BeNil())
+		Expect(pending).Should(HaveLen(2))
+
+		Expect(svc.CancelScheduledNotification(ctx, "teacher1@gmail.com", cancelled.ID)).Should(Succeed())
+		err = svc.CancelScheduledNotification(ctx, "teacher2@gmail.com", fromTemplate.ID)
+		Expect(services.KindOf(err)).Should(Equal(services.KindNotFound))
+		Expect(svc.DeleteTemplate(ctx, "teacher1@gmail.com", t.ID)).Should(Succeed())
+
+		sent, err := svc.SendDueNotifications(ctx, time.Now().Add(time.Hour), 10)
+		Expect(err).Should(BeNil())
+		Expect(sent).Should(BeZero())
+
+		pending, err = svc.ListScheduledNotifications(ctx, "teacher1@gmail.com")
+		Expect(err).Should(BeNil())
+		Expect(pending).Should(BeEmpty())
+		page, err := svc.ListNotifications(ctx, services.ListNotificationsParams{Teacher: "teacher1@gmail.com"})
+		Expect(err).Should(BeNil())
+		Expect(page.Notifications).Should(BeEmpty())
+
+		_, err = svc.ScheduleNotification(ctx, services.SendNotificationsParams{
+			Teacher: "teacher1@gmail.com", Notifications: "too late", SendAt: time.Now().Add(-time.Minute).Format(time.RFC3339),
+		})
+		Expect(services.KindOf(err)).Should(Equal(services.KindValidation))
+	})
+	It("should enroll students to classes and aggregate them for the teacher", func() {
+		class, err := svc.CreateClass(ctx, services.CreateClassParams{Teacher: "teacher1@gmail.com", Code: "math101"})
+		Expect(err).Should(BeNil())
+		_, err = svc.CreateClass(ctx, services.CreateClassParams{Teacher: "teacher1@gmail.com", Code: "math101"})
+		Expect(services.KindOf(err)).Should(Equal(services.KindAlreadyExists))
+		_, err = svc.CreateClass(ctx, services.CreateClassParams{Teacher: "teacher1@gmail.com", Code: models.DefaultClassCode})
+		Expect(services.KindOf(err)).Should(Equal(services.KindValidation))
+
+		Expect(svc.EnrollStudents(ctx, "teacher1@gmail.com", class.ID, services.EnrollStudentsParams{
+			Students: []string{"student1@gmail.com", "student4@gmail.com"},
+		})).Should(Succeed())
+		err = svc.EnrollStudents(ctx, "teacher2@gmail.com", class.ID, services.EnrollStudentsParams{Students: []string{"student4@gmail.com"}})
+		Expect(services.KindOf(err)).Should(Equal(services.KindNotFound))
+
+		classes, err := svc.ListClasses(ctx, "teacher1@gmail.com")
+		Expect(err).Should(BeNil())
+		Expect(classes).Should(HaveLen(2))
+		Expect(classes[0].Code).Should(Equal(models.DefaultClassCode))
+
+		// the teacher-level endpoints see the students of every class once
+		students, err := svc.GetCommonStudents(ctx, services.GetCommonStudentsParams{Teacher: []string{"teacher1@gmail.com"}})
+		Expect(err).Should(BeNil())
+		Expect(students).Should(Equal([]string{"student1@gmail.com", "student2@gmail.com", "student4@gmail.com"}))
+		profile, err := svc.GetTeacherProfile(ctx, "teacher1@gmail.com")
+		Expect(err).Should(BeNil())
+		Expect(profile.ActiveStudents).Should(Equal(3))
+		err = register(services.RegisterStudentsParams{TeacherEmail: "teacher1@gmail.com", StudentEmails: []string{"student4@gmail.com"}})
+		Expect(services.KindOf(err)).Should(Equal(services.KindAlreadyExists))
+
+		students, err = svc.GetCommonStudents(ctx, services.GetCommonStudentsParams{Class: []string{strconv.Itoa(class.ID)}})
+		Expect(err).Should(BeNil())
+		Expect(students).Should(Equal([]string{"student1@gmail.com", "student4@gmail.com"}))
+
+		res, err := svc.SendNotifications(ctx, services.SendNotificationsParams{
+			Teacher: "teacher1@gmail.com", Notifications: "Quiz on Friday @student3@gmail.com", Class: class.ID,
+		})
+		Expect(err).Should(BeNil())
+		Expect(res.Recipients).Should(ConsistOf("student1@gmail.com", "student4@gmail.com", "student3@gmail.com"))
+		_, err = svc.SendNotifications(ctx, services.SendNotificationsParams{Teacher: "teacher2@gmail.com", Notifications: "Hi", Class: class.ID})
+		Expect(services.KindOf(err)).Should(Equal(services.KindNotFound))
+
+		unenrolled, err := svc.UnenrollStudents(ctx, "teacher1@gmail.com", class.ID, services.EnrollStudentsParams{
+			Students: []string{"student1@gmail.com", "student2@gmail.com"},
+		})
+		Expect(err).Should(BeNil())
+		Expect(unenrolled).Should(Equal([]string{"student1@gmail.com"}))
+		// student1 is still in the default class of the teacher
+		_, students, err = svc.GetClass(ctx, "teacher1@gmail.com", class.ID)
+		Expect(err).Should(BeNil())
+		Expect(students).Should(Equal([]string{"student4@gmail.com"}))
+
+		unregistered, err := svc.Unregister(ctx, services.UnregisterStudentsParams{
+			TeacherEmail: "teacher1@gmail.com", StudentEmails: []string{"student4@gmail.com"},
+		})
+		Expect(err).Should(BeNil())
+		Expect(unregistered).Should(Equal([]string{"student4@gmail.com"}))
+		_, students, err = svc.GetClass(ctx, "teacher1@gmail.com", class.ID)
+		Expect(err).Should(BeNil())
+		Expect(students).Should(BeEmpty())
+	})
+	It("Register should register students without an existing registration", func() {
+		Expect(register(services.RegisterStudentsParams{
+			TeacherEmail:  "teacher1@gmail.com",
+			StudentEmails: []string{"student4@gmail.com"},
+		})).Should(Succeed())
+	})
+	It("should scope registrations to the active term and roll them over", func() {
+		now := time.Now()
+		_, err := svc.CreateTerm(ctx, services.CreateTermParams{
+			Code: "current", StartsOn: now.Format(models.TermDateLayout), EndsOn: now.AddDate(0, 0, -1).Format(models.TermDateLayout),
+		})
+		Expect(services.KindOf(err)).Should(Equal(services.KindValidation))
+		_, err = svc.CreateTerm(ctx, services.CreateTermParams{
+			Code:     "current",
+			StartsOn: now.AddDate(0, 0, -30).Format(models.TermDateLayout),
+			EndsOn:   now.AddDate(0, 0, 30).Format(models.TermDateLayout),
+		})
+		Expect(err).Should(BeNil())
+		_, err = svc.CreateTerm(ctx, services.CreateTermParams{Code: "past", StartsOn: "2020-01-06", EndsOn: "2020-05-29"})
+		Expect(err).Should(BeNil())
+
+		// the term whose dates include today is active and has no registrations yet
+		terms, active, err := svc.ListTerms(ctx)
+		Expect(err).Should(BeNil())
+		Expect(terms).Should(HaveLen(2))
+		Expect(terms[0].Code).Should(Equal("past"))
+		Expect(active).Should(Equal("current"))
+		students, err := svc.GetCommonStudents(ctx, services.GetCommonStudentsParams{Teacher: []string{"teacher1@gmail.com"}})
+		Expect(err).Should(BeNil())
+		Expect(students).Should(BeEmpty())
+
+		_, err = svc.RolloverTerm(ctx, services.RolloverTermParams{Mode: services.RolloverCopy})
+		Expect(services.KindOf(err)).Should(Equal(services.KindValidation))
+		_, err = svc.RolloverTerm(ctx, services.RolloverTermParams{To: "future", Mode: services.RolloverCopy})
+		Expect(services.KindOf(err)).Should(Equal(services.KindNotFound))
+
+		res, err := svc.RolloverTerm(ctx, services.RolloverTermParams{To: "current", Mode: services.RolloverArchive})
+		Expect(err).Should(BeNil())
+		Expect(res.Archived).Should(Equal(4))
+		students, err = svc.GetCommonStudents(ctx, services.GetCommonStudentsParams{Teacher: []string{"teacher1@gmail.com"}})
+		Expect(err).Should(BeNil())
+		Expect(students).Should(Equal([]string{"student1@gmail.com", "student2@gmail.com"}))
+		profile, err := svc.GetTeacherProfile(ctx, "teacher1@gmail.com")
+		Expect(err).Should(BeNil())
+		Expect(profile.ActiveStudents).Should(Equal(2))
+
+		// registering again lands in the active term only
+		Expect(register(services.RegisterStudentsParams{
+			TeacherEmail: "teacher1@gmail.com", StudentEmails: []string{"student4@gmail.com"},
+		})).Should(Succeed())
+		students, err = svc.GetCommonStudents(ctx, services.GetCommonStudentsParams{Teacher: []string{"teacher1@gmail.com"}, Term: "past"})
+		Expect(err).Should(BeNil())
+		Expect(students).Should(BeEmpty())
+
+		svc.SetActiveTerm("past")
+		_, active, err = svc.ListTerms(ctx)
+		Expect(err).Should(BeNil())
+		Expect(active).Should(Equal("past"))
+		students, err = svc.GetCommonStudents(ctx, services.GetCommonStudentsParams{Teacher: []string{"teacher1@gmail.com"}})
+		Expect(err).Should(BeNil())
+		Expect(students).Should(BeEmpty())
+		students, err = svc.GetCommonStudents(ctx, services.GetCommonStudentsParams{Teacher: []string{"teacher1@gmail.com"}, Term: "current"})
+		Expect(err).Should(BeNil())
+		Expect(students).Should(Equal([]string{"student1@gmail.com", "student2@gmail.com", "student4@gmail.com"}))
+	})
+	It("EnsureAdmin should only create the administrator with a password the policy accepts", func() {
+		Expect(svc.EnsureAdmin(ctx, "admin@gmail.com", "")).Should(Succeed())
+		_, err := svc.Login(ctx, services.LoginParams{Email: "admin@gmail.com", Password: "password123"})
+		Expect(services.KindOf(err)).Should(Equal(services.KindUnauthorized))
+
+		err = svc.EnsureAdmin(ctx, "admin@gmail.com", "short")
+		Expect(services.KindOf(err)).Should(Equal(services.KindValidation))
+
+		Expect(svc.EnsureAdmin(ctx, "admin@gmail.com", "password123")).Should(Succeed())
+		user, err := svc.Login(ctx, services.LoginParams{Email: "admin@gmail.com", Password: "password123"})
+		Expect(err).Should(BeNil())
+		Expect(user.Role).Should(Equal(models.RoleAdmin))
+	})
+	DescribeTable("should return typed errors",
+		func(call func() error, kind services.Kind, code string) {
+			err := call()
+			Expect(services.KindOf(err)).Should(Equal(kind))
+			var svcErr *services.Error
+			Expect(errors.As(err, &svcErr)).Should(BeTrue())
+			Expect(svcErr.Code).Should(Equal(code))
+		},
+		Entry("for an unknown teacher", func() error {
+			return register(services.RegisterStudentsParams{TeacherEmail: "nobody@gmail.com", StudentEmails: []string{"student4@gmail.com"}})
+		}, services.KindNotFound, "teacher_not_found"),
+		Entry("for an unknown student", func() error {
+			return register(services.RegisterStudentsParams{TeacherEmail: "teacher1@gmail.com", StudentEmails: []string{"nobody@gmail.com"}})
+		}, services.KindNotFound, "student_not_found"),
+		Entry("for an existing registration", func() error {
+			return register(services.RegisterStudentsParams{TeacherEmail: "teacher1@gmail.com", StudentEmails: []string{"student1@gmail.com"}})
+		}, services.KindAlreadyExists, "registration_already_exists"),
+		Entry("for a suspended student", func() error {
+			Expect(svc.Suspend(ctx, services.SuspendStudentsParams{Student: "student1@gmail.com", Reason: "misconduct"})).Should(Succeed())
+			return register(services.RegisterStudentsParams{TeacherEmail: "teacher2@gmail.com", StudentEmails: []string{"student1@gmail.com", "student4@gmail.com"}})
+		}, services.KindSuspended, "student_suspended"),
+		Entry("for an existing student", func() error {
+			return svc.CreateStudent(ctx, services.CreateStudentParams{Email: "student1@gmail.com"})
+		}, services.KindAlreadyExists, "student_already_exists"),
+		Entry("for invalid params", func() error {
+			return svc.CreateTeacher(ctx, services.CreateTeacherParams{Email: "not-an-email"})
+		}, services.KindValidation, "validation_failed"),
+		Entry("for suspending an unknown student", func() error {
+			return svc.Suspend(ctx, services.SuspendStudentsParams{Student: "nobody@gmail.com", Reason: "misconduct"})
+		}, services.KindNotFound, "student_not_found"),
+		Entry("for a notification with both a text and a template", func() error {
+			_, err := svc.SendNotifications(ctx, services.SendNotificationsParams{Teacher: "teacher1@gmail.com", Notifications: "Hi", TemplateID: 1})
+			return err
+		}, services.KindValidation, "validation_failed"),
+		Entry("for a template variable set for every recipient", func() error {
+			t, err := svc.CreateTemplate(ctx, services.CreateTemplateParams{Teacher: "teacher1@gmail.com", Name: "hi", Body: "Hi {{.StudentName}}"})
+			Expect(err).Should(BeNil())
+			_, err = svc.SendNotifications(ctx, services.SendNotificationsParams{
+				Teacher:    "teacher1@gmail.com",
+				TemplateID: t.ID,
+				Variables:  map[string]string{"StudentName": "everyone"},
+			})
+			return err
+		}, services.KindValidation, "validation_failed"),
+		Entry("for a template of another teacher", func() error {
+			t, err := svc.CreateTemplate(ctx, services.CreateTemplateParams{Teacher: "teacher2@gmail.com", Name: "hi", Body: "Hi"})
+			Expect(err).Should(BeNil())
+			_, err = svc.UpdateTemplate(ctx, "teacher1@gmail.com", t.ID, services.UpdateTemplateParams{Body: &t.Body})
+			return err
+		}, services.KindNotFound, "template_not_found"),
+	)
+	It("should report every invalid field", func() {
+		err := register(services.RegisterStudentsParams{
+			TeacherEmail:  "teacher1",
+			StudentEmails: []string{"student1@gmail.com", "student2", "student3"},
+		})
+		var svcErr *services.Error
+		Expect(errors.As(err, &svcErr)).Should(BeTrue())
+		Expect(svcErr.Fields).Should(ConsistOf(
+			services.FieldError{Field: "teacher", Reason: "must be a valid email address"},
+			services.FieldError{Field: "students[1]", Reason: "must be a valid email address"},
+			services.FieldError{Field: "students[2]", Reason: "must be a valid email address"},
+		))
+	})
+})
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"time"
+)
+
+// StudentStore defines the DB level interaction of student records
+type StudentStore interface {
+	// Create persists a new student, returning db.ErrDuplicateObject if the email is taken
+	Create(ctx context.Context, input *models.Student) error
+	// FindByEmail returns db.ErrObjectNotFound if no student has the given email.
+	// Soft deleted students are left out of every read.
+	FindByEmail(ctx context.Context, email string) (models.Student, error)
+	// FindByEmails returns the given students that exist
+	FindByEmails(ctx context.Context, emails []string) ([]models.Student, error)
+	// FindByEmailArr returns the emails of the given students that exist, leaving out
+	// globally suspended students unless isSuspended is set
+	FindByEmailArr(ctx context.Context, emails []string, isSuspended bool) ([]string, error)
+	// FindNotSuspended returns the emails of the given students that exist and are suspended
+	// neither globally nor from the teacher
+	FindNotSuspended(ctx context.Context, emails []string, teacherEmail string) ([]string, error)
+	// Update changes the email and name of the student, moving its registrations along.
+	// It returns db.ErrDuplicateObject if the new email is taken.
+	Update(ctx context.Context, email string, input *models.Student) error
+	// Delete soft deletes the student, returning db.ErrObjectNotFound if there is no such student
+	Delete(ctx context.Context, email string) error
+	// Restore undoes Delete, returning db.ErrObjectNotFound if there is no such deleted student
+	Restore(ctx context.Context, email string) error
+	// List returns a page of the students matching the filter in the order it asks for
+	List(ctx context.Context, f models.ListFilter) ([]models.Student, error)
+	// Count counts every student matching the filter, ignoring its cursor and limit
+	Count(ctx context.Context, f models.ListFilter) (int, error)
+}
+
+// TeacherStore defines the DB level interaction of teacher records
+type TeacherStore interface {
+	// Create persists a new teacher, returning db.ErrDuplicateObject if the email is taken
+	Create(ctx context.Context, input *models.Teacher) error
+	// FindByEmail returns db.ErrObjectNotFound if no teacher has the given email.
+	// Soft deleted teachers are left out of every read.
+	FindByEmail(ctx context.Context, email string) (models.Teacher, error)
+	// Update changes the email and name of the teacher, moving its registrations along.
+	// It returns db.ErrDuplicateObject if the new email is taken.
+	Update(ctx context.Context, email string, input *models.Teacher) error
+	// Delete soft deletes the teacher, returning db.ErrObjectNotFound if there is no such teacher
+	Delete(ctx context.Context, email string) error
+	// List returns a page of the teachers matching the filter in the order it asks for.
+	// The Teacher and Suspended filters are ignored.
+	List(ctx context.Context, f models.ListFilter) ([]models.Teacher, error)
+	// Count counts every teacher matching the filter, ignoring its cursor and limit
+	Count(ctx context.Context, f models.ListFilter) (int, error)
+}
+
+// RegistrationStore defines the DB level interaction of student registrations. Registrations
+// to a teacher are the registrations to the classes of the teacher in a term; the term of the
+// classes created before terms existed is empty.
+type RegistrationStore interface {
+	// Register links every student to the default class of the teacher in the term; either all
+	// links are created or none. It returns db.ErrDuplicateObject if a student is already
+	// registered to a class of the teacher in the term and db.ErrReferenceNotFound if the
+	// teacher or a student does not exist.
+	Register(ctx context.Context, teacherEmail, term string, studentEmails []string) error
+	// Upsert links every student to the teacher like Register, skipping the students that are
+	// already registered to a class of the teacher in the term
+	Upsert(ctx context.Context, teacherEmail, term string, studentEmails []string) error
+	// FindRegistered returns the given students that are registered to a class of the teacher in the term
+	FindRegistered(ctx context.Context, teacherEmail, term string, studentEmails []string) ([]string, error)
+	// Unregister removes the registrations of the students to every class of the teacher in the
+	// term and returns the students that were registered
+	Unregister(ctx context.Context, teacherEmail, term string, studentEmails []string) ([]string, error)
+	// FindByEmailArr returns the students registered to any of the given teachers in the term,
+	// leaving out the registrations the student is suspended from
+	FindByEmailArr(ctx context.Context, teacherEmails []string, term string) ([]string, error)
+	// CountByTeacher counts the active and suspended students registered to the teacher in the term
+	CountByTeacher(ctx context.Context, teacherEmail, term string) (active, suspended int, err error)
+	// Enroll links every student to the class, skipping the students that are already enrolled.
+	// It returns db.ErrReferenceNotFound if the class or a student does not exist.
+	Enroll(ctx context.Context, classID int, studentEmails []string) error
+	// Unenroll removes the registrations of the students to the class and returns the students
+	// that were enrolled
+	Unenroll(ctx context.Context, classID int, studentEmails []string) ([]string, error)
+	// FindByClasses returns the students enrolled to any of the given classes, leaving out the
+	// registrations the student is suspended from
+	FindByClasses(ctx context.Context, classIDs []int) ([]string, error)
+	// CopyTerm creates the classes of a term in another term and enrolls the students of every
+	// class to its copy, leaving the classes and registrations that exist there as they are
+	CopyTerm(ctx context.Context, from, to string) error
+	// ArchiveTerm removes the registrations to the classes of the term and returns how many there were
+	ArchiveTerm(ctx context.Context, term string) (int, error)
+}
+
+// ClassStore defines the DB level interaction of the classes of teachers
+type ClassStore interface {
+	// Create adds the class and sets its ID, returning db.ErrDuplicateObject if the teacher has a
+	// class with the same code in the term and db.ErrReferenceNotFound if the teacher does not exist
+	Create(ctx context.Context, input *models.Class) error
+	// FindByID returns db.ErrObjectNotFound if the teacher has no class with the given id
+	FindByID(ctx context.Context, teacherEmail string, id int) (models.Class, error)
+	// FindByTeacher returns the classes of the teacher ordered by term and code
+	FindByTeacher(ctx context.Context, teacherEmail string) ([]models.Class, error)
+}
+
+// SuspensionStore defines the DB level interaction of the suspension history. A student is
+// suspended while one of their suspensions is neither lifted nor past its end date. A suspension
+// scoped to a teacher only applies to the registration of the student to that teacher.
+type SuspensionStore interface {
+	// Create adds a suspension and sets its ID, returning db.ErrReferenceNotFound if the student
+	// or the teacher does not exist
+	Create(ctx context.Context, input *models.Suspension) error
+	// FindActive returns db.ErrObjectNotFound if the student has no suspension in force globally
+	// or, when teacherEmail is given, from the teacher
+	FindActive(ctx context.Context, studentEmail, teacherEmail string) (models.Suspension, error)
+	// Lift ends the suspensions in force scoped to the teacher or, when teacherEmail is empty,
+	// every suspension in force, and returns how many were lifted
+	Lift(ctx context.Context, studentEmail, teacherEmail, liftedBy string) (int, error)
+	// FindByStudent returns the suspension history of the student, latest first
+	FindByStudent(ctx context.Context, studentEmail string) ([]models.Suspension, error)
+}
+
+// NotificationStore defines the DB level interaction of the notification history
+type NotificationStore interface {
+	// Create persists the notification with its recipients and sets its ID and creation date,
+	// returning db.ErrReferenceNotFound if the teacher or a recipient does not exist
+	Create(ctx context.Context, input *models.Notification) error
+	// List returns the notifications matching the filter with their recipients, latest first
+	List(ctx context.Context, f models.NotificationFilter) ([]models.Notification, error)
+}
+
+// TemplateStore defines the DB level interaction of notification templates. A template is only
+// found through the teacher it belongs to.
+type TemplateStore interface {
+	// Create persists a new template and sets its ID, returning db.ErrDuplicateObject if the teacher
+	// already has a template with that name and db.ErrReferenceNotFound if the teacher does not exist
+	Create(ctx context.Context, input *models.Template) error
+	// FindByID returns db.ErrObjectNotFound if the teacher has no template with the given id
+	FindByID(ctx context.Context, teacherEmail string, id int) (models.Template, error)
+	// FindByTeacher returns the templates of the teacher ordered by name
+	FindByTeacher(ctx context.Context, teacherEmail string) ([]models.Template, error)
+	// Update changes the name and body of the template, returning db.ErrDuplicateObject if the
+	// teacher already has another template with that name
+	Update(ctx context.Context, input *models.Template) error
+	// Delete removes the template, returning db.ErrObjectNotFound if the teacher has no such template
+	Delete(ctx context.Context, teacherEmail string, id int) error
+}
+
+// ScheduleStore defines the DB level interaction of scheduled notifications. Only pending scheduled
+// notifications can be sent, failed or cancelled.
+type ScheduleStore interface {
+	// Create queues the notification and sets its ID and status, returning db.ErrReferenceNotFound
+	// if the teacher does not exist
+	Create(ctx context.Context, input *models.ScheduledNotification) error
+	// FindByID returns db.ErrObjectNotFound if the teacher has no scheduled notification with the given id
+	FindByID(ctx context.Context, teacherEmail string, id int) (models.ScheduledNotification, error)
+	// FindPending returns the pending scheduled notifications of the teacher, the next one first
+	FindPending(ctx context.Context, teacherEmail string) ([]models.ScheduledNotification, error)
+	// Due returns up to limit pending scheduled notifications whose send date passed at now, oldest first
+	Due(ctx context.Context, now time.Time, limit int) ([]models.ScheduledNotification, error)
+	// Claim locks the pending scheduled notification for the unit of work of ctx, returning
+	// db.ErrObjectNotFound if it is no longer pending or another unit of work claimed it
+	Claim(ctx context.Context, id int) error
+	// MarkSent records the notification it was sent as, returning db.ErrObjectNotFound if it is not pending
+	MarkSent(ctx context.Context, id int, notificationID int) error
+	// MarkFailed records why it could not be sent, returning db.ErrObjectNotFound if it is not pending
+	MarkFailed(ctx context.Context, id int, reason string) error
+	// Cancel returns db.ErrObjectNotFound if the teacher has no such pending scheduled notification
+	Cancel(ctx context.Context, teacherEmail string, id int) error
+}
+
+// PreferenceStore defines the DB level interaction of the notification preferences of students
+type PreferenceStore interface {
+	// FindByStudent returns db.ErrObjectNotFound if the student never set any preference
+	FindByStudent(ctx context.Context, studentEmail string) (models.Preference, error)
+	// FindByStudents returns the preferences of the given students that set any
+	FindByStudents(ctx context.Context, studentEmails []string) ([]models.Preference, error)
+	// Save creates or replaces the preferences of the student, returning db.ErrReferenceNotFound
+	// if the student does not exist
+	Save(ctx context.Context, input *models.Preference) error
+}
+
+// TermStore defines the DB level interaction of academic terms
+type TermStore interface {
+	// Create adds the term, returning db.ErrDuplicateObject if the code is taken
+	Create(ctx context.Context, input *models.Term) error
+	// FindByCode returns db.ErrObjectNotFound if no term has the given code
+	FindByCode(ctx context.Context, code string) (models.Term, error)
+	// FindAt returns the term whose dates include at, the latest starting one when terms overlap,
+	// or db.ErrObjectNotFound
+	FindAt(ctx context.Context, at time.Time) (models.Term, error)
+	// FindAll returns every term ordered by start date
+	FindAll(ctx context.Context) ([]models.Term, error)
+}
+
+// UserStore defines the DB level interaction of user accounts
+type UserStore interface {
+	// Create persists a new user and sets its ID, returning db.ErrDuplicateObject if the email is taken
+	Create(ctx context.Context, input *models.User) error
+	// FindByEmail returns db.ErrObjectNotFound if no user has the given email
+	FindByEmail(ctx context.Context, email string) (models.User, error)
+	// UpdateEmail returns db.ErrObjectNotFound if no user has the email and db.ErrDuplicateObject if
+	// the new email is taken
+	UpdateEmail(ctx context.Context, email, newEmail string) error
+}
+
+// UnitOfWork runs a group of store calls atomically. The context passed to fn carries
+// the transaction, so every store call made with it is committed or rolled back together.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/opentracing/opentracing-go"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"time"
+)
+
+// Suspend suspends a student until the suspension is lifted or, when given, until its end date passes.
+// With a teacher the student is only suspended from the teacher's class.
+func (s *Service) Suspend(ctx context.Context, params SuspendStudentsParams) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.Suspend")
+	defer span.Finish()
+
+	if err := validate(params); err != nil {
+		return err
+	}
+	var until *time.Time
+	if params.Until != "" {
+		t, _ := time.Parse(time.RFC3339, params.Until)
+		if !t.After(time.Now()) {
+			return Validation("validation_failed", errors.New("until: must be in the future"),
+				FieldError{Field: "until", Reason: "must be in the future"})
+		}
+		until = &t
+	}
+
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		// find student object
+		_, err := s.sr.FindByEmail(ctx, params.Student)
+		if err != nil {
+			return translate(err, "student")
+		}
+
+		var teacher *string
+		if params.Teacher != "" {
+			_, err = s.tr.FindByEmail(ctx, params.Teacher)
+			if err != nil {
+				return translate(err, "teacher")
+			}
+			teacher = &params.Teacher
+		}
+
+		_, err = s.hr.FindActive(ctx, params.Student, params.Teacher)
+		if err == nil {
+			return Conflict("student_already_suspended", "student is already suspended")
+		}
+		if !errors.As(err, &db.ErrObjectNotFound{}) {
+			return err
+		}
+
+		return s.hr.Create(ctx, &models.Suspension{
+			StudentID:   params.Student,
+			TeacherID:   teacher,
+			Reason:      params.Reason,
+			SuspendedBy: params.By,
+			EndsOn:      until,
+		})
+	})
+	return translate(err, "suspension")
+}
+
+// Unsuspend lifts the suspensions of a student. With a teacher only the suspensions scoped to
+// the teacher are lifted.
+func (s *Service) Unsuspend(ctx context.Context, params UnsuspendStudentParams) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.Unsuspend")
+	defer span.Finish()
+
+	if err := validate(params); err != nil {
+		return err
+	}
+
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		_, err := s.sr.FindByEmail(ctx, params.Student)
+		if err != nil {
+			return translate(err, "student")
+		}
+
+		if params.Teacher != "" {
+			_, err = s.tr.FindByEmail(ctx, params.Teacher)
+			if err != nil {
+				return translate(err, "teacher")
+			}
+		}
+
+		lifted, err := s.hr.Lift(ctx, params.Student, params.Teacher, params.By)
+		if err != nil || lifted > 0 {
+			return err
+		}
+
+		// nothing was lifted, tell a teacher apart from a student that is suspended globally
+		_, err = s.hr.FindActive(ctx, params.Student, params.Teacher)
+		if errors.As(err, &db.ErrObjectNotFound{}) {
+			return Conflict("student_not_suspended", "student is not suspended")
+		}
+		if err != nil {
+			return err
+		}
+		return Conflict("student_suspended_globally", "a global suspension can only be lifted without a teacher")
+	})
+	return translate(err, "suspension")
+}
+
+// GetSuspensions retrieves the suspension history of a student, latest first
+func (s *Service) GetSuspensions(ctx context.Context, email string) ([]models.Suspension, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.GetSuspensions")
+	defer span.Finish()
+
+	_, err := s.sr.FindByEmail(ctx, email)
+	if err != nil {
+		return nil, translate(err, "student")
+	}
+
+	suspensions, err := s.hr.FindByStudent(ctx, email)
+	if err != nil {
+		return nil, translate(err, "suspension")
+	}
+	return suspensions, nil
+}
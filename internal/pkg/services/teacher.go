@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/opentracing/opentracing-go"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+)
+
+// TeacherProfile is a teacher together with the number of students registered to them
+type TeacherProfile struct {
+	models.Teacher
+	ActiveStudents    int
+	SuspendedStudents int
+}
+
+// GetTeacherProfile retrieves the teacher record and counts their active and suspended students in
+// the active term
+func (s *Service) GetTeacherProfile(ctx context.Context, email string) (TeacherProfile, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.GetTeacherProfile")
+	defer span.Finish()
+
+	teacher, err := s.GetTeacher(ctx, email)
+	if err != nil {
+		return TeacherProfile{}, err
+	}
+
+	term, err := s.activeTerm(ctx)
+	if err != nil {
+		return TeacherProfile{}, err
+	}
+	active, suspended, err := s.rr.CountByTeacher(ctx, email, term)
+	if err != nil {
+		return TeacherProfile{}, translate(err, "registration")
+	}
+
+	return TeacherProfile{
+		Teacher:           teacher,
+		ActiveStudents:    active,
+		SuspendedStudents: suspended,
+	}, nil
+}
+
+// UpdateTeacher changes the name and email of a teacher. Empty fields are left unchanged. The user
+// account of the teacher follows an email change.
+func (s *Service) UpdateTeacher(ctx context.Context, email string, params UpdateTeacherParams) (TeacherProfile, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.UpdateTeacher")
+	defer span.Finish()
+
+	if err := validate(params); err != nil {
+		return TeacherProfile{}, err
+	}
+	if params.Email == "" && params.Name == nil {
+		return TeacherProfile{}, Validation("validation_failed", errors.New("email or name is required"),
+			FieldError{Field: "email", Reason: "is required"}, FieldError{Field: "name", Reason: "is required"})
+	}
+
+	var teacher models.Teacher
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		teacher, err = s.tr.FindByEmail(ctx, email)
+		if err != nil {
+			return err
+		}
+
+		if params.Email != "" {
+			teacher.Email = params.Email
+		}
+		if params.Name != nil {
+			teacher.Name = *params.Name
+		}
+		if err := s.tr.Update(ctx, email, &teacher); err != nil {
+			return err
+		}
+		return s.renameUser(ctx, models.RoleTeacher, email, teacher.Email)
+	})
+	if err != nil {
+		return TeacherProfile{}, translate(err, "teacher")
+	}
+	return s.GetTeacherProfile(ctx, teacher.Email)
+}
+
+// DeleteTeacher soft deletes a teacher, which hides their students from common students and notifications
+func (s *Service) DeleteTeacher(ctx context.Context, email string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.DeleteTeacher")
+	defer span.Finish()
+
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		return s.tr.Delete(ctx, email)
+	})
+	return translate(err, "teacher")
+}
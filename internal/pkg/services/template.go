@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/opentracing/opentracing-go"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+)
+
+// CreateTemplate adds a notification template to the teacher
+func (s *Service) CreateTemplate(ctx context.Context, params CreateTemplateParams) (models.Template, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.CreateTemplate")
+	defer span.Finish()
+
+	if err := validate(params); err != nil {
+		return models.Template{}, err
+	}
+	if err := checkTemplate(params.Body); err != nil {
+		return models.Template{}, err
+	}
+
+	t := models.Template{TeacherID: params.Teacher, Name: params.Name, Body: params.Body}
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		_, err := s.tr.FindByEmail(ctx, params.Teacher)
+		if err != nil {
+			return translate(err, "teacher")
+		}
+		return s.tm.Create(ctx, &t)
+	})
+	if err != nil {
+		return models.Template{}, translate(err, "template")
+	}
+	return t, nil
+}
+
+// ListTemplates retrieves the notification templates of the teacher ordered by name
+func (s *Service) ListTemplates(ctx context.Context, teacher string) ([]models.Template, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.ListTemplates")
+	defer span.Finish()
+
+	_, err := s.tr.FindByEmail(ctx, teacher)
+	if err != nil {
+		return nil, translate(err, "teacher")
+	}
+
+	templates, err := s.tm.FindByTeacher(ctx, teacher)
+	if err != nil {
+		return nil, translate(err, "template")
+	}
+	return templates, nil
+}
+
+// GetTemplate retrieves a notification template of the teacher
+func (s *Service) GetTemplate(ctx context.Context, teacher string, id int) (models.Template, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.GetTemplate")
+	defer span.Finish()
+
+	t, err := s.tm.FindByID(ctx, teacher, id)
+	if err != nil {
+		return models.Template{}, translate(err, "template")
+	}
+	return t, nil
+}
+
+// UpdateTemplate changes the name and body of a notification template of the teacher. Empty
+// fields are left unchanged.
+func (s *Service) UpdateTemplate(ctx context.Context, teacher string, id int, params UpdateTemplateParams) (models.Template, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.UpdateTemplate")
+	defer span.Finish()
+
+	if err := validate(params); err != nil {
+		return models.Template{}, err
+	}
+	if params.Name == nil && params.Body == nil {
+		return models.Template{}, Validation("validation_failed", errors.New("name or body is required"),
+			FieldError{Field: "name", Reason: reasons["required"]}, FieldError{Field: "body", Reason: reasons["required"]})
+	}
+	if params.Body != nil {
+		if err := checkTemplate(*params.Body); err != nil {
+			return models.Template{}, err
+		}
+	}
+
+	var t models.Template
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		t, err = s.tm.FindByID(ctx, teacher, id)
+		if err != nil {
+			return err
+		}
+
+		if params.Name != nil {
+			t.Name = *params.Name
+		}
+		if params.Body != nil {
+			t.Body = *params.Body
+		}
+		return s.tm.Update(ctx, &t)
+	})
+	if err != nil {
+		return models.Template{}, translate(err, "template")
+	}
+	return s.GetTemplate(ctx, teacher, id)
+}
+
+// DeleteTemplate removes a notification template of the teacher. The notifications sent from it are kept.
+func (s *Service) DeleteTemplate(ctx context.Context, teacher string, id int) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.DeleteTemplate")
+	defer span.Finish()
+
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		return s.tm.Delete(ctx, teacher, id)
+	})
+	return translate(err, "template")
+}
+
+// checkTemplate reports a body that ParseTemplate rejects as a validation error
+func checkTemplate(body string) error {
+	if _, err := ParseTemplate(body); err != nil {
+		return Validation("invalid_template", err, FieldError{Field: "body", Reason: "is not a valid template"})
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/opentracing/opentracing-go"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"time"
+)
+
+// Term rollover modes
+const (
+	// RolloverCopy copies the classes of a term and their students to another term
+	RolloverCopy = "copy"
+	// RolloverArchive archives the registrations of a term, after copying them when a term to copy
+	// them to is given
+	RolloverArchive = "archive"
+)
+
+// TermRollover is the outcome of a term rollover
+type TermRollover struct {
+	From string
+	To   string
+	Mode string
+	// Archived is the number of registrations archived
+	Archived int
+}
+
+// SetActiveTerm configures the code of the active term. Without one, the term whose dates include
+// the current date is active.
+func (s *Service) SetActiveTerm(code string) {
+	s.term = code
+}
+
+// activeTerm returns the code of the active term: the configured one, else the term whose dates
+// include the current date, else the empty term of the classes without a term
+func (s *Service) activeTerm(ctx context.Context) (string, error) {
+	if s.term != "" {
+		return s.term, nil
+	}
+	t, err := s.tt.FindAt(ctx, time.Now())
+	if errors.As(err, &db.ErrObjectNotFound{}) {
+		return "", nil
+	}
+	if err != nil {
+		return "", translate(err, "term")
+	}
+	return t.Code, nil
+}
+
+// CreateTerm adds an academic term
+func (s *Service) CreateTerm(ctx context.Context, params CreateTermParams) (models.Term, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.CreateTerm")
+	defer span.Finish()
+
+	if err := validate(params); err != nil {
+		return models.Term{}, err
+	}
+	t := models.Term{Code: params.Code, Name: params.Name}
+	var fields []FieldError
+	var err error
+	if t.StartsOn, err = time.Parse(models.TermDateLayout, params.StartsOn); err != nil {
+		fields = append(fields, FieldError{Field: "starts_on", Reason: "must be a date such as 2024-01-08"})
+	}
+	if t.EndsOn, err = time.Parse(models.TermDateLayout, params.EndsOn); err != nil {
+		fields = append(fields, FieldError{Field: "ends_on", Reason: "must be a date such as 2024-01-08"})
+	}
+	if len(fields) == 0 && t.EndsOn.Before(t.StartsOn) {
+		fields = append(fields, FieldError{Field: "ends_on", Reason: "must not be before starts_on"})
+	}
+	if len(fields) > 0 {
+		return models.Term{}, Validation("validation_failed", errors.New(fields[0].Field+": "+fields[0].Reason), fields...)
+	}
+
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		return s.tt.Create(ctx, &t)
+	})
+	if err != nil {
+		return models.Term{}, translate(err, "term")
+	}
+	return t, nil
+}
+
+// ListTerms retrieves every academic term ordered by start date, and the code of the active term
+func (s *Service) ListTerms(ctx context.Context) ([]models.Term, string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.ListTerms")
+	defer span.Finish()
+
+	terms, err := s.tt.FindAll(ctx)
+	if err != nil {
+		return nil, "", translate(err, "term")
+	}
+	active, err := s.activeTerm(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	return terms, active, nil
+}
+
+// RolloverTerm moves the registrations of a term on. In copy mode the classes of the term and
+// their students are copied to another term, leaving the classes and registrations that exist
+// there as they are. In archive mode the registrations of the term are archived, after being
+// copied when another term is given. The term of the classes without a term is empty.
+func (s *Service) RolloverTerm(ctx context.Context, params RolloverTermParams) (TermRollover, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.RolloverTerm")
+	defer span.Finish()
+
+	if err := validate(params); err != nil {
+		return TermRollover{}, err
+	}
+	if params.Mode == RolloverCopy && params.To == "" {
+		return TermRollover{}, Validation("validation_failed", errors.New("to: is required"),
+			FieldError{Field: "to", Reason: reasons["required"]})
+	}
+	if params.To != "" && params.To == params.From {
+		return TermRollover{}, Validation("validation_failed", errors.New("to: must differ from from"),
+			FieldError{Field: "to", Reason: "must differ from from"})
+	}
+
+	res := TermRollover{From: params.From, To: params.To, Mode: params.Mode}
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		for _, code := range []string{params.From, params.To} {
+			if code == "" {
+				continue
+			}
+			if _, err := s.tt.FindByCode(ctx, code); err != nil {
+				return translate(err, "term")
+			}
+		}
+
+		if params.To != "" {
+			if err := s.rr.CopyTerm(ctx, params.From, params.To); err != nil {
+				return err
+			}
+		}
+		if params.Mode == RolloverArchive {
+			var err error
+			res.Archived, err = s.rr.ArchiveTerm(ctx, params.From)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return TermRollover{}, translate(err, "registration")
+	}
+	return res, nil
+}
@@ -13,3 +13,18 @@ func unique(stringSlice []string) []string {
 	}
 	return returnSlice
 }
+
+// difference returns the items of a that are not in b
+func difference(a, b []string) []string {
+	keys := make(map[string]bool, len(b))
+	for _, item := range b {
+		keys[item] = true
+	}
+	returnSlice := []string{}
+	for _, item := range unique(a) {
+		if !keys[item] {
+			returnSlice = append(returnSlice, item)
+		}
+	}
+	return returnSlice
+}
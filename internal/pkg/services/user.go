@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/opentracing/opentracing-go"
+	"github.com/whittier16/student-reg-svc/internal/app/models"
+	"github.com/whittier16/student-reg-svc/internal/pkg/database/db"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyHash is compared against when the login email is unknown, so that unknown
+// emails and wrong passwords take the same time to reject
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+// Login verifies the credentials and returns the matching user
+func (s *Service) Login(ctx context.Context, params LoginParams) (models.User, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.Login")
+	defer span.Finish()
+
+	if err := validate(params); err != nil {
+		return models.User{}, err
+	}
+
+	invalid := Unauthorized("invalid_credentials", "invalid email or password")
+	user, err := s.ur.FindByEmail(ctx, params.Email)
+	switch {
+	case err == nil:
+	case errors.As(err, &db.ErrObjectNotFound{}):
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(params.Password))
+		return models.User{}, invalid
+	default:
+		return models.User{}, translate(err, "user")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Passwordhash), []byte(params.Password)); err != nil {
+		return models.User{}, invalid
+	}
+	return user, nil
+}
+
+// GetUser sends the request straight to the repo and retrieves user record
+func (s *Service) GetUser(ctx context.Context, email string) (models.User, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.GetUser")
+	defer span.Finish()
+
+	user, err := s.ur.FindByEmail(ctx, email)
+	if err != nil {
+		return models.User{}, translate(err, "user")
+	}
+	return user, nil
+}
+
+// CreateUser hashes the password and creates the user record to the repo
+func (s *Service) CreateUser(ctx context.Context, params CreateUserParams) (models.User, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.CreateUser")
+	defer span.Finish()
+
+	if err := validate(params); err != nil {
+		return models.User{}, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(params.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return models.User{}, Internal(err)
+	}
+
+	user := models.User{
+		Email:        params.Email,
+		Username:     params.Username,
+		Passwordhash: string(hash),
+		Fullname:     params.Fullname,
+		Role:         models.ParseRole(params.Role),
+	}
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		return s.ur.Create(ctx, &user)
+	})
+	if err != nil {
+		return models.User{}, translate(err, "user")
+	}
+	return user, nil
+}
+
+// renameUser moves the user account of a renamed student or teacher to their new email, so that
+// they keep logging in and acting as themselves. Accounts of another role are left as they are.
+func (s *Service) renameUser(ctx context.Context, role models.Role, email, newEmail string) error {
+	if email == newEmail {
+		return nil
+	}
+	user, err := s.ur.FindByEmail(ctx, email)
+	if errors.As(err, &db.ErrObjectNotFound{}) || (err == nil && user.Role != role) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.ur.UpdateEmail(ctx, email, newEmail)
+}
+
+// EnsureAdmin creates the admin account if no user has the given email yet. It is used
+// to bootstrap the first administrator, who can then create the other users. Nothing is
+// created without a password, and a password the password policy rejects is an error.
+func (s *Service) EnsureAdmin(ctx context.Context, email, password string) error {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Service.EnsureAdmin")
+	defer span.Finish()
+
+	if password == "" {
+		return nil
+	}
+	params := CreateUserParams{Email: email, Password: password, Role: models.RoleAdmin.String()}
+	if err := validate(params); err != nil {
+		return err
+	}
+
+	_, err := s.ur.FindByEmail(ctx, email)
+	if !errors.As(err, &db.ErrObjectNotFound{}) {
+		return translate(err, "user")
+	}
+
+	_, err = s.CreateUser(ctx, params)
+	return err
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"github.com/asaskevich/govalidator"
+	"reflect"
+	"strings"
+)
+
+// reasons holds the message reported for each govalidator tag
+var reasons = map[string]string{
+	"email":        "must be a valid email address",
+	"required":     "is required",
+	"rfc3339":      "must be an RFC 3339 date",
+	"int":          "must be an integer",
+	"range":        "is out of range",
+	"in":           "is not an allowed value",
+	"stringlength": "has an invalid length",
+}
+
+// validate checks params against their govalidator tags and reports every offending field by
+// its json name. govalidator skips the elements of slices, so []string fields tagged "email"
+// are checked element by element and reported as e.g. "students[1]".
+func validate(params interface{}) error {
+	var fields []FieldError
+
+	if _, err := govalidator.ValidateStruct(params); err != nil {
+		var errs govalidator.Errors
+		if !errors.As(err, &errs) {
+			return Validation("validation_failed", err)
+		}
+		for _, e := range flatten(errs) {
+			var ve govalidator.Error
+			if !errors.As(e, &ve) {
+				fields = append(fields, FieldError{Reason: e.Error()})
+				continue
+			}
+			reason, ok := reasons[ve.Validator]
+			if !ok {
+				reason = ve.Err.Error()
+			}
+			fields = append(fields, FieldError{Field: ve.Name, Reason: reason})
+		}
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(params))
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		emails, ok := v.Field(i).Interface().([]string)
+		if !ok || !hasTag(f.Tag.Get("valid"), "email") {
+			continue
+		}
+		for j, email := range emails {
+			if !govalidator.IsEmail(email) {
+				fields = append(fields, FieldError{
+					Field:  fmt.Sprintf("%s[%d]", jsonName(f), j),
+					Reason: reasons["email"],
+				})
+			}
+		}
+	}
+
+	return invalid(fields)
+}
+
+// invalid returns the validation error reporting every offending field, nil when there is none
+func invalid(fields []FieldError) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(fields))
+	for _, f := range fields {
+		msgs = append(msgs, f.Field+": "+f.Reason)
+	}
+	return Validation("validation_failed", errors.New(strings.Join(msgs, "; ")), fields...)
+}
+
+// flatten returns the errors of errs, including those of embedded structs
+func flatten(errs govalidator.Errors) []error {
+	var flat []error
+	for _, e := range errs.Errors() {
+		var nested govalidator.Errors
+		if errors.As(e, &nested) {
+			flat = append(flat, flatten(nested)...)
+			continue
+		}
+		flat = append(flat, e)
+	}
+	return flat
+}
+
+// hasTag reports whether the comma separated govalidator tag contains name
+func hasTag(tag, name string) bool {
+	for _, t := range strings.Split(tag, ",") {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonName returns the name of the field in the request body
+func jsonName(f reflect.StructField) string {
+	if name := strings.Split(f.Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+		return name
+	}
+	return f.Name
+}
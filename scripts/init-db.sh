@@ -3,3 +3,6 @@
 sleep 5
 mysql -u root -e "CREATE DATABASE stdnt_reg"
 mysql -u root mydb < /db/migration/dump.sql
+for f in /db/migration/patch_*.sql; do
+  mysql -u root mydb < "$f"
+done